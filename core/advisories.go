@@ -0,0 +1,142 @@
+package core
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Advisory is a published security advisory of the opendex-docker repository.
+type Advisory struct {
+	GhsaId          string                  `json:"ghsa_id"`
+	Summary         string                  `json:"summary"`
+	Severity        string                  `json:"severity"`
+	HtmlUrl         string                  `json:"html_url"`
+	Vulnerabilities []AdvisoryVulnerability `json:"vulnerabilities"`
+}
+
+type AdvisoryVulnerability struct {
+	VulnerableVersionRange string `json:"vulnerable_version_range"`
+	PatchedVersions        string `json:"patched_versions"`
+}
+
+// GetAdvisories returns the published security advisories of opendex-docker.
+func (t *GithubClient) GetAdvisories() ([]Advisory, error) {
+	url := "https://api.github.com/repos/opendexnetwork/opendex-docker/security-advisories?state=published"
+	body, err := t.doGet(url)
+	if err != nil {
+		return nil, err
+	}
+	var result []Advisory
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// compareVersions compares dotted release versions such as 21.02.10
+// numerically. Prerelease suffixes are ignored.
+func compareVersions(a string, b string) int {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		if i := strings.Index(v, "-"); i >= 0 {
+			v = v[:i]
+		}
+		var parts []int
+		for _, part := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(part)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+	pa, pb := parse(a), parse(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// inVersionRange reports whether version satisfies every comma separated
+// constraint of r, e.g. ">= 21.01.01, < 21.02.05".
+func inVersionRange(version string, r string) bool {
+	if strings.TrimSpace(r) == "" {
+		return false
+	}
+	for _, constraint := range strings.Split(r, ",") {
+		constraint = strings.TrimSpace(constraint)
+		v := strings.TrimLeft(constraint, "<>=!")
+		op := constraint[:len(constraint)-len(v)]
+		c := compareVersions(version, v)
+		var ok bool
+		switch op {
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		case "=", "==", "":
+			ok = c == 0
+		case "!=":
+			ok = c != 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// affectingAdvisories returns the advisories affecting release version.
+func affectingAdvisories(advisories []Advisory, version string) []Advisory {
+	var result []Advisory
+	for _, a := range advisories {
+		for _, v := range a.Vulnerabilities {
+			if inVersionRange(version, v.VulnerableVersionRange) {
+				result = append(result, a)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// checkAdvisories warns when the release about to run is affected by a
+// published security advisory. Branch builds have no version to check.
+func (t *Launcher) checkAdvisories() {
+	if releaseChannel(t.branch) == "" {
+		return
+	}
+	advisories, err := t.github.GetAdvisories()
+	if err != nil {
+		t.logger.Debugf("Failed to check security advisories: %s", err)
+		return
+	}
+	for _, a := range affectingAdvisories(advisories, t.branch) {
+		var patched []string
+		for _, v := range a.Vulnerabilities {
+			if v.PatchedVersions != "" {
+				patched = append(patched, v.PatchedVersions)
+			}
+		}
+		fix := "no patched version yet"
+		if len(patched) > 0 {
+			fix = "update to " + strings.Join(patched, ", ")
+		}
+		t.logger.Warnf("%s is affected by security advisory %s (%s severity): %s, %s, see %s", t.branch, a.GhsaId, a.Severity, a.Summary, fix, a.HtmlUrl)
+	}
+}
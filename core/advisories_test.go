@@ -0,0 +1,25 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestInVersionRange(t *testing.T) {
+	assert.Equal(t, inVersionRange("21.02.10", "< 21.03.01"), true)
+	assert.Equal(t, inVersionRange("21.03.01", "< 21.03.01"), false)
+	assert.Equal(t, inVersionRange("21.02.10", ">= 21.01.01, < 21.02.05"), false)
+	assert.Equal(t, inVersionRange("21.01.20", ">= 21.01.01, < 21.02.05"), true)
+	assert.Equal(t, inVersionRange("21.02.10-rc1", "= 21.02.10"), true)
+	assert.Equal(t, inVersionRange("21.02.10", ""), false)
+}
+
+func TestAffectingAdvisories(t *testing.T) {
+	a := Advisory{
+		GhsaId:          "GHSA-1",
+		Vulnerabilities: []AdvisoryVulnerability{{VulnerableVersionRange: "< 21.03.01", PatchedVersions: "21.03.01"}},
+	}
+
+	assert.Equal(t, len(affectingAdvisories([]Advisory{a}, "21.02.10")), 1)
+	assert.Equal(t, len(affectingAdvisories([]Advisory{a}, "21.03.01")), 0)
+}
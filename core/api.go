@@ -0,0 +1,236 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type API struct {
+	Listen string `toml:"listen" comment:"Address of the local control API, e.g. 127.0.0.1:8887 (disabled when empty)"`
+}
+
+const defaultLogLines = 100
+
+// APITokenFilename holds the bearer token of the control API in the
+// launcher dir, readable by the user only.
+const APITokenFilename = "api-token"
+
+var ErrUpdateRunning = errors.New("an update is running already")
+
+// apiServer is the local control API. It only listens on loopback
+// addresses, and requires the token of APITokenFilename as browsers on the
+// machine can reach those as well.
+type apiServer struct {
+	launcher *Launcher
+	logger   *logrus.Entry
+	token    string
+}
+
+// apiToken returns the token of the control API in file, creating it when
+// missing.
+func apiToken(file string) (string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := ioutil.WriteFile(file, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// isLoopbackHost reports whether the Host header of a request names a
+// loopback address, which DNS rebinding attacks cannot fake.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func checkLoopback(listen string) error {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("not a loopback address: %s", listen)
+	}
+	return nil
+}
+
+func (t *Launcher) startAPI() error {
+	listen := t.config.API.Listen
+	if err := checkLoopback(listen); err != nil {
+		return fmt.Errorf("api: %w", err)
+	}
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("api: %w", err)
+	}
+	token, err := apiToken(filepath.Join(t.launcherDir, APITokenFilename))
+	if err != nil {
+		return fmt.Errorf("api token: %w", err)
+	}
+	s := &apiServer{
+		launcher: t,
+		logger:   logrus.NewEntry(logrus.StandardLogger()).WithField("name", "api"),
+		token:    token,
+	}
+	go func() {
+		if err := http.Serve(ln, s.handler()); err != nil {
+			s.logger.Errorf("Serve: %s", err)
+		}
+	}()
+	if Debug {
+		fmt.Printf("API: http://%s\n", ln.Addr())
+	}
+	return nil
+}
+
+func (t *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", t.get(t.handleStatus))
+	mux.HandleFunc("/versions", t.get(t.handleVersions))
+	mux.HandleFunc("/logs", t.get(t.handleLogs))
+	mux.HandleFunc("/update", t.post(t.handleUpdate))
+	mux.HandleFunc("/stop", t.post(t.handleStop))
+	mux.HandleFunc("/restart", t.post(t.handleRestart))
+	return t.authorize(mux)
+}
+
+// authorize rejects requests for other hosts and requests without the
+// token.
+func (t *apiServer) authorize(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			t.writeError(w, http.StatusForbidden, fmt.Errorf("host not allowed: %s", r.Host))
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if t.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(t.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			t.writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or wrong token, see %s", APITokenFilename))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (t *apiServer) method(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			t.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (t *apiServer) get(h http.HandlerFunc) http.HandlerFunc {
+	return t.method(http.MethodGet, h)
+}
+
+func (t *apiServer) post(h http.HandlerFunc) http.HandlerFunc {
+	return t.method(http.MethodPost, h)
+}
+
+func (t *apiServer) writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		t.logger.Debugf("Write response: %s", err)
+	}
+}
+
+func (t *apiServer) writeError(w http.ResponseWriter, status int, err error) {
+	t.writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (t *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	t.writeJSON(w, http.StatusOK, t.launcher.status())
+}
+
+func (t *apiServer) handleVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := t.launcher.installedVersions()
+	if err != nil {
+		t.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	t.writeJSON(w, http.StatusOK, versions)
+}
+
+func (t *apiServer) handleLogs(w http.ResponseWriter, r *http.Request) {
+	n := defaultLogLines
+	if value := r.URL.Query().Get("lines"); value != "" {
+		var err error
+		if n, err = strconv.Atoi(value); err != nil {
+			t.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid lines: %s", value))
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	lines := t.launcher.output.Tail(n)
+	if len(lines) > 0 {
+		fmt.Fprintln(w, strings.Join(lines, "\n"))
+	}
+}
+
+func (t *apiServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	commit, updated, err := t.launcher.update()
+	if errors.Is(err, ErrUpdateRunning) {
+		t.writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		t.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	t.writeJSON(w, http.StatusOK, map[string]interface{}{"commit": commit, "updated": updated})
+}
+
+func (t *apiServer) handleStop(w http.ResponseWriter, r *http.Request) {
+	if err := t.launcher.stopChild(); err != nil {
+		t.writeError(w, http.StatusConflict, err)
+		return
+	}
+	t.writeJSON(w, http.StatusOK, t.launcher.status())
+}
+
+func (t *apiServer) handleRestart(w http.ResponseWriter, r *http.Request) {
+	if err := t.launcher.restartChild("", ""); err != nil {
+		t.writeError(w, http.StatusConflict, err)
+		return
+	}
+	t.writeJSON(w, http.StatusOK, t.launcher.status())
+}
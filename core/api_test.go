@@ -0,0 +1,66 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAPIToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, APITokenFilename)
+
+	token, err := apiToken(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(token), 64)
+	again, err := apiToken(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, again, token)
+}
+
+func TestAPIAuthorize(t *testing.T) {
+	s := &apiServer{logger: logrus.NewEntry(logrus.New()), token: "secret"}
+	h := s.authorize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, c := range []struct {
+		host, authorization string
+		status              int
+	}{
+		{"127.0.0.1:8887", "Bearer secret", http.StatusNoContent},
+		{"localhost:8887", "Bearer secret", http.StatusNoContent},
+		{"[::1]:8887", "Bearer secret", http.StatusNoContent},
+		{"127.0.0.1:8887", "", http.StatusUnauthorized},
+		{"127.0.0.1:8887", "Bearer wrong", http.StatusUnauthorized},
+		{"attacker.example.com:8887", "Bearer secret", http.StatusForbidden},
+	} {
+		req := httptest.NewRequest("POST", "/update", nil)
+		req.Host = c.host
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, c.status, c.host+" "+c.authorization)
+	}
+}
+
+func TestUpdateRunning(t *testing.T) {
+	l := &Launcher{updating: 1}
+	_, _, err := l.update()
+	assert.Equal(t, err, ErrUpdateRunning)
+}
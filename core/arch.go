@@ -0,0 +1,104 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// hostArchs returns the architectures of launcher builds that run on this
+// machine, the native one first. On Apple Silicon the native architecture
+// is arm64 even when opendex-launcher itself runs as amd64 under Rosetta.
+// amd64 builds come last on the arm64 hosts that emulate them, Rosetta on
+// macOS and the x64 emulation of Windows on Arm. Other hosts only run
+// native builds.
+func hostArchs(goos string, goarch string, sysctl func(name string) string) []string {
+	native := goarch
+	if goos == "darwin" && sysctl("hw.optional.arm64") == "1" {
+		native = "arm64"
+	}
+	if native == "arm64" && (goos == "darwin" || goos == "windows") {
+		return []string{native, "amd64"}
+	}
+	return []string{native}
+}
+
+// sysctl returns the value of the sysctl name, empty when it does not exist.
+func sysctl(name string) string {
+	output, err := exec.Command("sysctl", "-n", name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// archs returns the architectures of launcher builds to look for, in order
+// of preference.
+func (t *GithubClient) archs() []string {
+	if len(t.Archs) == 0 {
+		return []string{runtime.GOARCH}
+	}
+	return t.Archs
+}
+
+// useArch selects the architecture of the launcher build to install and
+// notes when it will not run natively.
+func (t *GithubClient) useArch(arch string) {
+	t.mu.Lock()
+	t.arch = arch
+	t.mu.Unlock()
+	if native := t.archs()[0]; arch != native {
+		t.Logger.Warnf("No %s/%s launcher build, using the %s build, which runs emulated (by Rosetta on Apple Silicon, by the x64 emulation on Windows on Arm)", runtime.GOOS, native, arch)
+	}
+}
+
+// selectedArch returns the architecture of the launcher build being
+// installed.
+func (t *GithubClient) selectedArch() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.arch == "" {
+		return t.archs()[0]
+	}
+	return t.arch
+}
+
+// releaseAsset returns the name of the launcher release asset for arch.
+func releaseAsset(arch string) string {
+	return fmt.Sprintf("launcher-%s-%s.zip", runtime.GOOS, arch)
+}
+
+// releaseArch returns the preferred architecture the release tag has a
+// launcher build for. When the assets cannot be listed the native one is
+// tried.
+func (t *GithubClient) releaseArch(tag string) string {
+	archs := t.archs()
+	body, err := t.doGet(fmt.Sprintf("https://api.github.com/repos/opendexnetwork/opendex-docker/releases/tags/%s", tag))
+	if err != nil {
+		t.Logger.Debugf("Failed to list the assets of %s: %s", tag, err)
+		return archs[0]
+	}
+	var release struct {
+		Assets []struct {
+			Name string `json:"name"`
+			Size int64  `json:"size"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return archs[0]
+	}
+	for _, arch := range archs {
+		for _, asset := range release.Assets {
+			if asset.Name != releaseAsset(arch) {
+				continue
+			}
+			if asset.Size > 0 {
+				t.expectSize(fmt.Sprintf("https://github.com/opendexnetwork/opendex-docker/releases/download/%s/%s", tag, asset.Name), asset.Size)
+			}
+			return arch
+		}
+	}
+	return archs[0]
+}
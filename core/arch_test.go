@@ -0,0 +1,50 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHostArchs(t *testing.T) {
+	appleSilicon := func(name string) string {
+		if name == "hw.optional.arm64" {
+			return "1"
+		}
+		return ""
+	}
+	intel := func(name string) string { return "" }
+
+	assert.Equal(t, hostArchs("darwin", "amd64", appleSilicon), []string{"arm64", "amd64"})
+	assert.Equal(t, hostArchs("darwin", "arm64", appleSilicon), []string{"arm64", "amd64"})
+	assert.Equal(t, hostArchs("darwin", "amd64", intel), []string{"amd64"})
+	assert.Equal(t, hostArchs("linux", "amd64", appleSilicon), []string{"amd64"})
+	assert.Equal(t, hostArchs("linux", "arm64", intel), []string{"arm64"})
+	assert.Equal(t, hostArchs("linux", "arm", intel), []string{"arm"})
+	assert.Equal(t, hostArchs("windows", "arm64", intel), []string{"arm64", "amd64"})
+}
+
+func TestSelectArch(t *testing.T) {
+	body := ""
+	c := NewGithubClient()
+	c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body)), Header: make(http.Header)}, nil
+	})}
+	c.Archs = []string{"arm64", "amd64"}
+
+	body = `{"assets": [{"name": "launcher-` + runtime.GOOS + `-amd64.zip"}, {"name": "launcher-` + runtime.GOOS + `-arm64.zip"}]}`
+	assert.Equal(t, c.releaseArch("21.02.10"), "arm64")
+	body = `{"assets": [{"name": "launcher-` + runtime.GOOS + `-amd64.zip"}]}`
+	assert.Equal(t, c.releaseArch("21.02.10"), "amd64")
+
+	body = `{"total_count": 1, "artifacts": [{"name": "` + runtime.GOOS + `-amd64", "archive_download_url": "https://example.com/amd64.zip"}]}`
+	url, err := c.getWorkflowDownloadUrl(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, url, "https://example.com/amd64.zip")
+	assert.Equal(t, c.selectedArch(), "amd64")
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// bootstrapArgs holds the parsed command line of opendex-launcher:
+//
+//	opendex-launcher [bootstrap flags] [--] [child args]
+//
+// Bootstrap flags are only recognized at the beginning of the command line.
+// The first argument which is not a known bootstrap flag starts the child
+// arguments, and everything after "--" is passed to the child untouched.
+type bootstrapArgs struct {
+	network     string
+	branch      string
+	accessToken string
+	profile     string
+
+	progress string
+	timeout  time.Duration
+	logLevel string
+
+	printConfig bool
+	overrides   overrideFlag
+	traceHTTP   bool
+	dev         string
+
+	fallbackToMaster bool
+	yes              bool
+	ephemeral        bool
+	watch            bool
+	watchInterval    time.Duration
+
+	// rest holds the arguments following the bootstrap flags.
+	rest []string
+	// verbatim is set when rest followed the "--" separator. Such arguments
+	// never match a bootstrap command.
+	verbatim bool
+}
+
+func newFlagSet(a *bootstrapArgs) *flag.FlagSet {
+	fs := flag.NewFlagSet("opendex-launcher", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.StringVar(&a.network, "network", "", "network to run (overrides $NETWORK)")
+	fs.StringVar(&a.branch, "branch", "", "opendex-docker branch to run (overrides $BRANCH)")
+	fs.StringVar(&a.profile, "profile", "", "launch profile of the config file to use")
+	fs.StringVar(&a.accessToken, "access-token", "", "GitHub access token (overrides $GITHUB_ACCESS_TOKEN and the config file)")
+	fs.StringVar(&a.dev, "dev", "", "build and run the launcher of the opendex-docker checkout at this path")
+	fs.StringVar(&a.progress, "progress", "", "progress output format (json)")
+	fs.StringVar(&a.logLevel, "log-level", "", "lowest level of log lines shown (trace, debug, info, warn, error)")
+	fs.Var(&a.overrides, "o", "override a config key for this run, e.g. -o GitHub.graphql=false (repeatable)")
+	fs.BoolVar(&a.printConfig, "print-config", false, "print the effective configuration and exit")
+	fs.BoolVar(&a.fallbackToMaster, "fallback-to-master", false, "run the launcher of master when the branch has no launcher build")
+	fs.BoolVar(&a.ephemeral, "ephemeral", false, "run simnet or testnet with a temporary data dir removed on exit")
+	fs.BoolVar(&a.watch, "watch", false, "poll the branch and restart the launcher on every new build")
+	fs.DurationVar(&a.watchInterval, "watch-interval", defaultWatchInterval, "how often to poll the branch with --watch")
+	fs.BoolVar(&a.yes, "yes", false, "download without asking for confirmation, however large")
+	fs.BoolVar(&a.traceHTTP, "trace-http", false, "log every request to GitHub with its status, timing and rate limit")
+	fs.DurationVar(&a.timeout, "timeout", 0, "give up when the launcher is not resolved, downloaded and verified within this duration (e.g. 5m)")
+	return fs
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func parseArgs(args []string) (*bootstrapArgs, error) {
+	a := &bootstrapArgs{}
+	fs := newFlagSet(a)
+
+	var flags []string
+	i := 0
+	for ; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			a.verbatim = true
+			i++
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		hasValue := false
+		if j := strings.Index(name, "="); j >= 0 {
+			name = name[:j]
+			hasValue = true
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			// unknown flags belong to the child launcher
+			break
+		}
+		flags = append(flags, arg)
+		if !hasValue && !isBoolFlag(f) {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag needs an argument: %s", arg)
+			}
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	a.rest = args[i:]
+
+	if err := fs.Parse(flags); err != nil {
+		return nil, fmt.Errorf("parse flags: %w", err)
+	}
+
+	return a, nil
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseArgs(t *testing.T) {
+	a, err := parseArgs([]string{"--network", "testnet", "--branch=feat/foo", "setup", "--network", "simnet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, a.network, "testnet")
+	assert.Equal(t, a.branch, "feat/foo")
+	assert.Equal(t, a.rest, []string{"setup", "--network", "simnet"})
+	assert.Equal(t, a.verbatim, false)
+}
+
+func TestParseArgsSeparator(t *testing.T) {
+	a, err := parseArgs([]string{"-network=simnet", "--", "--branch", "x", "env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, a.network, "simnet")
+	assert.Equal(t, a.branch, "")
+	assert.Equal(t, a.rest, []string{"--branch", "x", "env"})
+	assert.Equal(t, a.verbatim, true)
+}
+
+func TestParseArgsUnknownFlag(t *testing.T) {
+	a, err := parseArgs([]string{"--help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, a.rest, []string{"--help"})
+}
+
+func TestParseArgsMissingValue(t *testing.T) {
+	_, err := parseArgs([]string{"--network"})
+	assert.Equal(t, err != nil, true)
+}
+
+func TestAccessTokenPrecedence(t *testing.T) {
+	os.Setenv("GITHUB_ACCESS_TOKEN", "env-token")
+	defer os.Unsetenv("GITHUB_ACCESS_TOKEN")
+
+	l := &Launcher{args: &bootstrapArgs{}, config: DefaultConfig()}
+	l.config.GitHub.AccessToken = "config-token"
+	assert.Equal(t, l.accessTokens(), []string{"env-token"})
+	assert.Equal(t, l.resolveAccessToken().Source, SourceEnv)
+
+	l.args.accessToken = "flag-token"
+	assert.Equal(t, l.accessTokens(), []string{"flag-token"})
+	assert.Equal(t, l.resolveAccessToken().Source, SourceFlag)
+}
+
+func TestParseArgsTimeout(t *testing.T) {
+	a, err := parseArgs([]string{"--timeout", "90s", "setup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, a.timeout, 90*time.Second)
+	assert.Equal(t, a.rest, []string{"setup"})
+}
+
+func TestParseArgsWatch(t *testing.T) {
+	a, err := parseArgs([]string{"--watch", "status"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, a.watch, true)
+	assert.Equal(t, a.watchInterval, defaultWatchInterval)
+	assert.Equal(t, a.rest, []string{"status"})
+
+	a, err = parseArgs([]string{"--watch", "--watch-interval", "30s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, a.watchInterval, 30*time.Second)
+	assert.Equal(t, detachArgs(a, "simnet", "master", nil), []string{"--network", "simnet", "--branch", "master", "--watch", "--watch-interval", "30s", "--"})
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditFilename is the append-only log of the actions of opendex-launcher in
+// the launcher dir, one JSON record per line.
+const AuditFilename = "audit.log"
+
+// Audited actions.
+const (
+	AuditResolved   = "resolved"
+	AuditDownloaded = "downloaded"
+	AuditExecuted   = "executed"
+	AuditUpdated    = "updated"
+	AuditPurged     = "purged"
+)
+
+// AuditRecord is an action recorded in the audit log.
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Network string    `json:"network,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	Commit  string    `json:"commit,omitempty"`
+	Url     string    `json:"url,omitempty"`
+	Sha256  string    `json:"sha256,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// appendAudit appends r to the audit log file. The file is only ever
+// appended to, records are never rewritten.
+func appendAudit(file string, r AuditRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	// a single write keeps the records of concurrent runs on separate lines
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// audit records r with the current time, network and branch. Failing to
+// record is logged but never stops the action.
+func (t *Launcher) audit(r AuditRecord) {
+	if t.launcherDir == "" {
+		return
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+	if r.Network == "" {
+		r.Network = t.network
+	}
+	if r.Branch == "" {
+		r.Branch = t.branch
+	}
+	if err := appendAudit(filepath.Join(t.launcherDir, AuditFilename), r); err != nil {
+		t.logger.Warnf("Failed to write the audit log: %s", err)
+	}
+}
+
+// auditExecuted records that the launcher of commit is started, with the
+// hash it was installed with.
+func (t *Launcher) auditExecuted(launcher string, commit string) {
+	r := AuditRecord{Action: AuditExecuted, Commit: commit, Detail: launcher}
+	if m, err := readMetadata(filepath.Dir(launcher)); err == nil {
+		r.Branch = m.Branch
+		r.Sha256 = m.Sha256
+	}
+	t.audit(r)
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := &Launcher{launcherDir: dir, network: "testnet", branch: "master", logger: logrus.NewEntry(logrus.New())}
+	l.audit(AuditRecord{Action: AuditResolved, Commit: "abc"})
+	l.audit(AuditRecord{Action: AuditPurged, Branch: "21.02.10", Detail: "stale restore directory"})
+
+	f, err := os.Open(filepath.Join(dir, AuditFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[0].Action, AuditResolved)
+	assert.Equal(t, records[0].Network, "testnet")
+	assert.Equal(t, records[0].Branch, "master")
+	assert.Equal(t, records[0].Commit, "abc")
+	assert.Equal(t, records[0].Time.IsZero(), false)
+	assert.Equal(t, records[1].Branch, "21.02.10")
+	assert.Equal(t, records[1].Detail, "stale restore directory")
+}
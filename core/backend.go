@@ -0,0 +1,176 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Container backends the launcher can run its containers with.
+const (
+	BackendAuto   = "auto"
+	BackendDocker = "docker"
+	BackendPodman = "podman"
+)
+
+// minPodmanVersion is the first Podman release whose API service is
+// compatible enough with the Docker API for the launcher.
+const minPodmanVersion = "3.0.0"
+
+var ErrNoBackend = errors.New("no container backend found")
+
+// containerBackend is the container engine found on the host. Host is the
+// socket its Docker compatible API listens on, empty for the default of
+// Docker.
+type containerBackend struct {
+	Name    string
+	CLI     string
+	Host    string
+	Version string
+}
+
+// backendProbe looks for container backends. Its functions are replaced in
+// tests.
+type backendProbe struct {
+	goos     string
+	lookPath func(file string) (string, error)
+	stat     func(name string) (os.FileInfo, error)
+	output   func(name string, args ...string) ([]byte, error)
+	getenv   func(key string) string
+}
+
+func newBackendProbe() backendProbe {
+	return backendProbe{
+		goos:     runtime.GOOS,
+		lookPath: exec.LookPath,
+		stat:     os.Stat,
+		output: func(name string, args ...string) ([]byte, error) {
+			return exec.Command(name, args...).Output()
+		},
+		getenv: os.Getenv,
+	}
+}
+
+// detect returns the backend to use: Docker when it runs, Podman when it is
+// installed and Docker does not run, and Docker when its CLI is installed
+// without Podman. preferred restricts the search to one backend.
+func (p backendProbe) detect(preferred string) (containerBackend, error) {
+	docker := containerBackend{Name: BackendDocker, CLI: "docker"}
+	installed, running := p.docker()
+	switch preferred {
+	case BackendDocker:
+		if !installed {
+			return docker, fmt.Errorf("%w: docker is not installed", ErrNoBackend)
+		}
+		return docker, nil
+	case BackendPodman:
+		return p.podman()
+	case BackendAuto:
+		if running {
+			return docker, nil
+		}
+		if _, err := p.lookPath("podman"); err == nil {
+			return p.podman()
+		}
+		if installed {
+			return docker, nil
+		}
+		return containerBackend{}, fmt.Errorf("%w: neither docker nor podman is installed", ErrNoBackend)
+	default:
+		return containerBackend{}, fmt.Errorf("unknown container backend: %s", preferred)
+	}
+}
+
+// docker reports whether the docker CLI is installed and whether the Docker
+// daemon runs. Only the default socket on Linux is checked; $DOCKER_HOST and
+// other platforms are trusted.
+func (p backendProbe) docker() (bool, bool) {
+	if _, err := p.lookPath("docker"); err != nil {
+		return false, false
+	}
+	if p.getenv("DOCKER_HOST") != "" || p.goos != "linux" {
+		return true, true
+	}
+	// podman-docker installs a docker CLI without a Docker daemon
+	_, err := p.stat("/var/run/docker.sock")
+	return true, err == nil
+}
+
+func (p backendProbe) podman() (containerBackend, error) {
+	b := containerBackend{Name: BackendPodman, CLI: "podman"}
+	if _, err := p.lookPath("podman"); err != nil {
+		return b, fmt.Errorf("%w: podman is not installed", ErrNoBackend)
+	}
+	version, err := p.output("podman", "version", "--format", "{{.Client.Version}}")
+	if err != nil {
+		return b, fmt.Errorf("podman version: %w", err)
+	}
+	b.Version = strings.TrimSpace(string(version))
+	if compareVersions(b.Version, minPodmanVersion) < 0 {
+		return b, fmt.Errorf("podman %s is not supported, %s or newer is required", b.Version, minPodmanVersion)
+	}
+	socket, err := p.output("podman", "info", "--format", "{{.Host.RemoteSocket.Path}}")
+	if err != nil {
+		return b, fmt.Errorf("podman info: %w", err)
+	}
+	path := strings.TrimPrefix(strings.TrimSpace(string(socket)), "unix://")
+	if path == "" {
+		path = p.podmanSocket()
+	}
+	if p.goos == "linux" {
+		if _, err := p.stat(path); err != nil {
+			return b, fmt.Errorf("podman socket %s is not available, enable it with systemctl --user enable --now podman.socket", path)
+		}
+	}
+	b.Host = "unix://" + path
+	return b, nil
+}
+
+// podmanSocket returns the default socket of the rootless or, for root, the
+// rootful Podman API service.
+func (p backendProbe) podmanSocket() string {
+	if dir := p.getenv("XDG_RUNTIME_DIR"); dir != "" && os.Geteuid() != 0 {
+		return filepath.Join(dir, "podman", "podman.sock")
+	}
+	return "/run/podman/podman.sock"
+}
+
+// setupBackend finds the container backend once and passes it to the
+// launcher, which talks to Podman through $DOCKER_HOST. A missing backend and
+// the pitfalls of WSL are only warned about, the launcher reports them in
+// detail.
+func (t *Launcher) setupBackend() {
+	t.backendOnce.Do(t.detectBackend)
+}
+
+func (t *Launcher) detectBackend() {
+	if runtime.GOOS == "linux" {
+		t.checkWSL()
+	}
+	b, err := newBackendProbe().detect(t.config.Docker.Backend)
+	if err != nil {
+		t.logger.Warnf("Container backend: %s", err)
+		return
+	}
+	t.backend = b
+	if b.Name != BackendPodman {
+		return
+	}
+	t.logger.Infof("Using Podman %s through %s", b.Version, b.Host)
+	if os.Getenv("DOCKER_HOST") == "" {
+		t.childEnv = append(t.childEnv, "DOCKER_HOST="+b.Host)
+	}
+	t.childEnv = append(t.childEnv, "OPENDEX_CONTAINER_BACKEND="+b.Name)
+}
+
+// containerCLI returns the command line tool of the container backend.
+func (t *Launcher) containerCLI() string {
+	if t.backend.CLI == "" {
+		return "docker"
+	}
+	return t.backend.CLI
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func fakeProbe(installed []string, files []string, outputs map[string]string) backendProbe {
+	has := func(list []string, s string) bool {
+		for _, item := range list {
+			if item == s {
+				return true
+			}
+		}
+		return false
+	}
+	return backendProbe{
+		goos: "linux",
+		lookPath: func(file string) (string, error) {
+			if has(installed, file) {
+				return "/usr/bin/" + file, nil
+			}
+			return "", exec.ErrNotFound
+		},
+		stat: func(name string) (os.FileInfo, error) {
+			if has(files, name) {
+				return nil, nil
+			}
+			return nil, os.ErrNotExist
+		},
+		output: func(name string, args ...string) ([]byte, error) {
+			return []byte(outputs[args[0]]), nil
+		},
+		getenv: func(key string) string { return "" },
+	}
+}
+
+func TestDetectBackend(t *testing.T) {
+	podman := map[string]string{"version": "4.3.1\n", "info": "/run/user/1000/podman/podman.sock\n"}
+
+	b, err := fakeProbe([]string{"docker", "podman"}, []string{"/var/run/docker.sock"}, podman).detect(BackendAuto)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, b.Name, BackendDocker)
+
+	// podman-docker without a Docker daemon
+	b, err = fakeProbe([]string{"docker", "podman"}, []string{"/run/user/1000/podman/podman.sock"}, podman).detect(BackendAuto)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, b.Name, BackendPodman)
+	assert.Equal(t, b.CLI, "podman")
+	assert.Equal(t, b.Host, "unix:///run/user/1000/podman/podman.sock")
+	assert.Equal(t, b.Version, "4.3.1")
+
+	_, err = fakeProbe([]string{"podman"}, nil, podman).detect(BackendAuto)
+	assert.Equal(t, err != nil, true)
+
+	_, err = fakeProbe([]string{"podman"}, []string{"/run/user/1000/podman/podman.sock"}, map[string]string{"version": "2.2.1"}).detect(BackendPodman)
+	assert.Equal(t, err != nil, true)
+
+	b, err = fakeProbe([]string{"docker"}, nil, nil).detect(BackendAuto)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, b.Name, BackendDocker)
+
+	_, err = fakeProbe(nil, nil, nil).detect(BackendAuto)
+	assert.Equal(t, errors.Is(err, ErrNoBackend), true)
+	_, err = fakeProbe([]string{"podman"}, nil, podman).detect(BackendDocker)
+	assert.Equal(t, errors.Is(err, ErrNoBackend), true)
+}
@@ -0,0 +1,326 @@
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BackupManifestName is the archive entry listing every backed up file.
+const BackupManifestName = "MANIFEST.json"
+
+// defaultBackupExcludes leaves out chain data and logs, which the nodes
+// rebuild on their own.
+var defaultBackupExcludes = []string{
+	"data/bitcoind",
+	"data/litecoind",
+	"data/geth",
+	"logs",
+	"*.log",
+}
+
+type Backup struct {
+	Dir     string   `toml:"dir" comment:"Directory backup archives are written to, defaults to the backups folder in the home directory"`
+	Exclude []string `toml:"exclude" comment:"Additional path patterns of the network directory to leave out of backups"`
+}
+
+type BackupFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+type BackupManifest struct {
+	Network   string       `json:"network"`
+	CreatedAt time.Time    `json:"created_at"`
+	Files     []BackupFile `json:"files"`
+}
+
+// backupExcluded reports whether the slash separated path rel matches one of
+// patterns, either as a whole or by its base name.
+func backupExcluded(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok && !strings.Contains(pattern, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func addBackupFile(tw *tar.Writer, file string, rel string, info os.FileInfo) (BackupFile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return BackupFile{}, err
+	}
+	defer f.Close()
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return BackupFile{}, err
+	}
+	header.Name = rel
+	if err := tw.WriteHeader(header); err != nil {
+		return BackupFile{}, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tw, h), f)
+	if err != nil {
+		return BackupFile{}, err
+	}
+	return BackupFile{Path: rel, Size: n, Sha256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// createBackup writes the files of dir not matching excludes into the
+// tar.gz archive out. The manifest is the last entry of the archive.
+func createBackup(dir string, out string, network string, excludes []string) (*BackupManifest, error) {
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	manifest := &BackupManifest{Network: network, CreatedAt: time.Now().UTC()}
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if backupExcluded(rel, excludes) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			if Debug && !info.IsDir() {
+				fmt.Printf("Skip %s (not a regular file)\n", rel)
+			}
+			return nil
+		}
+		entry, err := addBackupFile(tw, file, rel, info)
+		if err != nil {
+			return fmt.Errorf("add %s: %w", rel, err)
+		}
+		manifest.Files = append(manifest.Files, entry)
+		return nil
+	})
+	if err != nil {
+		os.Remove(out)
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	header := &tar.Header{Name: BackupManifestName, Mode: 0600, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, f.Close()
+}
+
+// extractBackup extracts archive into dir and checks every file against the
+// manifest of the archive.
+func extractBackup(archive string, dir string) (*BackupManifest, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	tr := tar.NewReader(gr)
+
+	var manifest *BackupManifest
+	hashes := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tar: %w", err)
+		}
+		if header.Name == BackupManifestName {
+			manifest = &BackupManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("decode manifest: %w", err)
+			}
+			continue
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("illegal path in archive: %s", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeReg:
+		case tar.TypeDir:
+			continue
+		default:
+			// links and special files are never part of a backup, they could
+			// redirect the restore outside dir
+			return nil, fmt.Errorf("unsupported entry in archive: %s", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return nil, err
+		}
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		_, err = io.Copy(io.MultiWriter(out, h), tr)
+		out.Close()
+		if err != nil {
+			return nil, fmt.Errorf("extract %s: %w", name, err)
+		}
+		hashes[name] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if manifest == nil {
+		return nil, errors.New("archive has no manifest")
+	}
+	for _, file := range manifest.Files {
+		hash, ok := hashes[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("%s is missing from the archive", file.Path)
+		}
+		if hash != file.Sha256 {
+			return nil, fmt.Errorf("%s is corrupted: sha256 %s, expected %s", file.Path, hash, file.Sha256)
+		}
+		delete(hashes, file.Path)
+	}
+	for name := range hashes {
+		return nil, fmt.Errorf("%s is not in the manifest", name)
+	}
+	return manifest, nil
+}
+
+// restoreBackup verifies archive in a temporary directory next to dir and
+// then moves the files into dir. Existing files are only replaced, and backups
+// of another network only restored, with force.
+func restoreBackup(archive string, dir string, network string, force bool) (*BackupManifest, error) {
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), ".restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest, err := extractBackup(archive, tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Network != network && !force {
+		return nil, fmt.Errorf("archive is a backup of %s, not %s, use --force to restore it anyway", manifest.Network, network)
+	}
+	if !force {
+		var conflicts []string
+		for _, file := range manifest.Files {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file.Path))); err == nil {
+				conflicts = append(conflicts, file.Path)
+			}
+		}
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("%d files would be overwritten (%s), use --force to replace them", len(conflicts), strings.Join(conflicts, ", "))
+		}
+	}
+
+	for _, file := range manifest.Files {
+		target := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(filepath.Join(tmp, filepath.FromSlash(file.Path)), target); err != nil {
+			return nil, fmt.Errorf("restore %s: %w", file.Path, err)
+		}
+	}
+	return manifest, nil
+}
+
+func (t *Launcher) backupDir() string {
+	if t.config.Backup.Dir != "" {
+		return t.config.Backup.Dir
+	}
+	return filepath.Join(t.homeDir, "backups")
+}
+
+func (t *Launcher) runBackupCreate(args []string) error {
+	fs := flag.NewFlagSet("backup create", flag.ContinueOnError)
+	output := fs.String("output", "", "archive to write (default: <backup dir>/<network>-<timestamp>.tar.gz)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	out := *output
+	if out == "" {
+		dir := t.backupDir()
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("mkdir: %w", err)
+		}
+		out = filepath.Join(dir, fmt.Sprintf("%s-%s.tar.gz", t.network, time.Now().UTC().Format("20060102T150405Z")))
+	}
+
+	excludes := append(append([]string{}, defaultBackupExcludes...), t.config.Backup.Exclude...)
+	manifest, err := createBackup(t.networkDir, out, t.network, excludes)
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+	fmt.Printf("Backed up %d files of %s to %s\n", len(manifest.Files), t.network, out)
+	return nil
+}
+
+func (t *Launcher) runBackupRestore(args []string) error {
+	fs := flag.NewFlagSet("backup restore", flag.ContinueOnError)
+	force := fs.Bool("force", false, "replace existing files and allow restoring another network")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: backup restore [--force] <archive>")
+	}
+
+	manifest, err := restoreBackup(fs.Arg(0), t.networkDir, t.network, *force)
+	if err != nil {
+		return fmt.Errorf("restore backup: %w", err)
+	}
+	fmt.Printf("Restored %d files from %s (created %s)\n", len(manifest.Files), fs.Arg(0), manifest.CreatedAt.Format(time.RFC3339))
+	return nil
+}
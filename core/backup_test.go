@@ -0,0 +1,66 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupExcluded(t *testing.T) {
+	assert.Equal(t, backupExcluded("data/bitcoind", defaultBackupExcludes), true)
+	assert.Equal(t, backupExcluded("logs", defaultBackupExcludes), true)
+	assert.Equal(t, backupExcluded("data/lndbtc/lnd.log", defaultBackupExcludes), true)
+	assert.Equal(t, backupExcluded("data/lndbtc/data/graph/mainnet/channel.db", defaultBackupExcludes), false)
+	assert.Equal(t, backupExcluded("data/opendexd", defaultBackupExcludes), false)
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	root, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	src := filepath.Join(root, "src")
+	files := map[string]string{
+		"data/opendexd/wallet.db": "wallet",
+		"data/bitcoind/blocks":    "chain",
+		"config.toml":             "config",
+	}
+	for name, content := range files {
+		file := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archive := filepath.Join(root, "backup.tar.gz")
+	manifest, err := createBackup(src, archive, "simnet", defaultBackupExcludes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(manifest.Files), 2)
+
+	dst := filepath.Join(root, "dst")
+	if err := os.Mkdir(dst, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restoreBackup(archive, dst, "simnet", false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dst, "data", "opendexd", "wallet.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), "wallet")
+
+	_, err = restoreBackup(archive, dst, "simnet", false)
+	assert.Equal(t, err != nil, true)
+	_, err = restoreBackup(archive, dst, "mainnet", true)
+	assert.Equal(t, err, nil)
+}
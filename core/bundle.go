@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var commitRef = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// importBundle installs the launcher archive file, as published by CI, for
+// commit of branch. commit may be empty when the manifest of the archive
+// names it.
+func (t *Launcher) importBundle(file string, branch string, commit string) (string, error) {
+	if t.readOnlyVersions {
+		return "", ErrReadOnlyVersions
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(t.launcherVersionsDir), "import-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	archive := filepath.Join(tmp, "launcher.zip")
+	if err := linkOrCopy(file, archive); err != nil {
+		return "", err
+	}
+	if err := t.github.unzipTo(archive, tmp); err != nil {
+		return "", fmt.Errorf("extract: %w", err)
+	}
+	if err := normalizeLayout(tmp, t.github.selectedArch()); err != nil {
+		return "", err
+	}
+	manifest, err := readManifest(tmp)
+	if err != nil {
+		return "", err
+	}
+	if commit == "" && manifest != nil {
+		commit = manifest.GitCommit
+	}
+	if !commitRef.MatchString(commit) {
+		return "", fmt.Errorf("the full commit of the bundle is required, got %q", commit)
+	}
+
+	commitDir := filepath.Join(t.launcherVersionsDir, commit)
+	exists, err := utils.FileExists(commitDir)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("%s is installed already", shortCommit(commit))
+	}
+	hash, err := sha256File(archive)
+	if err != nil {
+		return "", err
+	}
+	m := &VersionMetadata{Branch: branch, Commit: commit, ArchiveSha256: hash, Manifest: manifest, Imported: true, InstalledAt: time.Now()}
+	if err := writeMetadata(tmp, m); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, commitDir); err != nil {
+		return "", err
+	}
+
+	launcher := t.launcherPath(commit)
+	if _, err := t.verifyInstalled(commit, launcher); err != nil {
+		_ = os.RemoveAll(commitDir)
+		return "", err
+	}
+	if err := t.checkPolicy(commit, launcher, true); err != nil {
+		return "", err
+	}
+	t.emit(Event{Type: EventInstalled, Branch: branch, Commit: commit})
+	return commit, nil
+}
+
+func (t *Launcher) runBundleImport(args []string) error {
+	fs := flag.NewFlagSet("bundle import", flag.ContinueOnError)
+	branch := fs.String("branch", "", "branch or release tag the bundle was built from")
+	commit := fs.String("commit", "", "commit the bundle was built from, read from its manifest when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *branch == "" || fs.NArg() != 1 {
+		return errors.New("usage: bundle import --branch <branch> [--commit <commit>] <launcher.zip>")
+	}
+
+	imported, err := t.importBundle(fs.Arg(0), *branch, *commit)
+	if err != nil {
+		t.logger.Errorf("Failed to import %s: %s", fs.Arg(0), err)
+		return &ExitCodeError{Code: 1}
+	}
+	fmt.Printf("Imported %s@%s\n", *branch, shortCommit(imported))
+	return nil
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"archive/zip"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBundleImport(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	home, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	archive := filepath.Join(home, "launcher-linux-amd64.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		launcherBinary(): "launcher",
+		"manifest.json":  `{"git_commit": "` + commit + `"}`,
+	} {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	l := &Launcher{
+		homeDir:             home,
+		launcherDir:         filepath.Join(home, "launcher"),
+		launcherVersionsDir: filepath.Join(home, "launcher", "versions"),
+		config:              DefaultConfig(),
+		logger:              logrus.NewEntry(logrus.New()),
+		github:              NewGithubClient(),
+	}
+	if err := os.MkdirAll(l.launcherVersionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := l.importBundle(archive, "21.02.10", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imported, commit)
+	m, err := readMetadata(filepath.Join(l.launcherVersionsDir, commit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, m.Imported, true)
+	assert.Equal(t, m.Branch, "21.02.10")
+	assert.Equal(t, m.Sha256 != "", true)
+
+	_, err = l.importBundle(archive, "21.02.10", "")
+	assert.Equal(t, err != nil, true)
+
+	l.config.Enterprise.Enabled = true
+	resolver := &importedResolver{launcher: l}
+	resolved, err := resolver.GetHeadCommit("21.02.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, resolved, commit)
+	_, err = resolver.GetHeadCommit("master")
+	assert.Equal(t, errors.Is(err, ErrEnterprise), true)
+
+	assert.Equal(t, l.checkImported(commit, l.launcherPath(commit), true), nil)
+	err = l.checkImported("fedcba9", l.launcherPath("fedcba9"), false)
+	assert.Equal(t, errors.Is(err, ErrEnterprise), true)
+
+	_, err = forbiddenClient().Get("https://api.github.com/")
+	assert.Equal(t, errors.Is(err, ErrEnterprise), true)
+}
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/utils"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// artifactCache stores downloaded launcher archives by their SHA256 so that
+// a commit installed before can be installed again without the network,
+// e.g. after a rollback or after its version was removed. The index maps
+// commits to archive hashes.
+type artifactCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func newArtifactCache(dir string) *artifactCache {
+	return &artifactCache{dir: dir}
+}
+
+func (c *artifactCache) blobPath(hash string) string {
+	return filepath.Join(c.dir, "sha256", hash[:2], hash)
+}
+
+func (c *artifactCache) indexPath() string {
+	return filepath.Join(c.dir, "index.json")
+}
+
+func (c *artifactCache) readIndex() (map[string]string, error) {
+	index := make(map[string]string)
+	data, err := ioutil.ReadFile(c.indexPath())
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("unmarshal index: %w", err)
+	}
+	return index, nil
+}
+
+func (c *artifactCache) writeIndex(index map[string]string) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.indexPath() + ".part"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.indexPath())
+}
+
+// store adds file as the archive of commit and returns its hash.
+func (c *artifactCache) store(commit string, file string) (string, error) {
+	hash, err := sha256File(file)
+	if err != nil {
+		return "", err
+	}
+	return c.storeHashed(commit, file, hash)
+}
+
+// storeHashed adds file, whose hash was computed while downloading it, as
+// the archive of commit.
+func (c *artifactCache) storeHashed(commit string, file string, hash string) (string, error) {
+	blob := c.blobPath(hash)
+	if _, err := os.Stat(blob); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+			return "", err
+		}
+		if err := linkOrCopy(file, blob); err != nil {
+			return "", err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, err := c.readIndex()
+	if err != nil {
+		return "", err
+	}
+	index[commit] = hash
+	return hash, c.writeIndex(index)
+}
+
+// has tells whether an archive of commit is cached, without checking its
+// hash like lookup does.
+func (c *artifactCache) has(commit string) bool {
+	c.mu.Lock()
+	index, err := c.readIndex()
+	c.mu.Unlock()
+	if err != nil {
+		return false
+	}
+	hash, ok := index[commit]
+	if !ok {
+		return false
+	}
+	exists, err := utils.FileExists(c.blobPath(hash))
+	return err == nil && exists
+}
+
+// lookup returns the cached archive of commit. A blob which does not match
+// its hash any more is removed.
+func (c *artifactCache) lookup(commit string) (string, string, bool) {
+	c.mu.Lock()
+	index, err := c.readIndex()
+	c.mu.Unlock()
+	if err != nil {
+		return "", "", false
+	}
+	hash, ok := index[commit]
+	if !ok {
+		return "", "", false
+	}
+	blob := c.blobPath(hash)
+	actual, err := sha256File(blob)
+	if err != nil {
+		return "", "", false
+	}
+	if actual != hash {
+		_ = os.Remove(blob)
+		return "", "", false
+	}
+	return blob, hash, true
+}
+
+// forget removes commit from the index and drops its archive when no other
+// commit uses it.
+func (c *artifactCache) forget(commit string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, err := c.readIndex()
+	if err != nil {
+		return err
+	}
+	hash, ok := index[commit]
+	if !ok {
+		return nil
+	}
+	delete(index, commit)
+	if err := c.writeIndex(index); err != nil {
+		return err
+	}
+	for _, other := range index {
+		if other == hash {
+			return nil
+		}
+	}
+	if err := os.Remove(c.blobPath(hash)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// pruneBinaries removes the stored binaries no file below versionsDir is
+// linked to any more.
+func (c *artifactCache) pruneBinaries(versionsDir string) error {
+	var inUse []os.FileInfo
+	err := filepath.Walk(versionsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			inUse = append(inUse, info)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(filepath.Join(c.dir, "binaries"), func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		for _, other := range inUse {
+			if os.SameFile(info, other) {
+				return nil
+			}
+		}
+		return os.Remove(path)
+	})
+}
+
+// dropBinary removes the stored copy of the binary with hash.
+func (c *artifactCache) dropBinary(hash string) error {
+	if err := os.Remove(filepath.Join(c.dir, "binaries", hash[:2], hash)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// dedupe replaces file by a hardlink to the stored copy of its content, or
+// stores file when its content was not seen before. It returns the bytes
+// saved.
+func (c *artifactCache) dedupe(file string, hash string) (int64, error) {
+	blob := filepath.Join(c.dir, "binaries", hash[:2], hash)
+	stored, err := os.Stat(blob)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+			return 0, err
+		}
+		return 0, os.Link(file, blob)
+	}
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0, err
+	}
+	if os.SameFile(stored, info) {
+		return 0, nil
+	}
+	tmp := file + ".link"
+	_ = os.Remove(tmp)
+	if err := os.Link(blob, tmp); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// linkOrCopy hardlinks src to dst, or copies it when the filesystem does not
+// support hardlinks between the two.
+func linkOrCopy(src string, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := dst + ".part"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArtifactCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newArtifactCache(filepath.Join(dir, "cache"))
+	archive := filepath.Join(dir, "launcher.zip")
+	if err := ioutil.WriteFile(archive, []byte("zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, ok := c.lookup("abc")
+	assert.Equal(t, ok, false)
+
+	hash, err := c.store("abc", archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, cached, ok := c.lookup("abc")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, cached, hash)
+
+	data, err := ioutil.ReadFile(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), "zip")
+
+	// a corrupted blob is dropped
+	if err := os.Remove(blob); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(blob, []byte("corrupted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, ok = c.lookup("abc")
+	assert.Equal(t, ok, false)
+}
+
+func TestArtifactCacheDedupe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newArtifactCache(filepath.Join(dir, "cache"))
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	for _, file := range []string{a, b} {
+		if err := ioutil.WriteFile(file, []byte("launcher"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := sha256File(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := c.dedupe(a, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, saved, int64(0))
+	saved, err = c.dedupe(b, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, saved, int64(len("launcher")))
+
+	infoA, _ := os.Stat(a)
+	infoB, _ := os.Stat(b)
+	assert.Equal(t, os.SameFile(infoA, infoB), true)
+}
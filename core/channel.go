@@ -0,0 +1,141 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Release channels can be given instead of a branch. They follow the newest
+// release: stable only takes final releases, rc release candidates as well.
+const (
+	ChannelStable = "stable"
+	ChannelRC     = "rc"
+)
+
+var ReleaseCandidateRef = regexp.MustCompile(`^\d{2}\.\d{2}\.\d{2}-rc\.?(\d*)$`)
+
+type Release struct {
+	TagName    string `json:"tag_name"`
+	Prerelease bool   `json:"prerelease"`
+	Draft      bool   `json:"draft"`
+}
+
+// GetReleases returns the latest releases of opendex-docker.
+func (t *GithubClient) GetReleases() ([]Release, error) {
+	body, err := t.doGet("https://api.github.com/repos/opendexnetwork/opendex-docker/releases?per_page=100")
+	if err != nil {
+		return nil, err
+	}
+	var result []Release
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func isChannel(branch string) bool {
+	return branch == ChannelStable || branch == ChannelRC
+}
+
+func isReleaseCandidate(ref string) bool {
+	return ReleaseCandidateRef.MatchString(ref)
+}
+
+// releaseLabel names the kind of release ref is, empty for branches.
+func releaseLabel(ref string) string {
+	switch {
+	case isReleaseCandidate(ref):
+		return "release candidate"
+	case releaseChannel(ref) == "stable":
+		return "stable release"
+	case releaseChannel(ref) == "prerelease":
+		return "prerelease"
+	default:
+		return ""
+	}
+}
+
+// describeRef returns ref at commit followed by its release label, e.g.
+// "21.02.10-rc.1@0123456 (release candidate)". commit may be empty.
+func describeRef(ref string, commit string) string {
+	s := ref
+	if commit != "" {
+		s += "@" + shortCommit(commit)
+	}
+	if label := releaseLabel(ref); label != "" {
+		s += fmt.Sprintf(" (%s)", label)
+	}
+	return s
+}
+
+// compareReleases orders release tags like compareVersions, with release
+// candidates before the final release and ordered by their number.
+func compareReleases(a string, b string) int {
+	if c := compareVersions(a, b); c != 0 {
+		return c
+	}
+	rc := func(v string) int {
+		m := ReleaseCandidateRef.FindStringSubmatch(v)
+		if m == nil {
+			return -1
+		}
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	ra, rb := rc(a), rc(b)
+	switch {
+	case ra == rb:
+		return 0
+	case ra == -1:
+		return 1
+	case rb == -1:
+		return -1
+	case ra < rb:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// latestRelease returns the newest release of channel. Both channels skip
+// drafts and prereleases, except that the rc channel takes release
+// candidates.
+func latestRelease(releases []Release, channel string) string {
+	latest := ""
+	for _, r := range releases {
+		if r.Draft || !ReleaseRef.MatchString(r.TagName) {
+			continue
+		}
+		rc := isReleaseCandidate(r.TagName)
+		if rc && channel != ChannelRC {
+			continue
+		}
+		if !rc && (r.Prerelease || strings.Contains(r.TagName, "-")) {
+			continue
+		}
+		if latest == "" || compareReleases(r.TagName, latest) > 0 {
+			latest = r.TagName
+		}
+	}
+	return latest
+}
+
+// resolveChannel points the branch to the newest release of the channel.
+func (t *Launcher) resolveChannel() error {
+	releases, err := t.github.GetReleases()
+	if err != nil {
+		return fmt.Errorf("get releases: %w", err)
+	}
+	tag := latestRelease(releases, t.channel)
+	if tag == "" {
+		return fmt.Errorf("no release in channel %s", t.channel)
+	}
+	if tag != t.branch {
+		t.logger.Infof("Channel %s is at %s", t.channel, describeRef(tag, ""))
+	}
+	t.branch = tag
+	return nil
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestCompareReleases(t *testing.T) {
+	assert.Equal(t, compareReleases("21.02.10", "21.02.10-rc.1"), 1)
+	assert.Equal(t, compareReleases("21.02.10-rc.1", "21.02.10-rc.2"), -1)
+	assert.Equal(t, compareReleases("21.02.10-rc.10", "21.02.10-rc.9"), 1)
+	assert.Equal(t, compareReleases("21.02.11-rc.1", "21.02.10"), 1)
+	assert.Equal(t, compareReleases("21.02.10", "21.02.10"), 0)
+}
+
+func TestLatestRelease(t *testing.T) {
+	releases := []Release{
+		{TagName: "21.02.10"},
+		{TagName: "21.02.11-rc.1", Prerelease: true},
+		{TagName: "21.02.11-beta", Prerelease: true},
+		{TagName: "21.02.12", Draft: true},
+		{TagName: "21.02.09"},
+		{TagName: "nightly"},
+	}
+	assert.Equal(t, latestRelease(releases, ChannelStable), "21.02.10")
+	assert.Equal(t, latestRelease(releases, ChannelRC), "21.02.11-rc.1")
+
+	releases = append(releases, Release{TagName: "21.02.11"})
+	assert.Equal(t, latestRelease(releases, ChannelRC), "21.02.11")
+}
+
+func TestDescribeRef(t *testing.T) {
+	assert.Equal(t, describeRef("21.02.10-rc.1", "0123456789"), "21.02.10-rc.1@0123456 (release candidate)")
+	assert.Equal(t, describeRef("21.02.10", ""), "21.02.10 (stable release)")
+	assert.Equal(t, describeRef("master", "0123456789"), "master@0123456")
+}
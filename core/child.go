@@ -0,0 +1,297 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// child is a running launcher process.
+type child struct {
+	cmd       *exec.Cmd
+	launcher  string
+	commit    string
+	version   string
+	startedAt time.Time
+	done      chan struct{}
+}
+
+// ChildStatus describes the managed launcher process.
+type ChildStatus struct {
+	Network   string    `json:"network"`
+	Branch    string    `json:"branch"`
+	Commit    string    `json:"commit,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	Launcher  string    `json:"launcher,omitempty"`
+	Running   bool      `json:"running"`
+	Pid       int       `json:"pid,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	// Downloads summarizes the latest launcher downloads, oldest first.
+	Downloads []Transfer `json:"downloads,omitempty"`
+}
+
+type restartRequest struct {
+	launcher string
+	commit   string
+}
+
+// childOutput returns the stdout and stderr of the launcher and a function
+// writing out incomplete lines after it exited. Output going to a terminal is
+// never prefixed, so that prompts keep working, but JSON log lines are
+// rendered for it.
+func (t *Launcher) childOutput() (io.Writer, io.Writer, func()) {
+	var flushers []interface{ Flush() error }
+	relay := func(f *os.File) io.Writer {
+		var w io.Writer = f
+		switch {
+		case isTerminal(f) && t.config.Output.RenderJSON:
+			r := newLogRenderer(f, t.logLevel(), os.Getenv("NO_COLOR") == "")
+			flushers = append(flushers, r)
+			w = r
+		case !isTerminal(f) && t.config.Output.Prefix:
+			p := newPrefixWriter(f, t.network)
+			flushers = append(flushers, p)
+			w = p
+		}
+		return w
+	}
+	stdout, stderr := relay(os.Stdout), relay(os.Stderr)
+	flush := func() {
+		for _, w := range flushers {
+			_ = w.Flush()
+		}
+	}
+	if t.output != nil {
+		stdout, stderr = io.MultiWriter(stdout, t.output), io.MultiWriter(stderr, t.output)
+	}
+	if t.shipper != nil {
+		stdout, stderr = io.MultiWriter(stdout, t.shipper.writer()), io.MultiWriter(stderr, t.shipper.writer())
+	}
+	return stdout, stderr, flush
+}
+
+// runChild runs the launcher until it exits.
+func (t *Launcher) runChild(launcher string, commit string) error {
+	cmd := exec.Command(launcher, t.args.rest...)
+	cmd.Env = childEnviron()
+	if t.networkDir != "" {
+		cmd.Env = append(cmd.Env, "NETWORK_DIR="+t.networkDir)
+	}
+	cmd.Env = append(cmd.Env, t.childEnv...)
+	cmd.Stdin = os.Stdin
+	var flush func()
+	cmd.Stdout, cmd.Stderr, flush = t.childOutput()
+	pgrp := setProcessGroup(cmd)
+	if t.config.User.Name != "" {
+		if err := t.runAsUser(cmd); err != nil {
+			return err
+		}
+	}
+	if t.config.Sandbox.Enabled {
+		if err := t.sandbox(cmd); err != nil {
+			return fmt.Errorf("sandbox: %w", err)
+		}
+	}
+	end := t.trace.span("exec")
+	err := cmd.Start()
+	end(err)
+	t.finishTrace(err, commit)
+	if err != nil {
+		return err
+	}
+	if err := attachJob(cmd.Process.Pid); err != nil {
+		t.logger.Warnf("Failed to tie the launcher to opendex-launcher: %s", err)
+	}
+	if err := t.applyResources(cmd.Process.Pid); err != nil {
+		t.logger.Warnf("Failed to apply resource limits: %s", err)
+	}
+	if os.Getenv(detachedEnv) != "" {
+		t.recordDetachedChild(cmd.Process.Pid)
+		defer t.removeDetachedChild()
+	}
+
+	c := &child{cmd: cmd, launcher: launcher, commit: commit, version: manifestVersion(launcher), startedAt: time.Now(), done: make(chan struct{})}
+	t.mu.Lock()
+	t.child = c
+	if t.baseContext().Err() != nil {
+		// stopWhenDone ran before the launcher was started
+		t.stopRequested = true
+		if err := t.signalChild(syscall.SIGTERM); err != nil {
+			t.logger.Warnf("Failed to stop the launcher: %s", err)
+		}
+	}
+	t.mu.Unlock()
+	t.emit(Event{Type: EventChildStarted, Commit: commit, Message: fmt.Sprintf("pid %d", cmd.Process.Pid)})
+	t.auditExecuted(launcher, commit)
+	go t.notifyReady(c)
+
+	err = cmd.Wait()
+	flush()
+	close(c.done)
+	restoreForeground(pgrp)
+
+	t.mu.Lock()
+	t.child = nil
+	t.mu.Unlock()
+
+	exited := Event{Type: EventChildExited, Commit: commit}
+	if err != nil {
+		exited.Message = err.Error()
+	}
+	t.emit(exited)
+
+	return err
+}
+
+func (t *Launcher) status() ChildStatus {
+	downloads := t.recentTransfers()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := ChildStatus{Network: t.network, Branch: t.branch, Downloads: downloads}
+	if t.child != nil {
+		s.Commit = t.child.commit
+		s.Version = t.child.version
+		s.Launcher = t.child.launcher
+		s.Running = true
+		s.Pid = t.child.cmd.Process.Pid
+		s.StartedAt = t.child.startedAt
+	}
+	return s
+}
+
+// signalChild sends sig to the running launcher and every process it
+// started. When the launcher has not exited after the grace period, the whole
+// group is killed. On Windows the group gets Ctrl-Break instead of sig.
+func (t *Launcher) signalChild(sig os.Signal) error {
+	if t.child == nil {
+		return fmt.Errorf("launcher is not running")
+	}
+	c := t.child
+	if err := signalGroup(c.cmd.Process.Pid, sig); err != nil {
+		return err
+	}
+	go t.escalate(c, time.Duration(t.config.Shutdown.GracePeriod)*time.Second)
+	return nil
+}
+
+// escalate kills the process group of c unless it exits within grace.
+func (t *Launcher) escalate(c *child, grace time.Duration) {
+	select {
+	case <-c.done:
+		return
+	case <-time.After(grace):
+	}
+	t.logger.Warnf("Launcher did not exit within %s, killing it", grace)
+	if err := signalGroup(c.cmd.Process.Pid, os.Kill); err != nil {
+		t.logger.Warnf("Failed to kill the launcher: %s", err)
+	}
+}
+
+// handleSignals forwards shutdown signals to the process group of the
+// launcher instead of leaving its process tree behind. Without a running
+// launcher they cancel the context of the start, which aborts resolving and
+// downloading and returns through the usual cleanup. The returned function
+// stops handling signals.
+func (t *Launcher) handleSignals() func() {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, shutdownSignals...)
+	go func() {
+		for {
+			var sig os.Signal
+			select {
+			case sig = <-ch:
+			case <-done:
+				return
+			}
+			t.mu.Lock()
+			if t.child == nil {
+				t.stopRequested = true
+				t.restart = nil
+				t.mu.Unlock()
+				t.logger.Debugf("Received %s, aborting the start", sig)
+				if t.cancel != nil {
+					t.cancel()
+				}
+				continue
+			}
+			t.logger.Debugf("Received %s, stopping the launcher", sig)
+			_ = sdNotify("STOPPING=1")
+			t.stopRequested = true
+			t.restart = nil
+			if err := t.signalChild(sig); err != nil {
+				t.logger.Warnf("Failed to stop the launcher: %s", err)
+			}
+			t.mu.Unlock()
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// stopWhenDone stops the running launcher without restarting it once the
+// context of the launcher is cancelled.
+func (t *Launcher) stopWhenDone() {
+	<-t.ctx.Done()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopRequested = true
+	t.restart = nil
+	if t.child == nil {
+		return
+	}
+	t.logger.Debugf("Context cancelled, stopping the launcher")
+	if err := t.signalChild(syscall.SIGTERM); err != nil {
+		t.logger.Warnf("Failed to stop the launcher: %s", err)
+	}
+}
+
+// stopChild stops the running launcher without restarting it.
+func (t *Launcher) stopChild() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopRequested = true
+	t.restart = nil
+	return t.signalChild(syscall.SIGTERM)
+}
+
+// restartChild stops the running launcher and starts launcher instead. An
+// empty launcher restarts the current version.
+func (t *Launcher) restartChild(launcher string, commit string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.child == nil {
+		return fmt.Errorf("launcher is not running")
+	}
+	if launcher == "" {
+		launcher = t.child.launcher
+		commit = t.child.commit
+	}
+	t.stopRequested = true
+	t.restart = &restartRequest{launcher: launcher, commit: commit}
+	return t.signalChild(syscall.SIGTERM)
+}
+
+// takeRestart returns and clears a pending restart request.
+func (t *Launcher) takeRestart() (*restartRequest, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := t.restart
+	t.restart = nil
+	return r, r != nil
+}
+
+// takeStopRequested reports and clears whether the last exit was requested.
+func (t *Launcher) takeStopRequested() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	requested := t.stopRequested
+	t.stopRequested = false
+	return requested
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// activeVersion returns the most recently launched installed version.
+// Versions installed with install but never launched only count when no
+// version was launched yet.
+func (t *Launcher) activeVersion() (string, error) {
+	versions, err := t.installedVersions()
+	if err != nil {
+		return "", err
+	}
+	var active, launched InstalledVersion
+	var launchedAt time.Time
+	for _, v := range versions {
+		if active.Commit == "" || lastUsed(v).After(lastUsed(active)) {
+			active = v
+		}
+		if m, err := readMetadata(filepath.Dir(v.Path)); err == nil && m.LastUsedAt.After(launchedAt) {
+			launched, launchedAt = v, m.LastUsedAt
+		}
+	}
+	if launched.Commit != "" {
+		return launched.Commit, nil
+	}
+	return active.Commit, nil
+}
+
+// partialAge is how long a partial file has not been written to before
+// clean removes it. Younger ones may belong to a download in progress or be
+// resumed by the next one.
+const partialAge = 24 * time.Hour
+
+// stalePartial tells whether the file of info is a partial file not written
+// to within partialAge before now.
+func stalePartial(info os.FileInfo, now time.Time) bool {
+	if info.IsDir() || !(strings.HasSuffix(info.Name(), ".part") || strings.HasSuffix(info.Name(), ".link")) {
+		return false
+	}
+	return now.Sub(info.ModTime()) > partialAge
+}
+
+// recentVersionAge is how long a version installed or launched is kept by
+// clean, another start may be about to run it.
+const recentVersionAge = time.Hour
+
+// keepReason returns why the version v must not be removed although it is
+// not the active one, empty when it may. inUse are the versions running
+// networks use.
+func (t *Launcher) keepReason(v InstalledVersion, inUse map[string]bool, now time.Time) string {
+	if reason := t.protectReason(v, inUse); reason != "" {
+		return reason
+	}
+	if now.Sub(lastUsed(v)) < recentVersionAge {
+		return "it was installed or launched within the last hour"
+	}
+	return ""
+}
+
+type cleanItem struct {
+	path   string
+	reason string
+	size   int64
+}
+
+// cleanPlan lists what clean removes: stale partial downloads, stale restore
+// directories, downloaded archives and every version but the active one and
+// those keepReason keeps.
+// The config and the network data are never part of it.
+func (t *Launcher) cleanPlan(active string) ([]cleanItem, error) {
+	var items []cleanItem
+	add := func(path string, reason string) error {
+		size, err := diskUsage(path)
+		if err != nil {
+			return err
+		}
+		items = append(items, cleanItem{path: path, reason: reason, size: size})
+		return nil
+	}
+
+	versions, err := t.installedVersions()
+	if err != nil {
+		return nil, err
+	}
+	if t.readOnlyVersions {
+		versions = nil
+	}
+	inUse := t.versionsInUse()
+	now := time.Now()
+	for _, v := range versions {
+		dir := filepath.Dir(v.Path)
+		if v.Commit != active {
+			if reason := t.keepReason(v, inUse, now); reason != "" {
+				t.logger.Debugf("Keeping version %s, %s", shortCommit(v.Commit), reason)
+				continue
+			}
+			if err := add(dir, "unused version"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, "launcher.zip")); err == nil {
+			if err := add(filepath.Join(dir, "launcher.zip"), "downloaded archive"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(t.github.Cache.dir, "sha256")); err == nil {
+		if err := add(filepath.Join(t.github.Cache.dir, "sha256"), "downloaded archives"); err != nil {
+			return nil, err
+		}
+	}
+
+	err = filepath.Walk(t.launcherDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if stalePartial(info, now) {
+			return add(path, "partial file")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stale, err := filepath.Glob(filepath.Join(t.homeDir, ".restore-*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range stale {
+		if err := add(dir, "stale restore directory"); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func (t *Launcher) runClean(args []string) error {
+	fs := flag.NewFlagSet("clean", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only show what would be removed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	active, err := t.activeVersion()
+	if err != nil {
+		return err
+	}
+	items, err := t.cleanPlan(active)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, item := range items {
+		fmt.Printf("%s: %s (%.1f MB)\n", item.reason, item.path, float64(item.size)/1024/1024)
+		total += item.size
+		if *dryRun {
+			continue
+		}
+		if err := os.RemoveAll(item.path); err != nil {
+			return fmt.Errorf("remove %s: %w", item.path, err)
+		}
+		t.audit(AuditRecord{Action: AuditPurged, Detail: item.reason + ": " + item.path})
+	}
+	if !*dryRun {
+		if err := os.Remove(t.github.Cache.indexPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := t.github.Cache.pruneBinaries(t.launcherVersionsDir); err != nil {
+			return err
+		}
+	}
+
+	if active != "" {
+		fmt.Printf("Kept active version %s\n", shortCommit(active))
+	}
+	verb := "Reclaimed"
+	if *dryRun {
+		verb = "Would reclaim"
+	}
+	fmt.Printf("%s %.1f MB\n", verb, float64(total)/1024/1024)
+	return nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStalePartial(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stat := func(name string) os.FileInfo {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+
+	part := stat("launcher.zip.part")
+	now := part.ModTime()
+	// a download in progress or to resume
+	assert.Equal(t, stalePartial(part, now.Add(time.Hour)), false)
+	assert.Equal(t, stalePartial(part, now.Add(partialAge+time.Hour)), true)
+	assert.Equal(t, stalePartial(stat("launcher.zip"), now.Add(partialAge+time.Hour)), false)
+}
+
+func TestKeepReason(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	l := &Launcher{launcherDir: dir, launcherVersionsDir: filepath.Join(dir, "versions"), config: DefaultConfig(), logger: logrus.NewEntry(logrus.New())}
+	old := time.Now().Add(-48 * time.Hour)
+	version := func(commit string, m *VersionMetadata) InstalledVersion {
+		versionDir := filepath.Join(l.launcherVersionsDir, commit)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeMetadata(versionDir, m); err != nil {
+			t.Fatal(err)
+		}
+		return InstalledVersion{Commit: commit, Path: l.launcherPath(commit), InstalledAt: old}
+	}
+	unused := version("1111111", &VersionMetadata{Step: StepExtracted, InstalledAt: old, LastUsedAt: old})
+	running := version("2222222", &VersionMetadata{Step: StepExtracted, InstalledAt: old, LastUsedAt: old})
+	installing := version("3333333", &VersionMetadata{Step: StepVerified})
+	recent := version("4444444", &VersionMetadata{Step: StepExtracted, InstalledAt: time.Now()})
+
+	// another network runs 2222222
+	data := []byte(`{"pid": ` + strconv.Itoa(os.Getpid()) + `, "commit": "2222222"}`)
+	if err := ioutil.WriteFile(filepath.Join(dir, "testnet.lock"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	inUse := l.versionsInUse()
+	now := time.Now()
+	assert.Equal(t, l.keepReason(unused, inUse, now), "")
+	assert.Equal(t, l.keepReason(running, inUse, now), "a running network uses it")
+	assert.Equal(t, l.keepReason(installing, inUse, now), "it is being installed")
+	assert.Equal(t, l.keepReason(recent, inUse, now), "it was installed or launched within the last hour")
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// command is handled by opendex-launcher itself instead of being passed to the
+// downloaded launcher. A command is matched by its full path so that child
+// launcher commands sharing the first word keep working.
+type command struct {
+	path  []string
+	usage string
+	run   func(t *Launcher, args []string) error
+	// exact commands only match without further arguments, which go to the
+	// launcher otherwise.
+	exact bool
+}
+
+// errNotLocal is returned by a command that leaves its arguments to the
+// launcher after all, e.g. stop when nothing runs in the background.
+var errNotLocal = errors.New("not a command of opendex-launcher")
+
+var commands = []*command{
+	{
+		path:  []string{"clean"},
+		usage: "Remove downloads and unused versions, keeping the active version, config and network data",
+		run:   (*Launcher).runClean,
+	},
+	{
+		path:  []string{"config", "docs"},
+		usage: "List all supported config keys",
+		run:   (*Launcher).runConfigDocs,
+	},
+	{
+		path:  []string{"config", "encrypt"},
+		usage: "Encrypt a secret config value read from stdin",
+		run:   (*Launcher).runConfigEncrypt,
+	},
+	{
+		path:  []string{"bundle", "import"},
+		usage: "Install a launcher archive distributed outside of GitHub",
+		run:   (*Launcher).runBundleImport,
+	},
+	{
+		path:  []string{"backup", "create"},
+		usage: "Archive the wallets and settings of the network",
+		run:   (*Launcher).runBackupCreate,
+	},
+	{
+		path:  []string{"backup", "restore"},
+		usage: "Restore the network from a backup archive",
+		run:   (*Launcher).runBackupRestore,
+	},
+	{
+		path:  []string{"env"},
+		usage: "Show the effective settings and where they came from",
+		run:   (*Launcher).runEnv,
+	},
+	{
+		path:  []string{"install"},
+		usage: "Download and verify a release or commit without running it",
+		run:   (*Launcher).runInstall,
+	},
+	{
+		path:  []string{"pull"},
+		usage: "Resolve, download and verify the launcher of the branch without running it",
+		run:   (*Launcher).runPull,
+	},
+	{
+		path:  []string{"info"},
+		usage: "Show the details of an installed version",
+		run:   (*Launcher).runInfo,
+	},
+	{
+		path:  []string{"restart"},
+		usage: "Restart the launcher running in the background with the head of the branch, the launcher handles it when there is none",
+		run:   (*Launcher).runRestart,
+		exact: true,
+	},
+	{
+		path:  []string{"sbom"},
+		usage: "Show the software bill of materials of a release",
+		run:   (*Launcher).runSBOM,
+	},
+	{
+		path:  []string{"user", "setup"},
+		usage: "Create a dedicated system user for the launcher and hand the data over to it",
+		run:   (*Launcher).runUserSetup,
+	},
+	{
+		path:  []string{"service", "install"},
+		usage: "Start the launcher at login or boot",
+		run:   (*Launcher).runServiceInstall,
+	},
+	{
+		path:  []string{"service", "uninstall"},
+		usage: "Stop starting the launcher at login or boot",
+		run:   (*Launcher).runServiceUninstall,
+	},
+	{
+		path:  []string{"start", "--detach"},
+		usage: "Run the launcher in the background, logging to a file",
+		run:   (*Launcher).runStartDetach,
+	},
+	{
+		path:  []string{"stop"},
+		usage: "Stop the launcher running in the background, the launcher handles it when there is none",
+		run:   (*Launcher).runStop,
+		exact: true,
+	},
+	{
+		path:  []string{"update", "--check"},
+		usage: "Check for a new launcher build without installing it",
+		run:   (*Launcher).runUpdateCheck,
+	},
+	{
+		path:  []string{"verify"},
+		usage: "Check the installed versions against their recorded hashes",
+		run:   (*Launcher).runVerify,
+	},
+}
+
+// runCommand runs the command of opendex-launcher args start with and
+// reports whether there was one. Otherwise args go to the launcher.
+func (t *Launcher) runCommand(args []string) (bool, error) {
+	cmd, cmdArgs := findCommand(args)
+	if cmd == nil {
+		return false, nil
+	}
+	err := cmd.run(t, cmdArgs)
+	if errors.Is(err, errNotLocal) {
+		return false, nil
+	}
+	return true, err
+}
+
+// findCommand returns the command matching the beginning of args and the
+// remaining arguments, or nil when args should go to the child launcher.
+func findCommand(args []string) (*command, []string) {
+	var found *command
+	for _, cmd := range commands {
+		if len(args) < len(cmd.path) {
+			continue
+		}
+		matched := true
+		for i, word := range cmd.path {
+			if args[i] != word {
+				matched = false
+				break
+			}
+		}
+		if matched && cmd.exact && len(args) > len(cmd.path) {
+			continue
+		}
+		if matched && (found == nil || len(cmd.path) > len(found.path)) {
+			found = cmd
+		}
+	}
+	if found == nil {
+		return nil, args
+	}
+	return found, args[len(found.path):]
+}
+
+// ExitCodeError makes opendex-launcher exit with Code.
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
+func (t *Launcher) runConfigDocs(args []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tDEFAULT\tDESCRIPTION")
+	for _, key := range ConfigKeys() {
+		value := key.Default
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.Key, key.Type, value, key.Description)
+	}
+	fmt.Fprintf(w, "\nConfig file: %s\n", t.configFile)
+	return w.Flush()
+}
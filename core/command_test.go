@@ -0,0 +1,43 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFindCommand(t *testing.T) {
+	cmd, args := findCommand([]string{"backup", "create", "--output", "x.tar.gz"})
+	assert.Equal(t, cmd.path, []string{"backup", "create"})
+	assert.Equal(t, args, []string{"--output", "x.tar.gz"})
+
+	cmd, _ = findCommand([]string{"update"})
+	assert.Equal(t, cmd == nil, true)
+}
+
+func TestFindCommandExact(t *testing.T) {
+	cmd, _ := findCommand([]string{"stop"})
+	assert.Equal(t, cmd.path, []string{"stop"})
+
+	// stopping a single service is up to the launcher
+	cmd, _ = findCommand([]string{"stop", "lndbtc"})
+	assert.Equal(t, cmd == nil, true)
+}
+
+func TestStopWithoutDetached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "launcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	l := &Launcher{launcherDir: dir, network: "testnet", config: DefaultConfig(), logger: logrus.NewEntry(logrus.New())}
+
+	// nothing runs in the background, the launcher stops or restarts itself
+	for _, args := range [][]string{{"stop"}, {"restart"}} {
+		ok, err := l.runCommand(args)
+		assert.Equal(t, ok, false, args[0])
+		assert.Equal(t, err, nil, args[0])
+	}
+}
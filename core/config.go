@@ -1,21 +1,89 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"github.com/pelletier/go-toml"
 	"io"
 	"io/ioutil"
+	"reflect"
+	"strings"
 )
 
+var ErrConfigType = errors.New("config key has another type")
+
 type GitHub struct {
-	AccessToken string `toml:"access-token"`
+	AccessToken     string   `toml:"access-token" secret:"true" comment:"GitHub personal access token used to download launcher artifacts"`
+	AccessTokens    []string `toml:"access-tokens" secret:"true" comment:"Additional access tokens, rotated when a token hits its rate limit"`
+	GraphQL         bool     `toml:"graphql" default:"true" comment:"Resolve branches with a single GraphQL query when an access token is configured"`
+	RetryBudget     int      `toml:"retry-budget" default:"60" comment:"Seconds to wait in total for GitHub to lift secondary rate limits before failing"`
+	CheckAdvisories bool     `toml:"check-advisories" default:"true" comment:"Warn at startup when the release to run is affected by a published security advisory"`
+	ValidateToken   bool     `toml:"validate-token" default:"true" comment:"Check that the access tokens are valid and have the required scopes at startup"`
+	MaxClockSkew    int      `toml:"max-clock-skew" default:"300" comment:"Seconds the system clock may differ from the time of GitHub before warning, 0 to never warn"`
+}
+
+// Tokens returns all configured access tokens.
+func (t GitHub) Tokens() []string {
+	return append([]string{t.AccessToken}, t.AccessTokens...)
 }
 
+// Config is the content of opendex-docker.conf. A Config returned by
+// Launcher.Config is a copy which may be read freely; use the typed getters
+// to read a key by its name in the config file.
 type Config struct {
+	ConfigVersion int    `toml:"config-version" comment:"Version of the config file layout, maintained by opendex-launcher"`
+	StrictMode    string `toml:"strict-mode" default:"warn" comment:"How to treat unknown keys of the config file: off, warn or error"`
+
 	GitHub     GitHub
-	SimnetDir  string `toml:"simnet-dir"`
-	TestnetDir string `toml:"testnet-dir"`
-	MainnetDir string `toml:"mainnet-dir"`
+	SimnetDir  string  `toml:"simnet-dir" comment:"Data directory of the simnet network, simnet in the home directory when empty"`
+	TestnetDir string  `toml:"testnet-dir" comment:"Data directory of the testnet network, testnet in the home directory when empty"`
+	MainnetDir string  `toml:"mainnet-dir" comment:"Data directory of the mainnet network, mainnet in the home directory when empty"`
+	Hooks      Hooks   `toml:"hooks"`
+	Webhook    Webhook `toml:"webhook"`
+
+	Notifications Notifications `toml:"notifications"`
+	API           API           `toml:"api"`
+	IPC           IPC           `toml:"ipc"`
+	Docker        Docker        `toml:"docker"`
+	Backup        Backup        `toml:"backup"`
+	Cosign        Cosign        `toml:"cosign"`
+	Cache         Cache         `toml:"cache"`
+	Artifacts     Artifacts     `toml:"artifacts"`
+	Monitor       Monitor       `toml:"monitor"`
+	Resources     Resources     `toml:"resources"`
+	Sandbox       Sandbox       `toml:"sandbox"`
+	User          User          `toml:"user"`
+	Shutdown      Shutdown      `toml:"shutdown"`
+	Output        Output        `toml:"output"`
+	LogSinks      LogSinks      `toml:"log-sinks"`
+	Metrics       Metrics       `toml:"metrics"`
+	Tracing       Tracing       `toml:"tracing"`
+	HTTP          HTTP          `toml:"http"`
+	Proxy         Proxy         `toml:"proxy"`
+	Enterprise    Enterprise    `toml:"enterprise"`
+	Simnet        Simnet        `toml:"simnet"`
+	Secrets       Secrets       `toml:"secrets"`
+
+	Profiles map[string]Profile `toml:"profile" comment:"Launch profiles selected with --profile, each in a [profile.<name>] section"`
+}
+
+type Output struct {
+	Prefix     bool `toml:"prefix" comment:"Prefix every line of launcher output with the network and a UTC timestamp, unless it goes to a terminal"`
+	RenderJSON bool `toml:"render-json" default:"true" comment:"Render JSON log lines of the launcher as colored text when the output goes to a terminal"`
+}
+
+type Shutdown struct {
+	GracePeriod int `toml:"grace-period" default:"30" comment:"Seconds the launcher gets to exit after SIGTERM before its process group is killed"`
+}
+
+// ConfigKey describes a single supported configuration key. It is generated
+// from the struct tags of Config so the documentation never drifts from the
+// code.
+type ConfigKey struct {
+	Key         string
+	Type        string
+	Default     string
+	Description string
 }
 
 func parseConfig(reader io.Reader) (*Config, error) {
@@ -30,3 +98,285 @@ func parseConfig(reader io.Reader) (*Config, error) {
 	}
 	return &config, nil
 }
+
+// DefaultConfig returns a Config with every default value applied. The
+// defaults are documented by ConfigKeys.
+func DefaultConfig() *Config {
+	config, err := parseConfig(strings.NewReader(""))
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func configKeyName(field reflect.StructField) string {
+	name := field.Tag.Get("toml")
+	if i := strings.Index(name, ","); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name
+}
+
+func configTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Slice, reflect.Array:
+		return "array of " + configTypeName(t.Elem())
+	case reflect.Map:
+		return "table of " + configTypeName(t.Elem())
+	default:
+		return t.Kind().String()
+	}
+}
+
+func collectConfigKeys(t reflect.Type, prefix string, keys []ConfigKey) []ConfigKey {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("toml") == "-" {
+			continue
+		}
+		name := prefix + configKeyName(field)
+		if field.Type.Kind() == reflect.Struct {
+			keys = collectConfigKeys(field.Type, name+".", keys)
+			continue
+		}
+		keys = append(keys, ConfigKey{
+			Key:         name,
+			Type:        configTypeName(field.Type),
+			Default:     field.Tag.Get("default"),
+			Description: field.Tag.Get("comment"),
+		})
+	}
+	return keys
+}
+
+// ConfigKeys lists every key supported in the config file.
+func ConfigKeys() []ConfigKey {
+	return collectConfigKeys(reflect.TypeOf(Config{}), "", nil)
+}
+
+func configValueLiteral(key ConfigKey) string {
+	switch {
+	case key.Type == "string":
+		return fmt.Sprintf("%q", key.Default)
+	case strings.HasPrefix(key.Type, "array"):
+		return "[]"
+	case strings.HasPrefix(key.Type, "table"):
+		return "{}"
+	case key.Default == "" && key.Type == "boolean":
+		return "false"
+	case key.Default == "":
+		return "0"
+	default:
+		return key.Default
+	}
+}
+
+// defaultConfigTemplate renders every supported key with its default value
+// commented out, top-level keys first so that uncommenting a key never moves
+// it into a table.
+func defaultConfigTemplate() string {
+	var b strings.Builder
+	b.WriteString("# opendex-docker.conf\n")
+	b.WriteString("#\n")
+	b.WriteString("# Generated by opendex-launcher. Every key is commented out and set to its\n")
+	b.WriteString("# default value, uncomment a key to change it.\n")
+
+	var sections []string
+	keys := make(map[string][]ConfigKey)
+	for _, key := range ConfigKeys() {
+		section := ""
+		if i := strings.LastIndex(key.Key, "."); i >= 0 {
+			section = key.Key[:i]
+		}
+		if _, ok := keys[section]; !ok && section != "" {
+			sections = append(sections, section)
+		}
+		keys[section] = append(keys[section], key)
+	}
+
+	write := func(key ConfigKey) {
+		name := key.Key[strings.LastIndex(key.Key, ".")+1:]
+		b.WriteString("\n")
+		if key.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", key.Description)
+		}
+		if key.Key == "config-version" {
+			fmt.Fprintf(&b, "%s = %d\n", name, CurrentConfigVersion)
+			return
+		}
+		fmt.Fprintf(&b, "#%s = %s\n", name, configValueLiteral(key))
+	}
+
+	for _, key := range keys[""] {
+		write(key)
+	}
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n[%s]\n", section)
+		for _, key := range keys[section] {
+			write(key)
+		}
+	}
+	return b.String()
+}
+
+// cloneValue returns a deep copy of v. Unexported fields are left zero.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return c
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	default:
+		return v
+	}
+}
+
+// Clone returns a deep copy of c.
+func (c *Config) Clone() *Config {
+	clone := cloneValue(reflect.ValueOf(c).Elem()).Interface().(Config)
+	return &clone
+}
+
+// lookup returns the value of key, e.g. "cache.max-size", matched case
+// insensitively like the config file. Entries of tables such as profiles are
+// addressed by their name, e.g. "profile.testnet.branch".
+func (c *Config) lookup(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(c).Elem()
+	for _, part := range strings.Split(key, ".") {
+		switch v.Kind() {
+		case reflect.Struct:
+			found := false
+			for i := 0; i < v.NumField(); i++ {
+				field := v.Type().Field(i)
+				if field.PkgPath == "" && strings.EqualFold(configKeyName(field), part) {
+					v, found = v.Field(i), true
+					break
+				}
+			}
+			if !found {
+				return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownConfigKey, key)
+			}
+		case reflect.Map:
+			entry := v.MapIndex(reflect.ValueOf(part))
+			if !entry.IsValid() {
+				return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownConfigKey, key)
+			}
+			v = entry
+		default:
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownConfigKey, key)
+		}
+	}
+	return v, nil
+}
+
+func (c *Config) lookupKind(key string, kind reflect.Kind) (reflect.Value, error) {
+	v, err := c.lookup(key)
+	if err != nil {
+		return v, err
+	}
+	if v.Kind() != kind {
+		return v, fmt.Errorf("%w: %s is %s, not %s", ErrConfigType, key, configTypeName(v.Type()), kind)
+	}
+	return v, nil
+}
+
+// GetString returns the string value of key.
+func (c *Config) GetString(key string) (string, error) {
+	v, err := c.lookupKind(key, reflect.String)
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+// GetBool returns the boolean value of key.
+func (c *Config) GetBool(key string) (bool, error) {
+	v, err := c.lookupKind(key, reflect.Bool)
+	if err != nil {
+		return false, err
+	}
+	return v.Bool(), nil
+}
+
+// GetInt returns the integer value of key.
+func (c *Config) GetInt(key string) (int, error) {
+	v, err := c.lookupKind(key, reflect.Int)
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int()), nil
+}
+
+// GetStrings returns the string array value of key.
+func (c *Config) GetStrings(key string) ([]string, error) {
+	v, err := c.lookupKind(key, reflect.Slice)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := v.Interface().([]string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is %s, not array of string", ErrConfigType, key, configTypeName(v.Type()))
+	}
+	return append([]string(nil), values...), nil
+}
+
+// Config returns a copy of the effective configuration, which is safe to
+// call while the launcher runs. It has the default values until the config
+// file was read.
+func (t *Launcher) Config() *Config {
+	t.configMu.RLock()
+	defer t.configMu.RUnlock()
+	if t.config == nil {
+		return DefaultConfig()
+	}
+	return t.config.Clone()
+}
+
+// setConfig replaces the effective configuration.
+func (t *Launcher) setConfig(c *Config) {
+	t.configMu.Lock()
+	defer t.configMu.Unlock()
+	t.config = c
+}
@@ -1,8 +1,10 @@
 package core
 
 import (
+	"errors"
 	"github.com/magiconair/properties/assert"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -18,3 +20,74 @@ access-token = "abc123"
 	assert.Equal(t, config.GitHub.AccessToken, "abc123", "should get access token abc123")
 	assert.Equal(t, config.SimnetDir, "")
 }
+
+func TestDefaultConfigTemplate(t *testing.T) {
+	template := defaultConfigTemplate()
+	config, err := parseConfig(strings.NewReader(template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DefaultConfig()
+	expected.ConfigVersion = CurrentConfigVersion
+	assert.Equal(t, config, expected)
+	assert.Matches(t, template, `(?m)^#access-token = ""$`)
+	assert.Matches(t, template, `(?m)^\[hooks\]$`)
+}
+
+func TestConfigGetters(t *testing.T) {
+	config, err := parseConfig(strings.NewReader(`
+[cache]
+max-size = 100
+
+[backup]
+exclude = ["*.tmp"]
+
+[profile.testnet]
+branch = "feat/foo"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := config.GetInt("Cache.max-size")
+	assert.Equal(t, size, 100)
+	assert.Equal(t, err, nil)
+	graphql, err := config.GetBool("github.graphql")
+	assert.Equal(t, graphql, true)
+	assert.Equal(t, err, nil)
+	exclude, err := config.GetStrings("backup.exclude")
+	assert.Equal(t, exclude, []string{"*.tmp"})
+	assert.Equal(t, err, nil)
+	branch, err := config.GetString("profile.testnet.branch")
+	assert.Equal(t, branch, "feat/foo")
+	assert.Equal(t, err, nil)
+
+	_, err = config.GetString("cache.max-size")
+	assert.Equal(t, errors.Is(err, ErrConfigType), true)
+	_, err = config.GetString("cache.maxsize")
+	assert.Equal(t, errors.Is(err, ErrUnknownConfigKey), true)
+	_, err = config.GetString("profile.mainnet.branch")
+	assert.Equal(t, errors.Is(err, ErrUnknownConfigKey), true)
+}
+
+func TestLauncherConfigIsACopy(t *testing.T) {
+	l := &Launcher{}
+	assert.Equal(t, l.Config(), DefaultConfig())
+
+	config := DefaultConfig()
+	config.Backup.Exclude = []string{"*.tmp"}
+	l.setConfig(config)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := l.Config()
+			c.Backup.Exclude[0] = "changed"
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, l.Config().Backup.Exclude, []string{"*.tmp"})
+}
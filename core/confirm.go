@@ -0,0 +1,79 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrDownloadDeclined = errors.New("download declined")
+
+// needsConfirmation tells whether downloading size bytes must be confirmed
+// with threshold MB being the largest download that needs not. Downloads of
+// unknown size are never asked for.
+func needsConfirmation(size int64, threshold int, yes bool) bool {
+	return !yes && threshold > 0 && size > int64(threshold)*1024*1024
+}
+
+// confirmDownload asks whether to download size bytes when the download is
+// larger than artifacts.confirm-size and opendex-launcher runs in a
+// terminal. --yes skips the question.
+func (t *Launcher) confirmDownload(size int64) bool {
+	if !needsConfirmation(size, t.config.Artifacts.ConfirmSize, t.args.yes) {
+		return true
+	}
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return true
+	}
+	question := fmt.Sprintf("Download %.1f MB?", float64(size)/1024/1024)
+	return askYesNo(os.Stdin, os.Stdout, question)
+}
+
+// estimateSize records the size GitHub lists for the workflow artifact at
+// url.
+func (t *GithubClient) estimateSize(url string, size int64) {
+	if size <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.estimates == nil {
+		t.estimates = make(map[string]int64)
+	}
+	t.estimates[url] = size
+}
+
+// downloadSize returns the size of the download at url as listed by GitHub,
+// -1 when it is unknown.
+func (t *GithubClient) downloadSize(url string) int64 {
+	if size := t.expectedSize(url); size >= 0 {
+		return size
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if size, ok := t.estimates[url]; ok {
+		return size
+	}
+	return -1
+}
+
+// confirmDownloads asks Confirm once for the combined size of urls, before
+// any of them is downloaded. Downloads of unknown size do not count.
+func (t *GithubClient) confirmDownloads(urls []string) error {
+	if t.Confirm == nil {
+		return nil
+	}
+	total := int64(-1)
+	for _, url := range urls {
+		if size := t.downloadSize(url); size >= 0 {
+			if total < 0 {
+				total = 0
+			}
+			total += size
+		}
+	}
+	if total >= 0 && !t.Confirm(total) {
+		return fmt.Errorf("%w: %d bytes", ErrDownloadDeclined, total)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNeedsConfirmation(t *testing.T) {
+	const mb = 1024 * 1024
+	assert.Equal(t, needsConfirmation(101*mb, 100, false), true)
+	assert.Equal(t, needsConfirmation(100*mb, 100, false), false)
+	assert.Equal(t, needsConfirmation(101*mb, 100, true), false)
+	assert.Equal(t, needsConfirmation(101*mb, 0, false), false)
+	assert.Equal(t, needsConfirmation(-1, 100, false), false)
+}
+
+func TestConfirmDownloads(t *testing.T) {
+	var asked []int64
+	c := NewGithubClient()
+	c.Confirm = func(size int64) bool {
+		asked = append(asked, size)
+		return false
+	}
+	c.expectSize("https://example.com/launcher.zip", 8)
+	c.estimateSize("https://example.com/extra.zip", 2)
+
+	err := c.confirmDownloads([]string{"https://example.com/launcher.zip", "https://example.com/extra.zip", "https://example.com/unknown.zip"})
+	assert.Equal(t, errors.Is(err, ErrDownloadDeclined), true)
+	assert.Equal(t, asked, []int64{10})
+
+	// nothing to ask about downloads of unknown size
+	assert.Equal(t, c.confirmDownloads([]string{"https://example.com/unknown.zip"}), nil)
+	assert.Equal(t, len(asked), 1)
+}
+
+func TestWriteBodyNeverAsks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "declined")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "launcher.zip")
+
+	c := NewGithubClient()
+	c.Confirm = func(size int64) bool {
+		t.Fatal("asked from writeBody")
+		return false
+	}
+	resp := &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("launcher")), ContentLength: -1}
+	n, _, err := c.writeBody(resp, file, nil, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, n, int64(8))
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	CosignOff     = "off"
+	CosignKey     = "key"
+	CosignKeyless = "keyless"
+)
+
+// Cosign configures the verification of release assets with cosign. Stable
+// releases (e.g. 21.02.10) and prereleases (e.g. 21.02.10-rc1) are
+// configured separately.
+type Cosign struct {
+	Stable     string `toml:"stable" default:"off" comment:"Verify stable release assets with cosign: off, key or keyless"`
+	Prerelease string `toml:"prerelease" default:"off" comment:"Verify prerelease assets with cosign: off, key or keyless"`
+
+	Binary string `toml:"binary" default:"cosign" comment:"cosign executable"`
+	Key    string `toml:"key" comment:"Public key file for key based verification"`
+
+	IdentityRegexp string `toml:"certificate-identity-regexp" default:"^https://github.com/opendexnetwork/opendex-docker/" comment:"Signer identity accepted for keyless verification"`
+	OidcIssuer     string `toml:"certificate-oidc-issuer" default:"https://token.actions.githubusercontent.com" comment:"OIDC issuer accepted for keyless verification"`
+}
+
+// releaseChannel returns "stable" or "prerelease" for release refs and an
+// empty string for branches.
+func releaseChannel(ref string) string {
+	if !ReleaseRef.MatchString(ref) {
+		return ""
+	}
+	if strings.Contains(ref, "-") {
+		return "prerelease"
+	}
+	return "stable"
+}
+
+func (c Cosign) mode(ref string) string {
+	switch releaseChannel(ref) {
+	case "stable":
+		return c.Stable
+	case "prerelease":
+		return c.Prerelease
+	default:
+		return CosignOff
+	}
+}
+
+// verifyBlobArgs returns the cosign arguments verifying file with the bundle
+// holding its signature, certificate and transparency log entry.
+func (c Cosign) verifyBlobArgs(mode string, file string, bundle string) ([]string, error) {
+	args := []string{"verify-blob", "--bundle", bundle}
+	switch mode {
+	case CosignKey:
+		if c.Key == "" {
+			return nil, errors.New("cosign key is not configured")
+		}
+		args = append(args, "--key", c.Key)
+	case CosignKeyless:
+		args = append(args, "--certificate-identity-regexp", c.IdentityRegexp, "--certificate-oidc-issuer", c.OidcIssuer)
+	default:
+		return nil, fmt.Errorf("unknown cosign mode: %s", mode)
+	}
+	return append(args, file), nil
+}
+
+// verifyArtifact checks the cosign signature and transparency log inclusion
+// of the downloaded release asset file before it is extracted. It returns
+// the cosign mode the file was verified with, or "" when it was not. With a
+// policy listing keys, release assets signed by one of them are verified
+// with it, and the mode is followed by the fingerprint of the key.
+func (t *Launcher) verifyArtifact(ref string, file string) (string, error) {
+	mode := t.config.Cosign.mode(ref)
+	off := mode == CosignOff || mode == ""
+	policy, err := t.policy()
+	if err != nil {
+		return "", err
+	}
+	policyKeys := policy != nil && len(policy.Keys) > 0 && releaseChannel(ref) != ""
+	if off && !policyKeys {
+		return "", nil
+	}
+
+	name := releaseAsset(t.github.selectedArch()) + ".bundle"
+	data, err := t.github.GetReleaseAsset(ref, name)
+	if err != nil && off {
+		// the policy may still approve the hash
+		t.logger.Debugf("No signature bundle for the policy keys: %s", err)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get signature bundle: %w", err)
+	}
+	bundle := filepath.Join(filepath.Dir(file), name)
+	if err := ioutil.WriteFile(bundle, data, 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(bundle)
+
+	if policyKeys {
+		if signature := t.verifyPolicyKeys(policy, file, bundle); signature != "" {
+			t.logger.Infof("Verified cosign signature of %s with a policy key", ref)
+			return signature, nil
+		}
+	}
+	if off {
+		return "", nil
+	}
+
+	args, err := t.config.Cosign.verifyBlobArgs(mode, file, bundle)
+	if err != nil {
+		return "", err
+	}
+	if output, err := t.runCosign(args); err != nil {
+		return "", fmt.Errorf("cosign verify-blob: %w: %s", err, output)
+	}
+	t.logger.Infof("Verified cosign signature of %s (%s)", ref, mode)
+	return mode, nil
+}
+
+// runCosign runs cosign with args and returns its trimmed output.
+func (t *Launcher) runCosign(args []string) (string, error) {
+	output, err := exec.CommandContext(t.github.context(), t.config.Cosign.Binary, args...).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
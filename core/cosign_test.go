@@ -0,0 +1,31 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestReleaseChannel(t *testing.T) {
+	assert.Equal(t, releaseChannel("21.02.10"), "stable")
+	assert.Equal(t, releaseChannel("21.02.10-rc1"), "prerelease")
+	assert.Equal(t, releaseChannel("master"), "")
+}
+
+func TestVerifyBlobArgs(t *testing.T) {
+	c := DefaultConfig().Cosign
+	assert.Equal(t, c.mode("21.02.10"), CosignOff)
+
+	args, err := c.verifyBlobArgs(CosignKeyless, "launcher.zip", "launcher.zip.bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, args, []string{
+		"verify-blob", "--bundle", "launcher.zip.bundle",
+		"--certificate-identity-regexp", "^https://github.com/opendexnetwork/opendex-docker/",
+		"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+		"launcher.zip",
+	})
+
+	_, err = c.verifyBlobArgs(CosignKey, "launcher.zip", "launcher.zip.bundle")
+	assert.Equal(t, err != nil, true)
+}
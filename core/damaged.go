@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// damagedArchiveError is a downloaded archive which failed verification or
+// extraction. Downloading it again may fix it.
+type damagedArchiveError struct {
+	err error
+}
+
+func (e *damagedArchiveError) Error() string {
+	return e.err.Error()
+}
+
+func (e *damagedArchiveError) Unwrap() error {
+	return e.err
+}
+
+// damaged marks err as caused by a damaged archive. Exceeded archive limits
+// are not, a new download would not be any smaller.
+func damaged(err error) error {
+	if err == nil || errors.Is(err, ErrArchiveLimit) {
+		return err
+	}
+	return &damagedArchiveError{err: err}
+}
+
+func isDamaged(err error) bool {
+	var d *damagedArchiveError
+	return errors.As(err, &d)
+}
+
+var ErrSizeMismatch = errors.New("download size mismatch")
+
+// sizeMismatch is a download of n bytes where expected were listed. It is
+// damaged, the next download may well be complete.
+func sizeMismatch(n int64, expected int64) error {
+	return damaged(fmt.Errorf("%w: %d bytes instead of %d", ErrSizeMismatch, n, expected))
+}
+
+// expectSize records the size GitHub lists for the asset at url.
+func (t *GithubClient) expectSize(url string, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sizes == nil {
+		t.sizes = make(map[string]int64)
+	}
+	t.sizes[url] = size
+}
+
+// expectedSize returns the size listed for the asset at url, -1 when none
+// was.
+func (t *GithubClient) expectedSize(url string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if size, ok := t.sizes[url]; ok {
+		return size
+	}
+	return -1
+}
+
+// removeDamaged removes the files of a damaged download of commit from
+// commitDir and the cache, so that neither this nor a later run mistakes
+// them for an installed launcher. The staged extra artifacts are kept.
+func (t *GithubClient) removeDamaged(commit string, commitDir string) error {
+	entries, err := ioutil.ReadDir(commitDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".extras" {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(commitDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	if t.Cache != nil {
+		return t.Cache.forget(commit)
+	}
+	return nil
+}
+
+// downloadAttempts returns how often a damaged launcher archive is
+// downloaded before giving up.
+func (t *GithubClient) downloadAttempts() int {
+	if t.Attempts < 1 {
+		return 1
+	}
+	return t.Attempts
+}
+
+// downloadRetrying downloads the launcher archive again when it turns out to
+// be damaged.
+func (t *GithubClient) downloadRetrying(url string, branch string, commit string, commitDir string) error {
+	attempts := t.downloadAttempts()
+	t.noDelta = false
+	for attempt := 1; ; attempt++ {
+		err := t.downloadLauncher(url, branch, commit, commitDir)
+		if err == nil && t.transfer != nil && t.Transferred != nil {
+			s := *t.transfer
+			s.Commit = commit
+			s.Retries = attempt - 1
+			t.Transferred(s)
+		}
+		if err == nil || !isDamaged(err) {
+			return err
+		}
+		if rmErr := t.removeDamaged(commit, commitDir); rmErr != nil {
+			t.Logger.Warnf("Failed to remove the damaged download of %s: %s", commit, rmErr)
+			return err
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("damaged archive after %d attempts: %w", attempts, err)
+		}
+		// a damaged patched archive is downloaded in whole again
+		t.noDelta = true
+		t.Logger.Warnf("Downloaded launcher of %s is damaged (%s), downloading it again (attempt %d of %d)", commit, err, attempt+1, attempts)
+	}
+}
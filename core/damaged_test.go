@@ -0,0 +1,114 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func launcherZip(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	entry, err := w.Create("launcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entry.Write([]byte("launcher")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testDownloadRetrying(t *testing.T, attempts int, bodies ...[]byte) (int, []string, error) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := bodies[len(bodies)-1]
+		if requests < len(bodies) {
+			body = bodies[requests]
+		}
+		requests++
+		w.Write(body)
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "damaged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewGithubClient()
+	c.Attempts = attempts
+	err = c.downloadRetrying(server.URL+"/launcher.zip", "master", "abc", dir)
+
+	entries, _ := ioutil.ReadDir(dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return requests, names, err
+}
+
+func TestDownloadRetrying(t *testing.T) {
+	requests, files, err := testDownloadRetrying(t, 3, []byte("corrupt"), launcherZip(t))
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, requests, 2)
+	assert.Equal(t, files, []string{"launcher", "launcher.zip", MetadataFilename})
+}
+
+func TestDownloadRetryingGivesUp(t *testing.T) {
+	requests, files, err := testDownloadRetrying(t, 2, []byte("corrupt"))
+
+	assert.Equal(t, isDamaged(err), true)
+	assert.Equal(t, requests, 2)
+	assert.Equal(t, len(files), 0)
+}
+
+func TestDamagedIgnoresLimits(t *testing.T) {
+	err := damaged(NewGithubClient().limitExceeded("archive too large"))
+
+	assert.Equal(t, isDamaged(err), false)
+	assert.Equal(t, isDamaged(damaged(filepath.ErrBadPattern)), true)
+}
+
+func TestWriteBodyExpectedSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "expected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "launcher.zip")
+	c := NewGithubClient()
+	response := func(body string, length int64) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body)), ContentLength: length}
+	}
+
+	n, hash, err := c.writeBody(response("launcher", -1), file, nil, 8)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, n, int64(8))
+	expected, _ := sha256File(file)
+	assert.Equal(t, hash, expected)
+
+	_, _, err = c.writeBody(response("launcher", 8), file, nil, 7)
+	assert.Equal(t, errors.Is(err, ErrSizeMismatch), true)
+	assert.Equal(t, isDamaged(err), true)
+
+	// the body is not read past the expected size
+	n, _, err = c.writeBody(response(strings.Repeat("x", 4096), -1), file, nil, 7)
+	assert.Equal(t, errors.Is(err, ErrSizeMismatch), true)
+	assert.Equal(t, n, int64(8))
+
+	_, _, err = c.writeBody(response("short", -1), file, nil, 7)
+	assert.Equal(t, errors.Is(err, ErrSizeMismatch), true)
+}
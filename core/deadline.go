@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// ExitTimeout is the exit code when the launcher could not be started within
+// --timeout, as used by timeout(1).
+const ExitTimeout = 124
+
+// contextTransport sends every request with the context ctx.
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+// withContext returns a copy of client sending every request with ctx.
+func withContext(client *http.Client, ctx context.Context) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := *client
+	c.Transport = &contextTransport{ctx: ctx, base: base}
+	return &c
+}
+
+// baseContext returns the context the launcher was started with.
+func (t *Launcher) baseContext() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// startDeadline bounds resolving, downloading and verifying the launcher.
+type startDeadline struct {
+	timeout time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	github  *GithubClient
+	client  *http.Client
+	// previous is the context of the GitHub client before the deadline.
+	previous context.Context
+}
+
+// startDeadline routes the requests of the GitHub client through the
+// --timeout deadline. Without --timeout it does nothing.
+func (t *Launcher) startDeadline() *startDeadline {
+	d := &startDeadline{timeout: t.args.timeout, github: t.github}
+	if d.timeout <= 0 {
+		return d
+	}
+	d.ctx, d.cancel = context.WithTimeout(t.baseContext(), d.timeout)
+	d.previous = t.github.Context
+	d.client = t.github.Client
+	t.github.Client = withContext(d.client, d.ctx)
+	t.github.Context = d.ctx
+	return d
+}
+
+// lift removes the deadline once the launcher is ready to run.
+func (d *startDeadline) lift() {
+	if d.ctx == nil || d.github.Context != d.ctx {
+		return
+	}
+	d.cancel()
+	d.github.Client = d.client
+	d.github.Context = d.previous
+}
+
+// check turns err into an ExitTimeout exit when the deadline expired.
+func (d *startDeadline) check(err error, logger *logrus.Entry) error {
+	if err == nil || d.ctx == nil || !errors.Is(d.ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	var exitErr *ExitCodeError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+	logger.Errorf("Launcher not started within %s: %s", d.timeout, err)
+	return &ExitCodeError{Code: ExitTimeout}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithContextCancelsRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := withContext(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}")), Header: make(http.Header)}, nil
+	})}, ctx)
+
+	resp, err := client.Get("https://api.github.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	_, err = client.Get("https://api.github.com/")
+	assert.Equal(t, errors.Is(err, context.Canceled), true)
+}
+
+func TestLaunchStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l := &Launcher{ctx: ctx}
+	assert.Equal(t, l.launch("launcher", "abc"), context.Canceled)
+}
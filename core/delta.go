@@ -0,0 +1,186 @@
+package core
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/utils"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var ErrCorruptPatch = errors.New("corrupt patch")
+
+// bsdiffMagic starts a patch in the format of bsdiff 4.
+const bsdiffMagic = "BSDIFF40"
+
+// maxPatchedSize bounds the size of a patched archive, which is allocated
+// upfront from the untrusted header of the patch.
+const maxPatchedSize = 1 << 30
+
+// patchAsset returns the name of the release asset patching the launcher
+// archive of release from into the one of the release publishing it.
+func patchAsset(arch string, from string) string {
+	return fmt.Sprintf("%s.from-%s.bsdiff", releaseAsset(arch), from)
+}
+
+// offtin decodes the sign-magnitude integers of bsdiff.
+func offtin(buf []byte) int64 {
+	y := int64(binary.LittleEndian.Uint64(buf) &^ (1 << 63))
+	if buf[7]&0x80 != 0 {
+		y = -y
+	}
+	return y
+}
+
+// bspatch applies the bsdiff patch to old. The patched file may not exceed
+// max bytes, nor maxPatchedSize when max is 0 or larger.
+func bspatch(old []byte, patch []byte, max int64) ([]byte, error) {
+	if len(patch) < 32 || string(patch[:8]) != bsdiffMagic {
+		return nil, fmt.Errorf("%w: bad header", ErrCorruptPatch)
+	}
+	ctrlLen, diffLen, newSize := offtin(patch[8:]), offtin(patch[16:]), offtin(patch[24:])
+	// each length is checked on its own, their sum could overflow
+	body := int64(len(patch)) - 32
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || ctrlLen > body || diffLen > body-ctrlLen {
+		return nil, fmt.Errorf("%w: bad header", ErrCorruptPatch)
+	}
+	if max <= 0 || max > maxPatchedSize {
+		max = maxPatchedSize
+	}
+	if newSize > max {
+		return nil, fmt.Errorf("%w: patched archive has %d bytes, the limit is %d", ErrCorruptPatch, newSize, max)
+	}
+	ctrl := bzip2.NewReader(bytes.NewReader(patch[32 : 32+ctrlLen]))
+	diff := bzip2.NewReader(bytes.NewReader(patch[32+ctrlLen : 32+ctrlLen+diffLen]))
+	extra := bzip2.NewReader(bytes.NewReader(patch[32+ctrlLen+diffLen:]))
+
+	result := make([]byte, newSize)
+	// seeks may not leave the old file by more than the size of the new one,
+	// which keeps the positions far from overflowing
+	reach := int64(len(old)) + newSize
+	var oldPos, newPos int64
+	buf := make([]byte, 24)
+	for newPos < newSize {
+		if _, err := io.ReadFull(ctrl, buf); err != nil {
+			return nil, fmt.Errorf("%w: control: %s", ErrCorruptPatch, err)
+		}
+		add, copied, seek := offtin(buf), offtin(buf[8:]), offtin(buf[16:])
+		if add < 0 || copied < 0 || add > newSize-newPos {
+			return nil, fmt.Errorf("%w: bad control", ErrCorruptPatch)
+		}
+		if _, err := io.ReadFull(diff, result[newPos:newPos+add]); err != nil {
+			return nil, fmt.Errorf("%w: diff: %s", ErrCorruptPatch, err)
+		}
+		for i := int64(0); i < add; i++ {
+			if o := oldPos + i; o >= 0 && o < int64(len(old)) {
+				result[newPos+i] += old[o]
+			}
+		}
+		newPos += add
+		oldPos += add
+		if copied > newSize-newPos {
+			return nil, fmt.Errorf("%w: bad control", ErrCorruptPatch)
+		}
+		if _, err := io.ReadFull(extra, result[newPos:newPos+copied]); err != nil {
+			return nil, fmt.Errorf("%w: extra: %s", ErrCorruptPatch, err)
+		}
+		newPos += copied
+		if seek < -reach || seek > reach || oldPos+seek < -reach || oldPos+seek > reach {
+			return nil, fmt.Errorf("%w: bad seek", ErrCorruptPatch)
+		}
+		oldPos += seek
+	}
+	return result, nil
+}
+
+// applyPatch writes the archive old patched with patch to file.
+func applyPatch(old string, patch string, file string, max int64) error {
+	oldData, err := ioutil.ReadFile(old)
+	if err != nil {
+		return err
+	}
+	patchData, err := ioutil.ReadFile(patch)
+	if err != nil {
+		return err
+	}
+	data, err := bspatch(oldData, patchData, max)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".part"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// downloadPatch creates launcher.zip in the current dir by patching the
+// archive of the newest installed release before tag, reporting whether it
+// did. The whole archive is downloaded when the release publishes no patch
+// against it or patching fails.
+func (t *GithubClient) downloadPatch(tag string) bool {
+	if t.Previous == nil || t.noDelta || !ReleaseRef.MatchString(tag) {
+		return false
+	}
+	from, archive, ok := t.Previous(tag)
+	if !ok {
+		return false
+	}
+	name := patchAsset(t.selectedArch(), from)
+	url := fmt.Sprintf("https://github.com/opendexnetwork/opendex-docker/releases/download/%s/%s", tag, name)
+	if _, err := t.downloadFile(url, name, cacheValidators{}); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			t.Logger.Warnf("Failed to download the patch from %s, downloading the whole archive: %s", from, err)
+		}
+		return false
+	}
+	defer os.Remove(name)
+	max := t.Limits.MaxArchiveSize
+	if size := t.expectedSize(releaseDownloads + tag + "/" + releaseAsset(t.selectedArch())); size > 0 {
+		max = size
+	}
+	if err := applyPatch(archive, name, "launcher.zip", max); err != nil {
+		t.Logger.Warnf("Failed to apply the patch from %s, downloading the whole archive: %s", from, err)
+		return false
+	}
+	t.Logger.Infof("Updated the launcher archive of %s with the patch from %s", tag, from)
+	return true
+}
+
+// previousRelease returns the tag and the archive of the newest installed
+// release before tag, for downloading a patch against it.
+func (t *Launcher) previousRelease(tag string) (string, string, bool) {
+	versions, err := t.installedVersions()
+	if err != nil {
+		return "", "", false
+	}
+	var from, archive string
+	for _, v := range versions {
+		dir := filepath.Join(t.launcherVersionsDir, v.Commit)
+		m, err := readMetadata(dir)
+		if err != nil || !ReleaseRef.MatchString(m.Branch) || compareReleases(m.Branch, tag) >= 0 {
+			continue
+		}
+		if from != "" && compareReleases(m.Branch, from) <= 0 {
+			continue
+		}
+		file := filepath.Join(dir, "launcher.zip")
+		if exists, _ := utils.FileExists(file); !exists {
+			if t.github.Cache == nil {
+				continue
+			}
+			blob, _, ok := t.github.Cache.lookup(v.Commit)
+			if !ok {
+				continue
+			}
+			file = blob
+		}
+		from, archive = m.Branch, file
+	}
+	return from, archive, from != ""
+}
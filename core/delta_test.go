@@ -0,0 +1,150 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testPatch patches "opendex launcher 21.02.10" into
+// "opendex launcher 21.03.01 beta" in the format of bsdiff 4.
+const testPatch = "42534449464634302b000000000000002d000000000000001e00000000000000425a683931415926535902573b10000005e0004a08002020002186819a0c56c9b8bb9229c2848012b9d880425a68393141592653596763505e0000006000e00020000000a000219a68334d028ace2ee48a70a120cec6a0bc425a6839314159265359eb1a2ffa000000118040003200040020002183419a085c7177245385090eb1a2ffa0"
+
+// Crafted patches of "abcdef" into 4 bytes: seekBackPatch copies "ab" twice,
+// seeking back in between, seekFarPatch seeks 2^62 bytes back and
+// truncatedPatch has a control block of 10 bytes.
+const (
+	seekBackPatch  = "42534449464634302f0000000000000025000000000000000400000000000000425a6839314159265359c3861c16000006e040500808004000200030cd00da031894ba9b8bb9229c284861c30e0b00425a683931415926535938fb2284000002400040002000211846b0bb9229c28481c7d91420425a683917724538509000000000"
+	seekFarPatch   = "42534449464634302e0000000000000025000000000000000400000000000000425a68393141592653595c3fdcb10000046004500018004000200030cd00900c62ba1b8bb9229c28482e1fee5880425a683931415926535938fb2284000002400040002000211846b0bb9229c28481c7d91420425a683917724538509000000000"
+	truncatedPatch = "4253444946463430270000000000000025000000000000000400000000000000425a68393141592653596bf60cbe00000240005200200030cc0cf505ce2ee48a70a120d7ec197c425a683931415926535938fb2284000002400040002000211846b0bb9229c28481c7d91420425a683917724538509000000000"
+)
+
+func decodePatch(t *testing.T) []byte {
+	return decodeHex(t, testPatch)
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	patch, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return patch
+}
+
+func TestBspatch(t *testing.T) {
+	patch := decodePatch(t)
+
+	result, err := bspatch([]byte("opendex launcher 21.02.10"), patch, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(result), "opendex launcher 21.03.01 beta")
+
+	_, err = bspatch([]byte("opendex launcher 21.02.10"), patch, 16)
+	assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+
+	_, err = bspatch(nil, []byte("not a patch"), 0)
+	assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+
+	_, err = bspatch(nil, patch[:100], 0)
+	assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+
+	// sizes from the header are bounded before anything is allocated
+	huge := append([]byte(nil), patch...)
+	binary.LittleEndian.PutUint64(huge[24:], 1<<40)
+	_, err = bspatch(nil, huge, 0)
+	assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+	huge[31] |= 0x80
+	_, err = bspatch(nil, huge, 0)
+	assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+}
+
+func TestBspatchCraftedHeaders(t *testing.T) {
+	header := func(ctrlLen uint64, diffLen uint64) []byte {
+		patch := make([]byte, 40)
+		copy(patch, bsdiffMagic)
+		binary.LittleEndian.PutUint64(patch[8:], ctrlLen)
+		binary.LittleEndian.PutUint64(patch[16:], diffLen)
+		binary.LittleEndian.PutUint64(patch[24:], 4)
+		return patch
+	}
+	// the sum of the lengths overflows
+	for _, patch := range [][]byte{header(1<<63-1, 0), header(0, 1<<63-1), header(8, 1<<63-1), header(1<<62, 1<<62), header(9, 0)} {
+		_, err := bspatch(nil, patch, 0)
+		assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+	}
+
+	result, err := bspatch([]byte("abcdef"), decodeHex(t, seekBackPatch), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(result), "abab")
+
+	_, err = bspatch([]byte("abcdef"), decodeHex(t, seekFarPatch), 0)
+	assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+	_, err = bspatch([]byte("abcdef"), decodeHex(t, truncatedPatch), 0)
+	assert.Equal(t, errors.Is(err, ErrCorruptPatch), true)
+}
+
+func TestDownloadPatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	old := filepath.Join(dir, "old.zip")
+	if err := ioutil.WriteFile(old, []byte("opendex launcher 21.02.10"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	patch := decodePatch(t)
+	var requested []string
+	c := NewGithubClient()
+	c.Archs = []string{"amd64"}
+	c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Path)
+		if !strings.HasSuffix(req.URL.Path, ".from-21.02.10.bsdiff") {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(patch)), Header: make(http.Header)}, nil
+	})}
+
+	assert.Equal(t, c.downloadPatch("21.03.01"), false)
+	assert.Equal(t, len(requested), 0)
+
+	c.Previous = func(tag string) (string, string, bool) {
+		return "21.02.10", old, true
+	}
+	assert.Equal(t, c.downloadPatch("21.03.01"), true)
+	assert.Equal(t, requested, []string{"/opendexnetwork/opendex-docker/releases/download/21.03.01/" + patchAsset("amd64", "21.02.10")})
+	data, err := ioutil.ReadFile("launcher.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), "opendex launcher 21.03.01 beta")
+
+	c.Previous = func(tag string) (string, string, bool) {
+		return "21.01.01", old, true
+	}
+	assert.Equal(t, c.downloadPatch("21.03.01"), false)
+
+	c.noDelta = true
+	assert.Equal(t, c.downloadPatch("21.03.01"), false)
+	assert.Equal(t, len(requested), 2)
+}
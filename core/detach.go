@@ -0,0 +1,272 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// detachedEnv is set in the environment of a detached opendex-launcher.
+const detachedEnv = "OPENDEX_LAUNCHER_DETACHED"
+
+// pidFile returns the file holding the pid of the detached opendex-launcher
+// of the network.
+func (t *Launcher) pidFile() string {
+	return filepath.Join(t.launcherDir, t.network+".pid")
+}
+
+// childPidFile returns the file holding the pid of the launcher run by the
+// detached opendex-launcher of the network, which leads its own process
+// group.
+func (t *Launcher) childPidFile() string {
+	return filepath.Join(t.launcherDir, t.network+".child.pid")
+}
+
+// recordDetachedChild writes the pid of the launcher, so stop can kill its
+// process group along with the one of opendex-launcher.
+func (t *Launcher) recordDetachedChild(pid int) {
+	if err := writePidFile(t.childPidFile(), pid); err != nil {
+		t.logger.Warnf("Failed to record the pid of the launcher: %s", err)
+	}
+}
+
+func (t *Launcher) removeDetachedChild() {
+	_ = os.Remove(t.childPidFile())
+}
+
+// argsFile returns the file holding the launcher arguments of the detached
+// opendex-launcher of the network, which restart starts it with again.
+func (t *Launcher) argsFile() string {
+	return filepath.Join(t.launcherDir, t.network+".args")
+}
+
+// logFile returns the file a detached opendex-launcher writes its output to.
+func (t *Launcher) logFile() string {
+	return filepath.Join(t.launcherDir, "logs", t.network+".log")
+}
+
+func readPidFile(file string) (int, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return pid, nil
+}
+
+func writePidFile(file string, pid int) error {
+	return ioutil.WriteFile(file, []byte(fmt.Sprintf("%d\n", pid)), 0644)
+}
+
+// detachedPid returns the pid of the running detached opendex-launcher of the
+// network, or 0 when there is none. Stale pid files are removed.
+func (t *Launcher) detachedPid() (int, error) {
+	pid, err := readPidFile(t.pidFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !processAlive(pid) {
+		_ = os.Remove(t.pidFile())
+		return 0, nil
+	}
+	return pid, nil
+}
+
+// removePidFile removes the pid file when it belongs to this process.
+func (t *Launcher) removePidFile() {
+	if pid, err := readPidFile(t.pidFile()); err == nil && pid == os.Getpid() {
+		_ = os.Remove(t.pidFile())
+	}
+}
+
+// detachArgs returns the command line of a detached opendex-launcher running
+// the launcher with rest. The access token is passed by detachEnv, command
+// lines are readable by every local user.
+func detachArgs(a *bootstrapArgs, network string, branch string, rest []string) []string {
+	args := []string{"--network", network, "--branch", branch}
+	if a.profile != "" {
+		args = append(args, "--profile", a.profile)
+	}
+	for _, override := range a.overrides {
+		args = append(args, "-o", override)
+	}
+	if a.logLevel != "" {
+		args = append(args, "--log-level", a.logLevel)
+	}
+	if a.timeout > 0 {
+		args = append(args, "--timeout", a.timeout.String())
+	}
+	if a.dev != "" {
+		args = append(args, "--dev", a.dev)
+	}
+	if a.traceHTTP {
+		args = append(args, "--trace-http")
+	}
+	if a.fallbackToMaster {
+		args = append(args, "--fallback-to-master")
+	}
+	if a.ephemeral {
+		args = append(args, "--ephemeral")
+	}
+	if a.watch {
+		args = append(args, "--watch")
+		if a.watchInterval > 0 && a.watchInterval != defaultWatchInterval {
+			args = append(args, "--watch-interval", a.watchInterval.String())
+		}
+	}
+	args = append(args, "--")
+	return append(args, rest...)
+}
+
+// detachEnv returns the environment of a detached opendex-launcher.
+func detachEnv(a *bootstrapArgs, environ []string) []string {
+	env := append(environ, detachedEnv+"=1")
+	if a.accessToken != "" {
+		// later values override the inherited ones
+		env = append(env, "GITHUB_ACCESS_TOKEN="+a.accessToken)
+	}
+	return env
+}
+
+// runStartDetach starts opendex-launcher again in the background, with its
+// output going to the log file of the network, and returns right away.
+func (t *Launcher) runStartDetach(args []string) error {
+	if t.network == "" {
+		return ErrNetworkEmpty
+	}
+	if pid, err := t.detachedPid(); err != nil {
+		return err
+	} else if pid != 0 {
+		return fmt.Errorf("%s is already running in the background (pid %d)", t.network, pid)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.logFile()), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(t.argsFile(), data, 0600); err != nil {
+		return fmt.Errorf("write args file: %w", err)
+	}
+	log, err := os.OpenFile(t.logFile(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	cmd := exec.Command(exe, detachArgs(t.args, t.network, t.branch, args)...)
+	cmd.Env = detachEnv(t.args, os.Environ())
+	cmd.Stdout = log
+	cmd.Stderr = log
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	pid := cmd.Process.Pid
+	if err := writePidFile(t.pidFile(), pid); err != nil {
+		return fmt.Errorf("write pid file: %w", err)
+	}
+	if err := cmd.Process.Release(); err != nil {
+		return err
+	}
+	fmt.Printf("Started %s in the background (pid %d), logging to %s\n", t.network, pid, t.logFile())
+	return nil
+}
+
+// stopDetached stops the detached opendex-launcher of the network, which in
+// turn stops the launcher within the shutdown grace period. It reports
+// whether one was running.
+func (t *Launcher) stopDetached() (bool, error) {
+	if t.network == "" {
+		return false, ErrNetworkEmpty
+	}
+	pid, err := t.detachedPid()
+	if err != nil || pid == 0 {
+		return false, err
+	}
+	if err := signalGroup(pid, syscall.SIGTERM); err != nil {
+		return true, fmt.Errorf("stop pid %d: %w", pid, err)
+	}
+
+	// the launcher is killed after the grace period, leave some time for
+	// the post-exit hook on top of that
+	grace := time.Duration(t.config.Shutdown.GracePeriod)*time.Second + 30*time.Second
+	deadline := time.Now().Add(grace)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			t.logger.Warnf("opendex-launcher (pid %d) did not exit within %s, killing it", pid, grace)
+			// the launcher runs in a process group of its own
+			if childPid, err := readPidFile(t.childPidFile()); err == nil && processAlive(childPid) {
+				if err := signalGroup(childPid, os.Kill); err != nil {
+					t.logger.Warnf("Failed to kill the launcher (pid %d): %s", childPid, err)
+				}
+			}
+			if err := signalGroup(pid, os.Kill); err != nil {
+				return true, fmt.Errorf("kill pid %d: %w", pid, err)
+			}
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	_ = os.Remove(t.pidFile())
+	t.removeDetachedChild()
+	return true, nil
+}
+
+func (t *Launcher) runStop(args []string) error {
+	running, err := t.stopDetached()
+	if err != nil {
+		return err
+	}
+	if !running {
+		// the stop command of the launcher itself
+		return errNotLocal
+	}
+	fmt.Printf("Stopped %s\n", t.network)
+	return nil
+}
+
+// runRestart stops the detached opendex-launcher and starts it again with
+// the same launcher arguments. The new one resolves the branch again and so
+// picks up a new launcher build.
+func (t *Launcher) runRestart(args []string) error {
+	running, err := t.stopDetached()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(t.argsFile())
+	if errors.Is(err, os.ErrNotExist) && !running {
+		// the restart command of the launcher itself
+		return errNotLocal
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var launcherArgs []string
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &launcherArgs); err != nil {
+			return fmt.Errorf("parse %s: %w", t.argsFile(), err)
+		}
+	}
+	return t.runStartDetach(launcherArgs)
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetachArgs(t *testing.T) {
+	a := &bootstrapArgs{accessToken: "token", timeout: 5 * time.Minute, progress: "json", logLevel: "warn", overrides: overrideFlag{"cache.max-size=5"}, traceHTTP: true, fallbackToMaster: true}
+	args := detachArgs(a, "testnet", "master", []string{"--branch", "x"})
+
+	assert.Equal(t, args, []string{"--network", "testnet", "--branch", "master", "-o", "cache.max-size=5", "--log-level", "warn", "--timeout", "5m0s", "--trace-http", "--fallback-to-master", "--", "--branch", "x"})
+}
+
+func TestDetachArgsEmpty(t *testing.T) {
+	args := detachArgs(&bootstrapArgs{}, "simnet", "master", nil)
+
+	assert.Equal(t, args, []string{"--network", "simnet", "--branch", "master", "--"})
+}
+
+func TestDetachEnv(t *testing.T) {
+	a := &bootstrapArgs{accessToken: "token"}
+	for _, arg := range detachArgs(a, "testnet", "master", nil) {
+		assert.Equal(t, strings.Contains(arg, "token"), false, arg)
+	}
+
+	env := detachEnv(a, []string{"PATH=/usr/bin", "GITHUB_ACCESS_TOKEN=old"})
+	assert.Equal(t, env, []string{"PATH=/usr/bin", "GITHUB_ACCESS_TOKEN=old", detachedEnv + "=1", "GITHUB_ACCESS_TOKEN=token"})
+	assert.Equal(t, detachEnv(&bootstrapArgs{}, nil), []string{detachedEnv + "=1"})
+}
+
+func TestDetachedChild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "launcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	l := &Launcher{launcherDir: dir, network: "testnet", logger: logrus.NewEntry(logrus.New())}
+
+	l.recordDetachedChild(1234)
+	pid, err := readPidFile(l.childPidFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, pid, 1234)
+	l.removeDetachedChild()
+	_, err = readPidFile(l.childPidFile())
+	assert.Equal(t, os.IsNotExist(err), true)
+}
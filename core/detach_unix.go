@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachProcess starts cmd in a session of its own, without a controlling
+// terminal.
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether a process with pid exists.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"golang.org/x/sys/windows"
+	"os/exec"
+	"syscall"
+)
+
+// stillActive is the exit code of a process which has not exited yet.
+const stillActive = 259
+
+// detachProcess starts cmd without a console.
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+		HideWindow:    true,
+	}
+}
+
+// processAlive reports whether a process with pid is running.
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
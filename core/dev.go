@@ -0,0 +1,99 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// devSourceDir returns the directory of the launcher module in the
+// opendex-docker checkout at path, which is either the checkout itself or
+// its launcher directory.
+func devSourceDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range []string{filepath.Join(abs, "launcher"), abs} {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no launcher sources in %s: expected go.mod in %s or its launcher directory", path, path)
+}
+
+// newestSource returns the latest modification time of the Go sources below
+// dir.
+func newestSource(dir string) (time.Time, error) {
+	var newest time.Time
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := info.Name()
+		if strings.HasSuffix(name, ".go") || name == "go.mod" || name == "go.sum" {
+			if info.ModTime().After(newest) {
+				newest = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return newest, err
+}
+
+// builtSince tells whether launcher was built after t, so that a rebuild of
+// an unchanged checkout still restarts the launcher.
+func builtSince(launcher string, t time.Time) bool {
+	info, err := os.Stat(launcher)
+	return err == nil && info.ModTime().After(t)
+}
+
+// devCommit describes the checkout at dir like `git describe`, e.g.
+// 4c1b8a9-dirty, or returns dev when it is no git checkout.
+func devCommit(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "describe", "--always", "--dirty", "--abbrev=7").Output()
+	if err != nil {
+		return "dev"
+	}
+	return "dev-" + strings.TrimSpace(string(out))
+}
+
+// buildDev builds the launcher from the opendex-docker checkout given with
+// --dev, unless the last build is newer than every source file, and returns
+// the binary and the version of the checkout.
+func (t *Launcher) buildDev() (string, string, error) {
+	src, err := devSourceDir(t.args.dev)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(src))
+	launcher := filepath.Join(t.launcherDir, "dev", hex.EncodeToString(sum[:])[:12], launcherBinary())
+	commit := devCommit(src)
+
+	newest, err := newestSource(src)
+	if err != nil {
+		return "", "", err
+	}
+	if info, err := os.Stat(launcher); err == nil && info.ModTime().After(newest) {
+		t.logger.Infof("Using launcher built from %s (%s)", src, commit)
+		return launcher, commit, nil
+	}
+
+	t.reportPhase(PhaseBuild, 0, "Building launcher from %s", src)
+	if err := buildLauncher(src, launcher); err != nil {
+		return "", "", fmt.Errorf("build launcher in %s: %w", src, err)
+	}
+	t.reportPhase(PhaseBuild, 100, "Built launcher from %s (%s)", src, commit)
+	return launcher, commit, nil
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDevSourceDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = devSourceDir(dir)
+	assert.Equal(t, err != nil, true)
+
+	src := filepath.Join(dir, "launcher")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "go.mod"), []byte("module launcher\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	found, err := devSourceDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, found, src)
+	found, err = devSourceDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, found, src)
+}
+
+func TestNewestSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	old := time.Unix(1600000000, 0)
+	recent := time.Unix(1700000000, 0)
+	for file, mtime := range map[string]time.Time{
+		"go.mod":         old,
+		"main.go":        recent,
+		"README.md":      recent.Add(time.Hour),
+		".git/config.go": recent.Add(time.Hour),
+	} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newest, err := newestSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, newest.Equal(recent), true)
+	assert.Equal(t, devCommit(dir), "dev")
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"time"
+)
+
+type HTTP struct {
+	IPFamily string   `toml:"ip-family" default:"any" comment:"IP version to connect to GitHub and artifact mirrors with: any, ipv4, ipv6, prefer-ipv4 or prefer-ipv6"`
+	Headers  []string `toml:"headers" secret:"true" comment:"Extra headers sent to a download host over https, e.g. the auth header of an artifact mirror, as \"<host> <Name>: <value>\""`
+}
+
+// familyDialer connects over the IP version chosen with http.ip-family. The
+// prefer modes fall back to the other version when the preferred one fails,
+// the other modes never use it.
+type familyDialer struct {
+	dialer *net.Dialer
+	family string
+}
+
+func newFamilyDialer(family string) (*familyDialer, error) {
+	switch family {
+	case "", "any", "ipv4", "ipv6", "prefer-ipv4", "prefer-ipv6":
+	default:
+		return nil, fmt.Errorf("invalid ip-family: %s", family)
+	}
+	return &familyDialer{
+		dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		family: family,
+	}, nil
+}
+
+func (d *familyDialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	if network != "tcp" {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+	switch d.family {
+	case "ipv4":
+		return d.dialer.DialContext(ctx, "tcp4", address)
+	case "ipv6":
+		return d.dialer.DialContext(ctx, "tcp6", address)
+	case "prefer-ipv4":
+		return d.dialPreferred(ctx, "tcp4", "tcp6", address)
+	case "prefer-ipv6":
+		return d.dialPreferred(ctx, "tcp6", "tcp4", address)
+	default:
+		return d.dialer.DialContext(ctx, network, address)
+	}
+}
+
+func (d *familyDialer) dialPreferred(ctx context.Context, preferred string, fallback string, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, preferred, address)
+	if err == nil || ctx.Err() != nil {
+		return conn, err
+	}
+	conn, fallbackErr := d.dialer.DialContext(ctx, fallback, address)
+	if fallbackErr != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// httpClient returns the client for the requests to GitHub, connecting with
+// the configured IP version and proxy.
+func (t *Launcher) httpClient() (*http.Client, error) {
+	dialer, err := newFamilyDialer(t.config.HTTP.IPFamily)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := t.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(t.config.HTTP.Headers)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = proxy
+	var base http.RoundTripper = transport
+	if len(headers) > 0 {
+		// below the trace, which must not log their secrets
+		base = &headerTransport{base: transport, headers: headers}
+	}
+	if t.args != nil && t.args.traceHTTP {
+		logger := logrus.NewEntry(logrus.StandardLogger()).WithField("name", "http")
+		return &http.Client{Transport: newUserAgentTransport(newTraceTransport(base, logger))}, nil
+	}
+	return &http.Client{Transport: newUserAgentTransport(base)}, nil
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"github.com/magiconair/properties/assert"
+	"net"
+	"testing"
+)
+
+func TestFamilyDialer(t *testing.T) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	address := l.Addr().String()
+
+	for _, test := range []struct {
+		family string
+		ok     bool
+	}{
+		{"any", true},
+		{"ipv4", true},
+		{"ipv6", false},
+		{"prefer-ipv4", true},
+		{"prefer-ipv6", true},
+	} {
+		d, err := newFamilyDialer(test.family)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := d.DialContext(context.Background(), "tcp", address)
+		if err == nil {
+			conn.Close()
+		}
+		assert.Equal(t, err == nil, test.ok, test.family)
+	}
+}
+
+func TestFamilyDialerInvalid(t *testing.T) {
+	_, err := newFamilyDialer("ipv5")
+
+	assert.Equal(t, err.Error(), "invalid ip-family: ipv5")
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ImageManifest maps the services of a release to the content digests of
+// their images. It is published as the release asset images-<network>.json
+// together with a detached ed25519 signature images-<network>.json.sig.
+type ImageManifest struct {
+	Services map[string]ManifestImage `json:"services"`
+}
+
+type ManifestImage struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
+// pinnedRef returns image pinned to digest, e.g.
+// opendexnetwork/opendexd@sha256:...
+func pinnedRef(image string, digest string) string {
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name + "@" + digest
+}
+
+func verifyManifest(data []byte, signature []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil {
+		return fmt.Errorf("decode public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key size: %d", len(key))
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (t *Launcher) fetchImageManifest() (*ImageManifest, error) {
+	name := fmt.Sprintf("images-%s.json", t.network)
+	data, err := t.github.GetReleaseAsset(t.branch, name)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := t.github.GetReleaseAsset(t.branch, name+".sig")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyManifest(data, signature, t.config.Docker.ManifestKey); err != nil {
+		return nil, fmt.Errorf("verify %s: %w", name, err)
+	}
+	var manifest ImageManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", name, err)
+	}
+	return &manifest, nil
+}
+
+// pinImages pulls the images of the release manifest by digest and passes
+// the pinned references to the launcher as OPENDEX_IMAGE_<SERVICE>, so a
+// repointed tag can never change what runs.
+func (t *Launcher) pinImages() error {
+	if !ReleaseRef.MatchString(t.branch) {
+		t.logger.Warnf("Branch %s is not a release, skip image digest verification", t.branch)
+		return nil
+	}
+	if t.config.Docker.ManifestKey == "" {
+		return errors.New("docker.manifest-key is required to verify image digests")
+	}
+
+	manifest, err := t.fetchImageManifest()
+	if err != nil {
+		return fmt.Errorf("image manifest: %w", err)
+	}
+
+	var services []string
+	for service := range manifest.Services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		image := manifest.Services[service]
+		if !strings.HasPrefix(image.Digest, "sha256:") {
+			return fmt.Errorf("invalid digest of %s: %s", service, image.Digest)
+		}
+		ref := pinnedRef(image.Image, image.Digest)
+		cmd := exec.Command(t.containerCLI(), "pull", ref)
+		// stdout carries the events of --progress=json
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("pull %s: %w", ref, err)
+		}
+		key := "OPENDEX_IMAGE_" + strings.ToUpper(strings.ReplaceAll(service, "-", "_"))
+		t.childEnv = append(t.childEnv, key+"="+ref)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const PhasePull = "pull"
+
+type Docker struct {
+	PrePull   bool   `toml:"pre-pull" default:"false" comment:"Pull the docker images of the network before starting the launcher"`
+	ImageList string `toml:"image-list" default:"images/{network}.txt" comment:"Image list in the opendex-docker repository, one image per line, {network} is replaced by the network name"`
+
+	VerifyDigests bool   `toml:"verify-digests" default:"false" comment:"Pull release images by the digests of the signed release manifest and pin them for the launcher"`
+	ManifestKey   string `toml:"manifest-key" comment:"Base64 ed25519 public key the release image manifest is signed with"`
+
+	Backend string `toml:"backend" default:"auto" comment:"Container backend: auto, docker or podman. auto prefers Docker and falls back to Podman"`
+}
+
+func parseImageList(data []byte) []string {
+	var images []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			images = append(images, line)
+		}
+	}
+	return images
+}
+
+// prePullImages pulls the images of the network at commit so that the
+// launcher does not start with a long silent pull.
+func (t *Launcher) prePullImages(commit string) error {
+	path := strings.ReplaceAll(t.config.Docker.ImageList, "{network}", t.network)
+	data, err := t.github.GetFile(commit, path)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			t.logger.Warnf("No image list %s at %s, skip pre-pulling images", path, shortCommit(commit))
+			return nil
+		}
+		return fmt.Errorf("get image list: %w", err)
+	}
+
+	images := parseImageList(data)
+	for i, image := range images {
+		t.reportPhase(PhasePull, percent(int64(i), int64(len(images))), "Pulling %s", image)
+		t.logger.Infof("Pulling %s (%d/%d)", image, i+1, len(images))
+		cmd := exec.Command(t.containerCLI(), "pull", image)
+		// stdout carries the events of --progress=json
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("pull %s: %w", image, err)
+		}
+	}
+	t.reportPhase(PhasePull, 100, "Pulled %d images", len(images))
+	return nil
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestParseImageList(t *testing.T) {
+	images := parseImageList([]byte(`
+# mainnet images
+opendexnetwork/opendexd:latest
+  opendexnetwork/lndbtc:0.12.1 # pinned
+`))
+
+	assert.Equal(t, images, []string{"opendexnetwork/opendexd:latest", "opendexnetwork/lndbtc:0.12.1"})
+}
+
+func TestPinnedRef(t *testing.T) {
+	digest := "sha256:0123"
+	assert.Equal(t, pinnedRef("opendexnetwork/opendexd:21.02.10", digest), "opendexnetwork/opendexd@sha256:0123")
+	assert.Equal(t, pinnedRef("localhost:5000/opendexd", digest), "localhost:5000/opendexd@sha256:0123")
+	assert.Equal(t, pinnedRef("opendexd@sha256:ffff", digest), "opendexd@sha256:0123")
+}
+
+func TestVerifyManifest(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"services":{}}`)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, data))
+	key := base64.StdEncoding.EncodeToString(public)
+
+	assert.Equal(t, verifyManifest(data, []byte(signature), key), nil)
+	assert.Equal(t, verifyManifest([]byte(`{}`), []byte(signature), key) != nil, true)
+}
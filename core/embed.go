@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// Prepare gets the launcher ready to be driven step by step with Resolve,
+// EnsureInstalled and Launch instead of Start. args are the bootstrap flags
+// and launcher arguments of the command line, e.g. "--network", "testnet",
+// "status". Bootstrap commands are not run. Cancelling ctx aborts resolving
+// and downloading and stops the launcher started by Launch.
+func (t *Launcher) Prepare(ctx context.Context, args []string) error {
+	t.ctx = ctx
+	if err := t.load(args); err != nil {
+		return err
+	}
+	if err := t.useSharedCache(); err != nil {
+		return err
+	}
+	if err := t.useVersionsDir(); err != nil {
+		return err
+	}
+	if err := t.setupGithub(); err != nil {
+		return err
+	}
+	t.setBranch()
+	return nil
+}
+
+// Resolve returns the commit the branch, release tag or release channel
+// currently points to.
+func (t *Launcher) Resolve() (string, error) {
+	return t.resolve()
+}
+
+// EnsureInstalled downloads and verifies the launcher of commit unless it is
+// installed already and returns its path.
+func (t *Launcher) EnsureInstalled(commit string) (string, error) {
+	return t.ensureLauncher(commit)
+}
+
+// Launch runs the launcher at path, installed for commit, until it exits.
+func (t *Launcher) Launch(path string, commit string) error {
+	return t.runLauncher(path, commit)
+}
+
+// Subscribe calls handler with every event of the launcher until the
+// returned function is called. Handlers are called one at a time and must
+// neither block nor unsubscribe.
+func (t *Launcher) Subscribe(handler EventHandler) func() {
+	t.emitMu.Lock()
+	defer t.emitMu.Unlock()
+	remove := t.subscribe(handler)
+	return func() {
+		t.emitMu.Lock()
+		defer t.emitMu.Unlock()
+		remove()
+	}
+}
+
+// Events returns a channel receiving every event of the launcher and the
+// function that stops the events and closes the channel. Up to buffer
+// events are kept for a slow reader, further events are dropped until it
+// catches up.
+func (t *Launcher) Events(buffer int) (<-chan Event, func()) {
+	events := make(chan Event, buffer)
+	unsubscribe := t.Subscribe(func(e Event) {
+		select {
+		case events <- e:
+		default:
+		}
+	})
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			// no handler runs after unsubscribe returned
+			unsubscribe()
+			close(events)
+		})
+	}
+}
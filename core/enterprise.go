@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+var ErrEnterprise = errors.New("GitHub access is disabled in enterprise mode")
+
+// Enterprise locks opendex-launcher down for environments with their own
+// distribution channel. Versions are only installed with bundle import.
+type Enterprise struct {
+	Enabled bool `toml:"enabled" comment:"Never contact GitHub and only run versions installed with bundle import"`
+}
+
+// forbiddenTransport fails every request, so that nothing reaches GitHub in
+// enterprise mode.
+type forbiddenTransport struct{}
+
+func (forbiddenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("%w: %s %s", ErrEnterprise, req.Method, req.URL.Host)
+}
+
+func forbiddenClient() *http.Client {
+	return &http.Client{Transport: forbiddenTransport{}}
+}
+
+// importedResolver resolves branches to the newest version imported for
+// them, or a commit to the imported version it starts.
+type importedResolver struct {
+	launcher *Launcher
+}
+
+func (t *importedResolver) GetHeadCommit(branch string) (string, error) {
+	versions, err := t.launcher.installedVersions()
+	if err != nil {
+		return "", err
+	}
+	for _, v := range versions {
+		m, err := readMetadata(filepath.Join(t.launcher.launcherVersionsDir, v.Commit))
+		if err != nil || !m.Imported {
+			continue
+		}
+		if m.Branch == branch || strings.HasPrefix(v.Commit, branch) {
+			return v.Commit, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no version of %s was imported with bundle import", ErrEnterprise, branch)
+}
+
+// checkImported refuses to run versions that were not imported with bundle
+// import in enterprise mode.
+func (t *Launcher) checkImported(commit string, launcher string, exists bool) error {
+	if !t.config.Enterprise.Enabled {
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s is not installed, import it with bundle import", ErrEnterprise, shortCommit(commit))
+	}
+	m, err := readMetadata(filepath.Dir(launcher))
+	if err != nil {
+		return err
+	}
+	if !m.Imported {
+		return fmt.Errorf("%w: %s was not installed with bundle import", ErrEnterprise, shortCommit(commit))
+	}
+	return nil
+}
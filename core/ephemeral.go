@@ -0,0 +1,35 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+var ErrEphemeralMainnet = errors.New("mainnet cannot run ephemeral")
+
+// ephemeralNetworkDir creates a throwaway data dir of network for
+// --ephemeral, removed again by removeEphemeral.
+func (t *Launcher) ephemeralNetworkDir(network string) (string, error) {
+	if network == "mainnet" {
+		return "", fmt.Errorf("%w, its wallets would be lost on exit", ErrEphemeralMainnet)
+	}
+	dir, err := ioutil.TempDir("", fmt.Sprintf("opendex-%s-", network))
+	if err != nil {
+		return "", err
+	}
+	t.ephemeralDirs = append(t.ephemeralDirs, dir)
+	t.logger.Infof("Running %s in the ephemeral data dir %s, it is removed on exit", network, dir)
+	return dir, nil
+}
+
+// removeEphemeral removes the data dirs created for --ephemeral.
+func (t *Launcher) removeEphemeral() {
+	for _, dir := range t.ephemeralDirs {
+		if err := os.RemoveAll(dir); err != nil {
+			t.logger.Warnf("Failed to remove the ephemeral data dir %s: %s", dir, err)
+		}
+	}
+	t.ephemeralDirs = nil
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"os"
+	"testing"
+)
+
+func TestEphemeralNetworkDir(t *testing.T) {
+	l := &Launcher{homeDir: "/nonexistent", network: "testnet", args: &bootstrapArgs{ephemeral: true}, logger: logrus.NewEntry(logrus.New())}
+
+	if err := l.ensureNetworkDir(); err != nil {
+		t.Fatal(err)
+	}
+	dir := l.networkDir
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, info.IsDir(), true)
+	assert.Equal(t, l.ephemeralDirs, []string{dir})
+
+	l.removeEphemeral()
+	_, err = os.Stat(dir)
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	l.network = "mainnet"
+	err = l.ensureNetworkDir()
+	assert.Equal(t, errors.Is(err, ErrEphemeralMainnet), true)
+	assert.Equal(t, len(l.ephemeralDirs), 0)
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"time"
+)
+
+type EventType string
+
+const (
+	EventInstalled      EventType = "installed"
+	EventDownloadFailed EventType = "download-failed"
+	EventChildCrashed   EventType = "child-crashed"
+	EventProgress       EventType = "progress"
+	// EventArtifactChanged is emitted when a commit is installed again and
+	// its launcher differs from the first install.
+	EventArtifactChanged EventType = "artifact-changed"
+	// EventResourceAlert is emitted when the launcher process tree exceeds
+	// a resource threshold.
+	EventResourceAlert EventType = "resource-alert"
+
+	// Lifecycle events mark the steps of every run. Download progress is
+	// reported as EventProgress with the download phase.
+	EventResolveStarted EventType = "resolve-started"
+	EventChildStarted   EventType = "child-started"
+	EventChildExited    EventType = "child-exited"
+)
+
+// notable reports whether events of type t are worth a notification.
+// Progress and lifecycle events are not.
+func (t EventType) notable() bool {
+	switch t {
+	case EventInstalled, EventDownloadFailed, EventChildCrashed, EventArtifactChanged, EventResourceAlert:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event is a notable step in the launcher lifecycle. Handlers are called
+// synchronously in the order they subscribed.
+type Event struct {
+	Type    EventType `json:"type"`
+	Time    time.Time `json:"time"`
+	Network string    `json:"network,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	Commit  string    `json:"commit,omitempty"`
+	Message string    `json:"message,omitempty"`
+
+	Phase   string  `json:"phase,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	Bytes   int64   `json:"bytes,omitempty"`
+	Total   int64   `json:"total,omitempty"`
+}
+
+type EventHandler func(e Event)
+
+func (e Event) String() string {
+	prefix := "[" + e.Network + "] "
+	switch e.Type {
+	case EventInstalled:
+		return prefix + "New launcher installed: " + e.Branch + "@" + shortCommit(e.Commit)
+	case EventDownloadFailed:
+		return prefix + "Launcher download failed (" + e.Branch + "@" + shortCommit(e.Commit) + "): " + e.Message
+	case EventChildCrashed:
+		return prefix + "Launcher crashed: " + e.Message
+	case EventChildStarted:
+		return prefix + "Launcher started: " + e.Branch + "@" + shortCommit(e.Commit)
+	case EventChildExited:
+		if e.Message == "" {
+			return prefix + "Launcher exited"
+		}
+		return prefix + "Launcher exited: " + e.Message
+	case EventResourceAlert:
+		return prefix + "Launcher " + e.Message
+	case EventArtifactChanged:
+		return prefix + "SECURITY WARNING: " + e.Message
+	default:
+		return prefix + string(e.Type) + ": " + e.Message
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// subscribe adds handler and returns the function removing it again.
+// Removing needs emitMu.
+func (t *Launcher) subscribe(handler EventHandler) func() {
+	i := len(t.handlers)
+	t.handlers = append(t.handlers, handler)
+	return func() {
+		t.handlers[i] = nil
+	}
+}
+
+func (t *Launcher) emit(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Network == "" {
+		e.Network = t.network
+	}
+	if e.Branch == "" {
+		e.Branch = t.branch
+	}
+	// events come from the monitor and the API as well, handlers see them
+	// one at a time
+	t.emitMu.Lock()
+	defer t.emitMu.Unlock()
+	for _, handler := range t.handlers {
+		if handler != nil {
+			handler(e)
+		}
+	}
+}
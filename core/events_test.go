@@ -0,0 +1,39 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	l := &Launcher{network: "testnet", branch: "master"}
+	var handled []EventType
+	unsubscribe := l.Subscribe(func(e Event) {
+		handled = append(handled, e.Type)
+	})
+	events, stop := l.Events(1)
+
+	l.emit(Event{Type: EventResolveStarted})
+	l.emit(Event{Type: EventChildStarted})
+	e := <-events
+	assert.Equal(t, e.Type, EventResolveStarted)
+	assert.Equal(t, e.Network, "testnet")
+	assert.Equal(t, e.Branch, "master")
+	assert.Equal(t, len(events), 0)
+
+	unsubscribe()
+	stop()
+	stop()
+	l.emit(Event{Type: EventChildExited})
+	_, ok := <-events
+	assert.Equal(t, ok, false)
+	assert.Equal(t, handled, []EventType{EventResolveStarted, EventChildStarted})
+}
+
+func TestNotableEvents(t *testing.T) {
+	assert.Equal(t, EventInstalled.notable(), true)
+	assert.Equal(t, EventChildCrashed.notable(), true)
+	assert.Equal(t, EventProgress.notable(), false)
+	assert.Equal(t, EventChildStarted.notable(), false)
+	assert.Equal(t, EventChildExited.notable(), false)
+}
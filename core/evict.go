@@ -0,0 +1,174 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type Cache struct {
+	MaxSize     int    `toml:"max-size" default:"0" comment:"Maximum size in MB of the installed versions and cached archives, least recently used versions are removed first, 0 for no limit"`
+	SharedDir   string `toml:"shared-dir" comment:"Directory to keep the installed versions and cached archives in, shared by all users of its group, e.g. /var/cache/opendex-launcher"`
+	VersionsDir string `toml:"versions-dir" comment:"Directory of the installed versions, which may be read-only, e.g. baked into an image, launcher/versions in the home directory when empty"`
+}
+
+// diskUsage returns the size of the regular files below dirs. Hardlinked
+// files are counted once.
+func diskUsage(dirs ...string) (int64, error) {
+	var total int64
+	seen := make(map[int64][]os.FileInfo)
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			for _, other := range seen[info.Size()] {
+				if os.SameFile(info, other) {
+					return nil
+				}
+			}
+			seen[info.Size()] = append(seen[info.Size()], info)
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// lastUsed returns when version was last launched, falling back to when it
+// was installed.
+func lastUsed(v InstalledVersion) time.Time {
+	m, err := readMetadata(filepath.Dir(v.Path))
+	if err != nil {
+		return v.InstalledAt
+	}
+	if !m.LastUsedAt.IsZero() {
+		return m.LastUsedAt
+	}
+	if !m.InstalledAt.IsZero() {
+		return m.InstalledAt
+	}
+	return v.InstalledAt
+}
+
+// markUsed records that commit is launched now.
+func (t *Launcher) markUsed(commit string) {
+	if t.readOnlyVersions {
+		return
+	}
+	dir := filepath.Dir(t.launcherPath(commit))
+	m, err := readMetadata(dir)
+	if err != nil {
+		t.logger.Debugf("Failed to read metadata of %s: %s", shortCommit(commit), err)
+		return
+	}
+	m.LastUsedAt = time.Now()
+	if err := writeMetadata(dir, m); err != nil {
+		t.logger.Debugf("Failed to write metadata of %s: %s", shortCommit(commit), err)
+	}
+}
+
+// pinned tells whether the branch is the commit or the release tag of the
+// version v, so that every start runs v.
+func (t *Launcher) pinned(v InstalledVersion) bool {
+	if commitRef.MatchString(t.branch) {
+		return v.Commit == t.branch
+	}
+	if !ReleaseRef.MatchString(t.branch) {
+		return false
+	}
+	m, err := readMetadata(filepath.Dir(v.Path))
+	return err == nil && m.Branch == t.branch
+}
+
+// protectReason returns why the version v must not be removed, empty when it
+// may. inUse are the versions running networks use.
+func (t *Launcher) protectReason(v InstalledVersion, inUse map[string]bool) string {
+	dir := filepath.Dir(v.Path)
+	switch {
+	case inUse[v.Commit]:
+		return "a running network uses it"
+	case t.pinned(v):
+		return fmt.Sprintf("branch %s pins it", t.branch)
+	case !extracted(dir):
+		return "it is being installed"
+	case t.config.Cache.SharedDir != "" && !ownedByCurrentUser(dir):
+		return "another user of the shared cache installed it"
+	}
+	return ""
+}
+
+// evictVersions removes the least recently used versions until the versions
+// and the cache fit into cache.max-size. The version keep, which is about to
+// be installed, the running version and those protectReason protects are
+// never removed.
+func (t *Launcher) evictVersions(keep string) error {
+	if t.config.Cache.MaxSize <= 0 {
+		return nil
+	}
+	max := int64(t.config.Cache.MaxSize) * 1024 * 1024
+	cacheDir := t.github.Cache.dir
+
+	versions, err := t.installedVersions()
+	if err != nil {
+		return err
+	}
+	used := make(map[string]time.Time)
+	for _, v := range versions {
+		used[v.Commit] = lastUsed(v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return used[versions[i].Commit].Before(used[versions[j].Commit])
+	})
+	running := t.status().Commit
+	inUse := t.versionsInUse()
+
+	for _, v := range versions {
+		total, err := diskUsage(t.launcherVersionsDir, cacheDir)
+		if err != nil {
+			return err
+		}
+		if total <= max {
+			return nil
+		}
+		if v.Commit == keep || v.Commit == running {
+			continue
+		}
+		if reason := t.protectReason(v, inUse); reason != "" {
+			t.logger.Debugf("Not evicting version %s, %s", shortCommit(v.Commit), reason)
+			continue
+		}
+		t.logger.Infof("Removing least recently used version %s (last used %s), cache exceeds %d MB", shortCommit(v.Commit), used[v.Commit].Format(time.RFC3339), t.config.Cache.MaxSize)
+		if err := t.removeVersion(v.Commit, "least recently used version"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeVersion removes the installed version commit together with the
+// cached files only it used. reason is recorded in the audit log.
+func (t *Launcher) removeVersion(commit string, reason string) error {
+	if err := t.checkWritableVersion(commit); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Dir(t.launcherPath(commit))); err != nil {
+		return err
+	}
+	t.audit(AuditRecord{Action: AuditPurged, Commit: commit, Detail: reason})
+	if err := t.github.Cache.forget(commit); err != nil {
+		return err
+	}
+	return t.github.Cache.pruneBinaries(t.launcherVersionsDir)
+}
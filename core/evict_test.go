@@ -0,0 +1,85 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDiskUsageCountsHardlinksOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a := filepath.Join(dir, "a")
+	if err := ioutil.WriteFile(a, make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "b"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(a, filepath.Join(dir, "c")); err != nil {
+		t.Fatal(err)
+	}
+
+	total, err := diskUsage(dir, filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, total, int64(200))
+}
+
+func TestEvictVersionsKeepsProtected(t *testing.T) {
+	dir, err := ioutil.TempDir("", "evict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := &Launcher{launcherDir: dir, launcherVersionsDir: filepath.Join(dir, "versions"), config: DefaultConfig(), logger: logrus.NewEntry(logrus.New()), branch: "21.01.01"}
+	l.config.Cache.MaxSize = 1
+	l.github = &GithubClient{Cache: newArtifactCache(filepath.Join(dir, "cache"))}
+	old := time.Now().Add(-48 * time.Hour)
+	for _, v := range []struct {
+		commit string
+		branch string
+		step   string
+	}{
+		{"1111111", "master", StepExtracted},
+		{"2222222", "master", StepExtracted},
+		{"3333333", "21.01.01", StepExtracted},
+		{"4444444", "master", StepVerified},
+		{"5555555", "master", StepExtracted},
+	} {
+		versionDir := filepath.Join(l.launcherVersionsDir, v.commit)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(versionDir, "launcher"), make([]byte, 512*1024), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeMetadata(versionDir, &VersionMetadata{Branch: v.branch, Commit: v.commit, Step: v.step, InstalledAt: old, LastUsedAt: old}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// another network runs 2222222
+	data := []byte(`{"pid": ` + strconv.Itoa(os.Getpid()) + `, "commit": "2222222"}`)
+	if err := ioutil.WriteFile(filepath.Join(dir, "testnet.lock"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.evictVersions("5555555"); err != nil {
+		t.Fatal(err)
+	}
+	for commit, kept := range map[string]bool{"1111111": false, "2222222": true, "3333333": true, "4444444": true, "5555555": true} {
+		_, err := os.Stat(filepath.Join(l.launcherVersionsDir, commit))
+		assert.Equal(t, err == nil, kept, commit)
+	}
+}
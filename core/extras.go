@@ -0,0 +1,167 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Artifacts declares auxiliary files installed next to the launcher.
+type Artifacts struct {
+	Extra []string `toml:"extra" comment:"Additional artifacts (branch builds) or release assets (without .zip) installed next to the launcher, e.g. compose templates"`
+
+	MaxArchiveSize   int  `toml:"max-archive-size" default:"512" comment:"Maximum size in MB of a downloaded archive, 0 for no limit"`
+	MaxExtractedSize int  `toml:"max-extracted-size" default:"2048" comment:"Maximum size in MB of the files extracted from an archive, 0 for no limit"`
+	MaxEntries       int  `toml:"max-entries" default:"10000" comment:"Maximum number of entries of an archive, 0 for no limit"`
+	AllowSymlinks    bool `toml:"allow-symlinks" comment:"Extract symbolic links pointing inside the version directory instead of rejecting archives containing them"`
+
+	DownloadAttempts int  `toml:"download-attempts" default:"3" comment:"How often to download the launcher when the archive fails verification or extraction before giving up"`
+	ConfirmSize      int  `toml:"confirm-size" default:"100" comment:"Ask before downloading more than this many MB when running in a terminal, 0 to never ask"`
+	BuildFromSource  bool `toml:"build-from-source" comment:"Build the launcher from the sources of the commit with Go or Docker when CI published no build for it"`
+	DeltaUpdates     bool `toml:"delta-updates" default:"true" comment:"Download a bsdiff patch against the newest installed release instead of the whole archive when the release publishes one"`
+
+	Mirror string `toml:"mirror" comment:"Base URL of an artifact mirror, e.g. an Artifactory or Nexus generic repository, serving the release assets as <mirror>/<tag>/<asset> instead of GitHub"`
+
+	PolicyFile string `toml:"policy-file" comment:"Policy file listing the approved launcher hashes and signing keys, /etc/opendex-launcher/policy.json (%ProgramData%\\opendex-launcher\\policy.json on Windows) when empty and present"`
+}
+
+func (t Artifacts) limits() archiveLimits {
+	return archiveLimits{
+		MaxArchiveSize:   int64(t.MaxArchiveSize) * 1024 * 1024,
+		MaxExtractedSize: int64(t.MaxExtractedSize) * 1024 * 1024,
+		MaxEntries:       t.MaxEntries,
+		AllowSymlinks:    t.AllowSymlinks,
+	}
+}
+
+// getExtraUrls returns the download URLs of the extra artifacts of commit.
+func (t *GithubClient) getExtraUrls(branch string, commit string) (map[string]string, error) {
+	urls := make(map[string]string)
+	if ReleaseRef.MatchString(branch) {
+		for _, name := range t.Extras {
+			urls[name] = fmt.Sprintf("https://github.com/opendexnetwork/opendex-docker/releases/download/%s/%s.zip", branch, name)
+		}
+		return urls, nil
+	}
+
+	t.mu.Lock()
+	runs := t.runs[commit]
+	t.mu.Unlock()
+	if len(runs) == 0 {
+		run, err := t.getLastRunOfBranch(branch, commit)
+		if err != nil {
+			return nil, err
+		}
+		runs = []uint{run.Id}
+	}
+
+	for _, run := range runs {
+		artifacts, err := t.getRunArtifacts(run)
+		if err != nil {
+			return nil, err
+		}
+		for _, artifact := range artifacts {
+			urls[artifact.Name] = artifact.ArchiveDownloadUrl
+			t.estimateSize(artifact.ArchiveDownloadUrl, int64(artifact.SizeInBytes))
+		}
+	}
+	result := make(map[string]string)
+	for _, name := range t.Extras {
+		url, ok := urls[name]
+		if !ok {
+			return nil, fmt.Errorf("artifact %s of commit %s: %w", name, commit, ErrNotFound)
+		}
+		result[name] = url
+	}
+	return result, nil
+}
+
+func (t *GithubClient) downloadExtra(url string, file string, progress ProgressFunc) error {
+	req, err := http.NewRequest("GET", t.mirrored(url), nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	resp, err := t.doWithToken(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.New(string(body))
+	}
+	_, _, err = t.writeBody(resp, file, progress, -1)
+	return err
+}
+
+// installSet runs install, which installs the launcher, and downloads the
+// extra artifacts at urls concurrently, reporting their combined progress. The
+// extras are staged and only moved into commitDir when everything
+// succeeded; otherwise the launcher is removed again so that the version
+// does not count as installed.
+func (t *GithubClient) installSet(urls map[string]string, commitDir string, install func() error) error {
+	staging := filepath.Join(commitDir, ".extras")
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	aggregate := newProgressAggregate(t.Progress)
+	progress := t.Progress
+	t.Progress = aggregate.reporter("launcher")
+	defer func() { t.Progress = progress }()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(urls)+1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs <- install()
+	}()
+	for name, url := range urls {
+		wg.Add(1)
+		go func(name string, url string) {
+			defer wg.Done()
+			if err := t.downloadExtra(url, filepath.Join(staging, name+".zip"), aggregate.reporter(name)); err != nil {
+				errs <- fmt.Errorf("download %s: %w", name, err)
+				return
+			}
+			errs <- nil
+		}(name, url)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err == nil {
+			continue
+		}
+		for _, name := range []string{"launcher", "launcher.exe"} {
+			_ = os.Remove(filepath.Join(commitDir, name))
+		}
+		return err
+	}
+
+	for name := range urls {
+		if err := t.unzipTo(filepath.Join(staging, name+".zip"), filepath.Join(staging, name)); err != nil {
+			return fmt.Errorf("extract %s: %w", name, err)
+		}
+	}
+	for name := range urls {
+		target := filepath.Join(commitDir, name)
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(staging, name), target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FallbackBranch is run instead of a branch CI published no launcher for.
+const FallbackBranch = "master"
+
+// readLine reads a line from r a byte at a time, so that nothing after it
+// is consumed. The input that follows belongs to the launcher.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
+}
+
+// askYesNo asks question on w and reports whether the answer read from r is
+// yes. No answer counts as no.
+func askYesNo(r io.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	answer, err := readLine(r)
+	if err != nil && answer == "" {
+		fmt.Fprintln(w)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// canFallBack reports whether err allows running FallbackBranch instead of
+// the branch. Releases, channels and FallbackBranch itself never fall back.
+func (t *Launcher) canFallBack(err error) bool {
+	if !errors.Is(err, ErrNoArtifact) {
+		return false
+	}
+	return t.branch != FallbackBranch && t.channel == "" && !ReleaseRef.MatchString(t.branch)
+}
+
+// fallBack installs the launcher of FallbackBranch when the branch has no
+// launcher build, with --fallback-to-master or when the user agrees to it.
+// It returns the error of the branch otherwise.
+func (t *Launcher) fallBack(err error) (string, string, error) {
+	if !t.canFallBack(err) {
+		return "", "", err
+	}
+	if !t.args.fallbackToMaster {
+		if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+			t.logger.Errorf("Branch %s has no launcher build, use --fallback-to-master to run the launcher of %s instead", t.branch, FallbackBranch)
+			return "", "", err
+		}
+		question := fmt.Sprintf("Branch %s has no launcher build. Run the launcher of %s instead?", t.branch, FallbackBranch)
+		if !askYesNo(os.Stdin, os.Stdout, question) {
+			return "", "", err
+		}
+	}
+
+	t.logger.Warnf("Branch %s has no launcher build, running the launcher of %s", t.branch, FallbackBranch)
+	t.branch = FallbackBranch
+	commit, err := t.resolve()
+	if err != nil {
+		return "", "", err
+	}
+	launcher, err := t.ensureLauncher(commit)
+	if err != nil {
+		return "", "", err
+	}
+	return launcher, commit, nil
+}
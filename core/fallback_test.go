@@ -0,0 +1,42 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestAskYesNo(t *testing.T) {
+	var out bytes.Buffer
+	assert.Equal(t, askYesNo(strings.NewReader("y\n"), &out, "Continue?"), true)
+	assert.Equal(t, out.String(), "Continue? [y/N] ")
+	assert.Equal(t, askYesNo(strings.NewReader(" Yes \n"), &out, "Continue?"), true)
+	assert.Equal(t, askYesNo(strings.NewReader("\n"), &out, "Continue?"), false)
+	assert.Equal(t, askYesNo(strings.NewReader("no\n"), &out, "Continue?"), false)
+	assert.Equal(t, askYesNo(strings.NewReader(""), &out, "Continue?"), false)
+}
+
+func TestCanFallBack(t *testing.T) {
+	noArtifact := fmt.Errorf("%w for commit abc", ErrNoArtifact)
+
+	l := &Launcher{branch: "feat/foo"}
+	assert.Equal(t, l.canFallBack(noArtifact), true)
+	assert.Equal(t, l.canFallBack(ErrNotFound), false)
+
+	for _, branch := range []string{FallbackBranch, "21.02.10"} {
+		l := &Launcher{branch: branch}
+		assert.Equal(t, l.canFallBack(noArtifact), false, branch)
+	}
+	l = &Launcher{branch: "21.02.10", channel: ChannelStable}
+	assert.Equal(t, l.canFallBack(noArtifact), false)
+}
+
+func TestAskYesNoLeavesInput(t *testing.T) {
+	r := strings.NewReader("y\nfor the launcher\n")
+	assert.Equal(t, askYesNo(r, ioutil.Discard, "Continue?"), true)
+	rest, _ := ioutil.ReadAll(r)
+	assert.Equal(t, string(rest), "for the launcher\n")
+}
@@ -2,6 +2,9 @@ package core
 
 import (
 	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,48 +17,135 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 )
 
 var (
 	ErrNotFound = errors.New("not found")
+	// ErrNoArtifact is returned when CI published no launcher for a commit
+	// and platform.
+	ErrNoArtifact = errors.New("no launcher build")
 
 	ReleaseRef = regexp.MustCompile(`^\d{2}\.\d{2}\.\d{2}.*$`)
 )
 
 type GithubClient struct {
-	Client      *http.Client
-	Logger      *logrus.Entry
-	AccessToken string
+	Client   *http.Client
+	Logger   *logrus.Entry
+	Tokens   *TokenPool
+	Progress ProgressFunc
+	// Retries bounds the time spent waiting for secondary rate limits.
+	Retries *retryBudget
+	// Verify is called with the downloaded archive before it is extracted.
+	// It returns how the signature of the archive was verified, or "" when
+	// it was not.
+	Verify func(ref string, file string) (string, error)
+	// Cache keeps downloaded archives for installing them again offline.
+	Cache *artifactCache
+	// Extras are additional artifacts installed next to the launcher.
+	Extras []string
+	// Limits bound the size of downloaded archives and their content.
+	Limits archiveLimits
+	// Span starts a tracing span and returns the function ending it.
+	Span func(name string) func(err error)
+	// Downloaded is called with the size of every completed download.
+	Downloaded func(n int64)
+	// Previous returns the tag and the archive of the newest installed
+	// release before a tag, to download a patch against. Releases are
+	// always downloaded in whole when it is nil.
+	Previous func(tag string) (string, string, bool)
+	// Confirm is asked once before downloading the launcher and the extra
+	// artifacts of size bytes together. The downloads are declined when it
+	// returns false.
+	Confirm func(size int64) bool
+	// Transferred is called with the summary of every completed launcher
+	// download.
+	Transferred func(s Transfer)
+	// Context is cancelled when waiting for GitHub should stop.
+	Context context.Context
+	// Attempts is how often a damaged launcher archive is downloaded.
+	Attempts int
+	// Archs are the architectures of launcher builds to install, in order
+	// of preference, runtime.GOARCH when empty.
+	Archs []string
+	// MaxSkew is how far the system clock may differ from the Date of
+	// GitHub responses before a warning, 0 to never warn.
+	MaxSkew time.Duration
+	// Mirror is the base URL release assets are downloaded from instead of
+	// GitHub, as <Mirror>/<tag>/<asset>, GitHub when empty.
+	Mirror string
+
+	mu sync.Mutex
+	// runs caches the workflow runs of commits found while resolving them.
+	runs map[string][]uint
+	// arch is the architecture of the launcher build being installed.
+	arch string
+	// noDelta is set once a patched archive turned out to be damaged.
+	noDelta bool
+	// sizes are the sizes of release assets by download URL, as listed by
+	// GitHub.
+	sizes map[string]int64
+	// estimates are the sizes of workflow artifacts by download URL, which
+	// are close to but not exactly the size of their download.
+	estimates map[string]int64
+	// transfer summarizes the last download of downloadFile, nil when the
+	// file was not downloaded.
+	transfer *Transfer
+	// skewWarned is set once the clock skew was warned about.
+	skewWarned bool
 }
 
-func NewGithubClient(accessToken string) *GithubClient {
+func NewGithubClient(accessTokens ...string) *GithubClient {
 	return &GithubClient{
-		Client:      http.DefaultClient,
-		Logger:      logrus.NewEntry(logrus.StandardLogger()).WithField("name", "github"),
-		AccessToken: accessToken,
+		Client:  &http.Client{Transport: newUserAgentTransport(nil)},
+		Logger:  logrus.NewEntry(logrus.StandardLogger()).WithField("name", "github"),
+		Tokens:  NewTokenPool(accessTokens...),
+		Retries: newRetryBudget(60 * time.Second),
 	}
 }
 
+// ResponseError is an error message returned by the GitHub API.
+type ResponseError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+func (e *ResponseError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
 func (t *GithubClient) getResponseError(resp *http.Response) error {
 	var err error
 	if resp.StatusCode != http.StatusOK {
 		var result map[string]interface{}
 		err = json.NewDecoder(resp.Body).Decode(&result)
 		if err != nil {
-			return fmt.Errorf("decode: %w", err)
+			// outages are answered with an HTML page
+			return &ResponseError{StatusCode: resp.StatusCode, Message: resp.Status}
 		}
-		return errors.New(result["message"].(string))
+		message, _ := result["message"].(string)
+		return &ResponseError{StatusCode: resp.StatusCode, Message: message}
 	}
 	return nil
 }
 
 func (t *GithubClient) doGet(url string) ([]byte, error) {
+	return t.doGetAccept(url, "application/vnd.github.v3+json")
+}
+
+func (t *GithubClient) doGetAccept(url string, accept string) ([]byte, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Accept", "application/vnd.github.v3+json")
-	resp, err := t.Client.Do(req)
+	req.Header.Add("Accept", accept)
+	resp, err := t.doWithToken(req)
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +174,30 @@ func (t *GithubClient) GetHeadCommit(branch string) (string, error) {
 	return result["sha"].(string), nil
 }
 
+// GetFile returns the raw content of path in the opendex-docker repository
+// at ref.
+func (t *GithubClient) GetFile(ref string, path string) ([]byte, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/opendexnetwork/opendex-docker/contents/%s?ref=%s", path, ref)
+	return t.doGetAccept(url, "application/vnd.github.v3.raw")
+}
+
+// GetReleaseAsset returns the content of the asset name of release tag.
+func (t *GithubClient) GetReleaseAsset(tag string, name string) ([]byte, error) {
+	url := fmt.Sprintf("https://github.com/opendexnetwork/opendex-docker/releases/download/%s/%s", tag, name)
+	resp, err := t.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status: %s", name, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
 type Artifact struct {
 	Name               string `json:"name"`
 	SizeInBytes        uint   `json:"size_in_bytes"`
@@ -107,18 +221,31 @@ type WorkflowRunList struct {
 	WorkflowRuns []WorkflowRun `json:"workflow_runs"`
 }
 
-func (t *GithubClient) getWorkflowDownloadUrl(runId uint) (string, error) {
+func (t *GithubClient) getRunArtifacts(runId uint) ([]Artifact, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/opendexnetwork/opendex-docker/actions/runs/%d/artifacts", runId)
 	body, err := t.doGet(url)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	var result ArtifactList
-	err = json.Unmarshal(body, &result)
-	for _, artifact := range result.Artifacts {
-		name := fmt.Sprintf("%s-amd64", runtime.GOOS)
-		if name == artifact.Name {
-			return artifact.ArchiveDownloadUrl, nil
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Artifacts, nil
+}
+
+func (t *GithubClient) getWorkflowDownloadUrl(runId uint) (string, error) {
+	artifacts, err := t.getRunArtifacts(runId)
+	if err != nil {
+		return "", err
+	}
+	for _, arch := range t.archs() {
+		for _, artifact := range artifacts {
+			if artifact.Name == fmt.Sprintf("%s-%s", runtime.GOOS, arch) {
+				t.useArch(arch)
+				t.estimateSize(artifact.ArchiveDownloadUrl, int64(artifact.SizeInBytes))
+				return artifact.ArchiveDownloadUrl, nil
+			}
 		}
 	}
 	return "", ErrNotFound
@@ -142,23 +269,69 @@ func (t *GithubClient) getLastRunOfBranch(branch string, commit string) (*Workfl
 	return run, nil
 }
 
+// getRunsOfCommit returns the build workflow runs of commit, newest first.
+func (t *GithubClient) getRunsOfCommit(commit string) ([]WorkflowRun, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/opendexnetwork/opendex-docker/actions/workflows/build.yml/runs?head_sha=%s", commit)
+	body, err := t.doGet(url)
+	if err != nil {
+		return nil, err
+	}
+	var result WorkflowRunList
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.WorkflowRuns, nil
+}
+
+func (t *GithubClient) setRuns(commit string, runs []uint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.runs == nil {
+		t.runs = make(map[string][]uint)
+	}
+	t.runs[commit] = runs
+}
+
+// getCachedDownloadUrl looks for the launcher artifact in the workflow runs
+// already known for commit.
+func (t *GithubClient) getCachedDownloadUrl(commit string) (string, bool) {
+	t.mu.Lock()
+	runs := t.runs[commit]
+	t.mu.Unlock()
+	for _, run := range runs {
+		url, err := t.getWorkflowDownloadUrl(run)
+		if err == nil {
+			return url, true
+		}
+	}
+	return "", false
+}
+
 func (t *GithubClient) getDownloadUrl(branch string, commit string) (string, error) {
 	var url string
 
 	if ReleaseRef.Match([]byte(branch)) {
-		url = fmt.Sprintf("https://github.com/opendexnetwork/opendex-docker/releases/download/%s/launcher-%s-%s.zip", branch, runtime.GOOS, runtime.GOARCH)
+		arch := t.releaseArch(branch)
+		t.useArch(arch)
+		url = fmt.Sprintf("https://github.com/opendexnetwork/opendex-docker/releases/download/%s/%s", branch, releaseAsset(arch))
+	} else if cached, ok := t.getCachedDownloadUrl(commit); ok {
+		url = cached
+		t.Logger.Debugf("Download launcher.zip from %s", url)
 	} else {
 		run, err := t.getLastRunOfBranch(branch, commit)
 		if err != nil {
 			if errors.Is(err, ErrNotFound) {
-				return "", fmt.Errorf("no launcher build for commit %s (The branch \"%s\" does not have a binary launcher)", commit, branch)
+				return "", fmt.Errorf("%w for commit %s (The branch \"%s\" does not have a binary launcher)", ErrNoArtifact, commit, branch)
 			}
 			return "", err
 		}
 
 		url, err = t.getWorkflowDownloadUrl(run.Id)
+		if errors.Is(err, ErrNotFound) {
+			return "", fmt.Errorf("%w for commit %s on %s", ErrNoArtifact, commit, runtime.GOOS)
+		}
 		if err != nil {
-			return "", nil
+			return "", err
 		}
 		t.Logger.Debugf("Download launcher.zip from %s", url)
 	}
@@ -166,24 +339,19 @@ func (t *GithubClient) getDownloadUrl(branch string, commit string) (string, err
 	return url, nil
 }
 
-func (t *GithubClient) ensureCommitDir(commit string, launcherVersionsDir string) (string, error) {
+// ensureCommitDir creates the dir of commit below launcherVersionsDir,
+// together with any missing parents. It also returns the topmost dir it
+// created, to be removed again when the install fails.
+func (t *GithubClient) ensureCommitDir(commit string, launcherVersionsDir string) (string, string, error) {
 	commitDir := filepath.Join(launcherVersionsDir, commit)
-
-	exists, err := utils.FileExists(commitDir)
+	created, err := utils.MkdirAll(commitDir, 0755)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-
-	if ! exists {
-		if err := os.Mkdir(commitDir, 0755); err != nil {
-			return "", err
-		}
-	}
-
-	return commitDir, nil
+	return commitDir, created, nil
 }
 
-func (t *GithubClient) downloadLauncher(url string, commit string, commitDir string) error {
+func (t *GithubClient) downloadLauncher(url string, branch string, commit string, commitDir string) error {
 	var err error
 
 	wd, err := os.Getwd()
@@ -196,41 +364,244 @@ func (t *GithubClient) downloadLauncher(url string, commit string, commitDir str
 	}
 	defer os.Chdir(wd)
 
-	if err = t.downloadFile(url, "launcher.zip"); err != nil {
+	m, err := readMetadata(".")
+	if err != nil {
 		return err
 	}
+	if t.resumable(m, url) {
+		t.Logger.Infof("Resuming the install of %s, its archive is %s already", shortCommit(commit), m.Step)
+	} else {
+		var cached cacheValidators
+		if m.Url == url {
+			cached = cacheValidators{ETag: m.ETag, LastModified: m.LastModified}
+		}
+
+		end := t.span(PhaseDownload)
+		var validators cacheValidators
+		hash := ""
+		if !t.downloadPatch(branch) {
+			validators, err = t.downloadFile(url, "launcher.zip", cached)
+			if err == nil && t.transfer != nil {
+				hash = t.transfer.Sha256
+			}
+		}
+		end(err)
+		if err != nil {
+			return err
+		}
+		m.Branch = branch
+		m.Commit = commit
+		m.Url = url
+		m.ETag = validators.ETag
+		m.LastModified = validators.LastModified
+		m.ArchiveSha256 = hash
+		m.Step = StepDownloaded
+		if err := writeMetadata(".", m); err != nil {
+			return err
+		}
+	}
+
+	if m.Step != StepVerified {
+		if t.Verify != nil {
+			end := t.span("verify")
+			signature, err := t.Verify(branch, filepath.Join(commitDir, "launcher.zip"))
+			end(err)
+			if err != nil {
+				return fmt.Errorf("verify: %w", damaged(err))
+			}
+			m.Signature = signature
+		}
+		if t.Cache != nil {
+			var hash string
+			var err error
+			if m.ArchiveSha256 != "" {
+				hash, err = t.Cache.storeHashed(commit, filepath.Join(commitDir, "launcher.zip"), m.ArchiveSha256)
+			} else {
+				hash, err = t.Cache.store(commit, filepath.Join(commitDir, "launcher.zip"))
+			}
+			if err != nil {
+				t.Logger.Warnf("Failed to cache launcher.zip: %s", err)
+			} else {
+				m.ArchiveSha256 = hash
+			}
+		}
+		m.Step = StepVerified
+		if err := writeMetadata(".", m); err != nil {
+			return err
+		}
+	}
 
-	if err = t.unzip("launcher.zip"); err != nil {
+	end := t.span(PhaseExtract)
+	err = t.extractInstall()
+	end(err)
+	if err != nil {
+		return err
+	}
+	if m.Manifest, err = readManifest("."); err != nil {
 		return err
 	}
 
-	return nil
+	m.InstalledAt = time.Now()
+	m.Step = StepExtracted
+	return writeMetadata(".", m)
 }
 
-func (t *GithubClient) DownloadLatestBinary(branch string, commit string, launcherVersionsDir string) error {
-	var err error
-	var url string
+// installCached installs the archive of commit from the cache, reporting
+// whether it was cached.
+func (t *GithubClient) installCached(branch string, commit string, commitDir string) (bool, error) {
+	blob, hash, ok := t.Cache.lookup(commit)
+	if !ok {
+		return false, nil
+	}
+	t.Logger.Debugf("Install %s from cached archive %s", commit, hash)
 
-	if url, err = t.getDownloadUrl(branch, commit); err != nil {
-		return err
+	wd, err := os.Getwd()
+	if err != nil {
+		return false, err
 	}
-	if Debug {
-		fmt.Printf("Download: %s\n", url)
+	if err := os.Chdir(commitDir); err != nil {
+		return false, err
 	}
+	defer os.Chdir(wd)
 
-	commitDir, err := t.ensureCommitDir(commit, launcherVersionsDir)
+	_ = os.Remove("launcher.zip")
+	if err := linkOrCopy(blob, "launcher.zip"); err != nil {
+		return false, err
+	}
+	end := t.span(PhaseExtract)
+	err = t.extractInstall()
+	end(err)
 	if err != nil {
+		return false, err
+	}
+	manifest, err := readManifest(".")
+	if err != nil {
+		return false, err
+	}
+	m := &VersionMetadata{Branch: branch, Commit: commit, ArchiveSha256: hash, Manifest: manifest, InstalledAt: time.Now(), Step: StepExtracted}
+	return true, writeMetadata(".", m)
+}
+
+func (t *GithubClient) DownloadLatestBinary(branch string, commit string, launcherVersionsDir string) (err error) {
+	var url string
+
+	commitDir, created, err := t.ensureCommitDir(commit, launcherVersionsDir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// an interrupted install is kept to be resumed
+		if err != nil && created != "" && t.context().Err() == nil {
+			if rmErr := os.RemoveAll(created); rmErr != nil {
+				t.Logger.Warnf("Failed to remove %s: %s", created, rmErr)
+			}
+		}
+	}()
+
+	var downloads []string
+	if t.Cache == nil || !t.Cache.has(commit) {
+		if url, err = t.getDownloadUrl(branch, commit); err != nil {
+			return err
+		}
+		if m, err := readMetadata(commitDir); err != nil || !t.resumableIn(commitDir, m, url) {
+			downloads = append(downloads, url)
+		}
+	}
+	var extras map[string]string
+	if len(t.Extras) > 0 {
+		if extras, err = t.getExtraUrls(branch, commit); err != nil {
+			return fmt.Errorf("get extra artifacts: %w", err)
+		}
+		for _, extra := range extras {
+			downloads = append(downloads, extra)
+		}
+	}
+	// asked once here, the downloads run concurrently
+	if err := t.confirmDownloads(downloads); err != nil {
 		return err
 	}
 
-	if err = t.downloadLauncher(url, commit, commitDir); err != nil {
+	install := func() error {
+		if t.Cache != nil {
+			installed, err := t.installCached(branch, commit, commitDir)
+			if err != nil {
+				t.Logger.Warnf("Failed to install %s from the cache: %s", commit, err)
+			} else if installed {
+				return nil
+			}
+		}
+
+		if url == "" {
+			if url, err = t.getDownloadUrl(branch, commit); err != nil {
+				return err
+			}
+		}
+		if Debug {
+			fmt.Printf("Download: %s\n", url)
+		}
+
+		err := t.downloadRetrying(url, branch, commit, commitDir)
+		if errors.Is(err, ErrNotFound) && ReleaseRef.MatchString(branch) {
+			return fmt.Errorf("%w for release %s on %s/%s", ErrNoArtifact, branch, runtime.GOOS, strings.Join(t.archs(), ", "))
+		}
 		return err
 	}
 
-	return nil
+	if len(t.Extras) == 0 {
+		return install()
+	}
+	return t.installSet(extras, commitDir, install)
+}
+
+func (t *GithubClient) span(name string) func(err error) {
+	if t.Span == nil {
+		return func(error) {}
+	}
+	return t.Span(name)
 }
 
-func (t *GithubClient) unzip(file string) error {
+// extractStaging is the directory of the version dir launcher.zip is
+// extracted into before its files are moved into place.
+const extractStaging = ".extract"
+
+// extractInstall extracts launcher.zip of the working directory into
+// extractStaging and moves the files into place, the launcher binary last,
+// so an interrupted extraction never leaves a launcher that looks installed.
+func (t *GithubClient) extractInstall() error {
+	if err := os.RemoveAll(extractStaging); err != nil {
+		return err
+	}
+	defer os.RemoveAll(extractStaging)
+	if err := t.unzipTo("launcher.zip", extractStaging); err != nil {
+		return damaged(err)
+	}
+	if err := normalizeLayout(extractStaging, t.selectedArch()); err != nil {
+		return err
+	}
+	entries, err := ioutil.ReadDir(extractStaging)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		switch name {
+		case launcherBinary(), "launcher.zip", MetadataFilename, ".extras":
+			continue
+		}
+		// left behind by an interrupted extraction
+		if err := os.RemoveAll(name); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(extractStaging, name), name); err != nil {
+			return err
+		}
+	}
+	return os.Rename(filepath.Join(extractStaging, launcherBinary()), launcherBinary())
+}
+
+// unzipTo extracts file into dir, or into the working directory when dir is
+// empty.
+func (t *GithubClient) unzipTo(file string, dir string) error {
 	var filenames []string
 
 	r, err := zip.OpenReader(file)
@@ -239,13 +610,40 @@ func (t *GithubClient) unzip(file string) error {
 	}
 	defer r.Close()
 
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	var total, current int64
+	var declared uint64
+	for _, f := range r.File {
+		declared += f.UncompressedSize64
+	}
+	if err := t.checkArchive(info.Size(), len(r.File), declared); err != nil {
+		return err
+	}
+	total = int64(declared)
+
 	for _, f := range r.File {
 		t.Logger.Debugf("Extracting %s", f.Name)
 
-		fpath := f.Name
+		fpath, ok := safeJoin(dir, f.Name)
+		if !ok {
+			return t.limitExceeded("illegal path %s", f.Name)
+		}
 
 		filenames = append(filenames, fpath)
 
+		if err := t.checkEntry(dir, f.Name, fpath, f.Mode()); err != nil {
+			return err
+		}
+		if f.Mode()&os.ModeSymlink != 0 {
+			if err := t.extractSymlink(f, dir, fpath); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if f.FileInfo().IsDir() {
 			// Make Folder
 			os.MkdirAll(fpath, os.ModePerm)
@@ -267,7 +665,12 @@ func (t *GithubClient) unzip(file string) error {
 			return fmt.Errorf("open: %w", err)
 		}
 
-		_, err = io.Copy(outFile, rc)
+		// The declared sizes may lie, count the bytes actually written
+		var reader io.Reader = rc
+		if t.Limits.MaxExtractedSize > 0 {
+			reader = io.LimitReader(rc, t.Limits.MaxExtractedSize-current+1)
+		}
+		n, err := io.Copy(outFile, reader)
 
 		// Close the file without defer to close before next iteration of loop
 		_ = outFile.Close()
@@ -276,40 +679,194 @@ func (t *GithubClient) unzip(file string) error {
 		if err != nil {
 			return fmt.Errorf("copy: %w", err)
 		}
+
+		current += n
+		if t.Limits.MaxExtractedSize > 0 && current > t.Limits.MaxExtractedSize {
+			return t.limitExceeded("archive extracts to more than %d bytes", t.Limits.MaxExtractedSize)
+		}
+		if t.Progress != nil {
+			t.Progress(PhaseExtract, current, total)
+		}
 	}
 	return nil
 }
 
-func (t *GithubClient) downloadFile(url string, file string) error {
-	req, err := http.NewRequest("GET", url, nil)
+// isGitHubHost tells whether the access tokens may be sent to host. Other
+// hosts, like mirrors or the storage GitHub redirects downloads to, never
+// see them.
+func isGitHubHost(host string) bool {
+	switch strings.ToLower(host) {
+	case "api.github.com", "github.com":
+		return true
+	}
+	return false
+}
+
+// doWithToken sends req authorized with a token of the pool, rotating to
+// the next token when the current one is rate limited. Requests to hosts
+// other than GitHub are sent without a token.
+func (t *GithubClient) doWithToken(req *http.Request) (*http.Response, error) {
+	if !isGitHubHost(req.URL.Host) {
+		return t.do(req)
+	}
+	for {
+		token := t.Tokens.Token()
+		if token != "" {
+			req.Header.Set("Authorization", "token "+token)
+		}
+		resp, err := t.do(req)
+		if err != nil {
+			return nil, err
+		}
+		reset, limited := rateLimitReset(resp)
+		if !limited || token == "" || !t.Tokens.RateLimited(token, reset) {
+			return resp, nil
+		}
+		t.Logger.Debugf("Token rate limited until %s, rotating", reset)
+		_ = resp.Body.Close()
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// cacheValidators identify the version of a downloaded file.
+type cacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+func (v cacheValidators) empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// downloadFile downloads url to file. When file exists and cached holds the
+// validators of a previous download, the server is asked to only send the
+// file if it changed. The file is written to a temporary file first so an
+// interrupted download never leaves a truncated file behind.
+func (t *GithubClient) downloadFile(url string, file string, cached cacheValidators) (cacheValidators, error) {
+	req, err := http.NewRequest("GET", t.mirrored(url), nil)
 	if err != nil {
-		return fmt.Errorf("new request: %w", err)
+		return cached, fmt.Errorf("new request: %w", err)
+	}
+
+	if !cached.empty() {
+		exists, err := utils.FileExists(file)
+		if err != nil {
+			return cached, err
+		}
+		if exists {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
 	}
-	req.Header.Add("Authorization", "token "+t.AccessToken)
-	resp, err := t.Client.Do(req)
+
+	t.transfer = nil
+	start := time.Now()
+	resp, err := t.doWithToken(req)
 	if err != nil {
-		return fmt.Errorf("do request: %w", err)
+		return cached, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		t.Logger.Debugf("Reuse cached %s", file)
+		return cached, nil
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return cached, fmt.Errorf("%s: %w", url, ErrNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("read all: %w", err)
+			return cached, fmt.Errorf("read all: %w", err)
 		}
-		return errors.New(string(body))
+		return cached, errors.New(string(body))
 	}
 
-	out, err := os.Create(file)
+	validators := cacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	n, hash, err := t.writeBody(resp, file, t.Progress, t.expectedSize(url))
+	if err != nil {
+		return cached, err
+	}
+	mirror := req.URL.Host
+	if resp.Request != nil {
+		mirror = resp.Request.URL.Host
+	}
+	now := time.Now()
+	s := newTransfer(mirror, n, hash, now.Sub(start), now)
+	s.Url = url
+	t.transfer = &s
+	return validators, nil
+}
+
+// writeBody writes the body of resp to file and returns its size and hash,
+// computed while it is written. expected is the size the body must have, -1
+// when it is unknown; a body of another size is aborted as soon as that is
+// certain.
+func (t *GithubClient) writeBody(resp *http.Response, file string, progress ProgressFunc, expected int64) (int64, string, error) {
+	tmp := file + ".part"
+	out, err := os.Create(tmp)
 	if err != nil {
-		return fmt.Errorf("create: %w", err)
+		return 0, "", fmt.Errorf("create: %w", err)
 	}
+	defer os.Remove(tmp)
 	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
+	max := t.Limits.MaxArchiveSize
+	if max > 0 && resp.ContentLength > max {
+		return 0, "", t.limitExceeded("download has %d bytes, the limit is %d", resp.ContentLength, max)
+	}
+	if expected >= 0 && resp.ContentLength >= 0 && resp.ContentLength != expected {
+		return 0, "", sizeMismatch(resp.ContentLength, expected)
+	}
+	size := resp.ContentLength
+	if size < 0 {
+		size = expected
+	}
+	var r io.Reader = resp.Body
+	if max > 0 {
+		r = io.LimitReader(r, max+1)
+	}
+	if expected >= 0 {
+		r = io.LimitReader(r, expected+1)
+	}
+	body := &progressReader{
+		r:        r,
+		phase:    PhaseDownload,
+		total:    resp.ContentLength,
+		progress: progress,
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), body)
 	if err != nil {
-		return fmt.Errorf("copy: %w", err)
+		return n, "", fmt.Errorf("copy: %w", err)
+	}
+	if max > 0 && n > max {
+		return n, "", t.limitExceeded("download exceeds %d bytes", max)
+	}
+	if expected >= 0 && n != expected {
+		return n, "", sizeMismatch(n, expected)
+	}
+	if t.Downloaded != nil {
+		t.Downloaded(n)
+	}
+	if err := out.Close(); err != nil {
+		return n, "", fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return n, "", fmt.Errorf("rename: %w", err)
 	}
 
-	return nil
+	return n, hex.EncodeToString(h.Sum(nil)), nil
 }
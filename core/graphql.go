@@ -0,0 +1,135 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+)
+
+// Resolver resolves a branch (or release tag) of opendex-docker to its head
+// commit.
+type Resolver interface {
+	GetHeadCommit(branch string) (string, error)
+}
+
+const resolveQuery = `query($ref: String!) {
+  repository(owner: "opendexnetwork", name: "opendex-docker") {
+    object(expression: $ref) {
+      ...commit
+      ... on Tag { target { ...commit } }
+    }
+  }
+}
+
+fragment commit on Commit {
+  oid
+  checkSuites(last: 20) {
+    nodes { workflowRun { databaseId } }
+  }
+}`
+
+type graphqlCommit struct {
+	Oid         string `json:"oid"`
+	CheckSuites struct {
+		Nodes []struct {
+			WorkflowRun *struct {
+				DatabaseId uint `json:"databaseId"`
+			} `json:"workflowRun"`
+		} `json:"nodes"`
+	} `json:"checkSuites"`
+}
+
+type graphqlResolveResult struct {
+	Data struct {
+		Repository struct {
+			Object *struct {
+				graphqlCommit
+				Target *graphqlCommit `json:"target"`
+			} `json:"object"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphqlResolver resolves the head commit and the workflow runs of the
+// commit in a single GraphQL query, saving the REST lookup of the last run
+// when the launcher is downloaded. GraphQL requires authentication, so it
+// falls back to REST without a token or when the query fails.
+type graphqlResolver struct {
+	client *GithubClient
+}
+
+func (t *graphqlResolver) GetHeadCommit(branch string) (string, error) {
+	if t.client.Tokens.Len() == 0 {
+		return t.client.GetHeadCommit(branch)
+	}
+	commit, err := t.query(branch)
+	if err != nil {
+		t.client.Logger.Debugf("GraphQL resolution failed, falling back to REST: %s", err)
+		return t.client.GetHeadCommit(branch)
+	}
+	return commit, nil
+}
+
+func (t *graphqlResolver) query(branch string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     resolveQuery,
+		"variables": map[string]string{"ref": branch},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("POST", "https://api.github.com/graphql", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.doWithToken(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var result graphqlResolveResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return "", errors.New(result.Errors[0].Message)
+	}
+	object := result.Data.Repository.Object
+	if object == nil {
+		return "", ErrNotFound
+	}
+	commit := &object.graphqlCommit
+	if object.Target != nil {
+		commit = object.Target
+	}
+	if commit.Oid == "" {
+		return "", ErrNotFound
+	}
+
+	var runs []uint
+	for _, node := range commit.CheckSuites.Nodes {
+		if node.WorkflowRun != nil {
+			runs = append(runs, node.WorkflowRun.DatabaseId)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i] > runs[j] })
+	t.client.setRuns(commit.Oid, runs)
+
+	return commit.Oid, nil
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrBadHeader = errors.New("bad header")
+
+// parseHeaders parses the http.headers of the config, "<host> <Name>: <value>"
+// each, into the headers to send by host.
+func parseHeaders(entries []string) (map[string]http.Header, error) {
+	headers := make(map[string]http.Header)
+	for _, entry := range entries {
+		fields := strings.SplitN(strings.TrimSpace(entry), " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: %q is not <host> <Name>: <value>", ErrBadHeader, entry)
+		}
+		host := strings.ToLower(fields[0])
+		parts := strings.SplitN(fields[1], ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.ContainsAny(name, " \t") {
+			// the value is a secret, never repeat it
+			return nil, fmt.Errorf("%w: header of %s is not <Name>: <value>", ErrBadHeader, host)
+		}
+		if headers[host] == nil {
+			headers[host] = make(http.Header)
+		}
+		headers[host].Add(name, strings.TrimSpace(parts[1]))
+	}
+	return headers, nil
+}
+
+// headerTransport adds the configured headers to https requests to their
+// host. Redirects to other hosts and plain http never carry them.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers, ok := t.headers[strings.ToLower(req.URL.Hostname())]
+	if !ok || req.URL.Scheme != "https" {
+		return t.base.RoundTrip(req)
+	}
+	// a RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	return t.base.RoundTrip(req)
+}
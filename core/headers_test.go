@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := parseHeaders([]string{
+		"Artifacts.example.com X-JFrog-Art-Api: key",
+		"nexus.example.com Authorization: Basic dXNlcjpwYXNz",
+		"artifacts.example.com X-Team: node",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(headers), 2)
+	assert.Equal(t, headers["artifacts.example.com"].Get("X-Jfrog-Art-Api"), "key")
+	assert.Equal(t, headers["artifacts.example.com"].Get("X-Team"), "node")
+	assert.Equal(t, headers["nexus.example.com"].Get("Authorization"), "Basic dXNlcjpwYXNz")
+
+	for _, entry := range []string{"artifacts.example.com", "artifacts.example.com X-JFrog-Art-Api key", "artifacts.example.com : key"} {
+		_, err := parseHeaders([]string{entry})
+		assert.Equal(t, errors.Is(err, ErrBadHeader), true, entry)
+		assert.Equal(t, strings.Contains(err.Error(), "key"), false, entry)
+	}
+}
+
+func TestHeaderTransport(t *testing.T) {
+	var sent []string
+	transport := &headerTransport{
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, req.Header.Get("X-JFrog-Art-Api"))
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+		}),
+		headers: map[string]http.Header{"artifacts.example.com": {"X-Jfrog-Art-Api": {"key"}}},
+	}
+	client := &http.Client{Transport: transport}
+	for _, url := range []string{"https://Artifacts.example.com:8443/launcher.zip", "http://artifacts.example.com/launcher.zip", "https://api.github.com/"} {
+		req, _ := http.NewRequest("GET", url, nil)
+		if _, err := client.Do(req); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, req.Header.Get("X-JFrog-Art-Api"), "")
+	}
+	assert.Equal(t, sent, []string{"key", "", ""})
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+type Hooks struct {
+	PreStart string `toml:"pre-start" comment:"Command to run before the launcher starts, a failure aborts the start"`
+	PostExit string `toml:"post-exit" comment:"Command to run after the launcher exited"`
+}
+
+// hookEnv returns the environment passed to hook commands. Hooks run in the
+// home dir so relative script paths resolve next to the config file.
+func (t *Launcher) hookEnv(launcher string, commit string) []string {
+	return append(childEnviron(),
+		"OPENDEX_NETWORK="+t.network,
+		"OPENDEX_NETWORK_DIR="+t.networkDir,
+		"OPENDEX_BRANCH="+t.branch,
+		"OPENDEX_COMMIT="+commit,
+		"OPENDEX_LAUNCHER="+launcher,
+		"OPENDEX_HOME_DIR="+t.homeDir,
+	)
+}
+
+func (t *Launcher) runHook(name string, command string, env []string) error {
+	if command == "" {
+		return nil
+	}
+	if Debug {
+		fmt.Printf("Hook %s: %s\n", name, command)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Dir = t.homeDir
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// exitCode returns the exit code of a finished process or -1 when it could
+// not be started at all.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+type infoField struct {
+	Name  string
+	Value string
+}
+
+// versionInfo describes the installed version v with its metadata m. known is
+// the hash pinned for the commit of v, active whether v is the most recently
+// launched version and size the disk usage of its directory.
+func versionInfo(v InstalledVersion, m *VersionMetadata, known string, active bool, size int64) []infoField {
+	branch, tag := m.Branch, ""
+	if ReleaseRef.MatchString(m.Branch) {
+		branch, tag = "", m.Branch
+	}
+	version := ""
+	if m.Manifest != nil {
+		version = m.Manifest.Version
+	}
+	installedAt := v.InstalledAt
+	if !m.InstalledAt.IsZero() {
+		installedAt = m.InstalledAt
+	}
+	lastUsed := ""
+	if !m.LastUsedAt.IsZero() {
+		lastUsed = m.LastUsedAt.Format(time.RFC3339)
+	}
+	signature := "not verified"
+	if m.Signature != "" {
+		signature = fmt.Sprintf("verified with cosign (%s)", m.Signature)
+	}
+	pinned := "no"
+	if known != "" {
+		pinned = "yes"
+	}
+	activeValue := "no"
+	if active {
+		activeValue = "yes"
+	}
+
+	return []infoField{
+		{"commit", v.Commit},
+		{"branch", orDash(branch)},
+		{"tag", orDash(tag)},
+		{"version", orDash(version)},
+		{"installed", installedAt.Format(time.RFC3339)},
+		{"last used", orDash(lastUsed)},
+		{"source", orDash(m.Url)},
+		{"sha256", orDash(m.Sha256)},
+		{"archive sha256", orDash(m.ArchiveSha256)},
+		{"signature", signature},
+		{"size", fmt.Sprintf("%.1f MB", float64(size)/1024/1024)},
+		{"hash pinned", pinned},
+		{"active", activeValue},
+		{"path", v.Path},
+	}
+}
+
+func (t *Launcher) runInfo(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: info <installed commit>")
+	}
+	v, err := t.findVersion(args[0])
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(v.Path)
+	m, err := readMetadata(dir)
+	if err != nil {
+		return err
+	}
+	known, err := readKnownHashes(filepath.Join(t.launcherDir, KnownHashesFilename))
+	if err != nil {
+		return err
+	}
+	active, err := t.activeVersion()
+	if err != nil {
+		return err
+	}
+	size, err := diskUsage(dir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, f := range versionInfo(v, m, known[pinKey(v.Commit, t.versionArch(m))], v.Commit == active, size) {
+		fmt.Fprintf(w, "%s:\t%s\n", f.Name, f.Value)
+	}
+	return w.Flush()
+}
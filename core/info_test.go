@@ -0,0 +1,39 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func infoValue(fields []infoField, name string) string {
+	for _, f := range fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func TestVersionInfo(t *testing.T) {
+	installedAt := time.Date(2021, 2, 10, 12, 0, 0, 0, time.UTC)
+	v := InstalledVersion{Commit: "abcdef", Path: "/versions/abcdef/launcher"}
+
+	m := &VersionMetadata{Branch: "21.02.10", Signature: CosignKeyless, InstalledAt: installedAt, Manifest: &ArtifactManifest{Version: "1.2.0"}}
+	fields := versionInfo(v, m, "1234", true, 3*1024*1024)
+	assert.Equal(t, infoValue(fields, "branch"), "-")
+	assert.Equal(t, infoValue(fields, "tag"), "21.02.10")
+	assert.Equal(t, infoValue(fields, "version"), "1.2.0")
+	assert.Equal(t, infoValue(fields, "installed"), "2021-02-10T12:00:00Z")
+	assert.Equal(t, infoValue(fields, "signature"), "verified with cosign (keyless)")
+	assert.Equal(t, infoValue(fields, "size"), "3.0 MB")
+	assert.Equal(t, infoValue(fields, "hash pinned"), "yes")
+	assert.Equal(t, infoValue(fields, "active"), "yes")
+
+	fields = versionInfo(v, &VersionMetadata{Branch: "master"}, "", false, 0)
+	assert.Equal(t, infoValue(fields, "branch"), "master")
+	assert.Equal(t, infoValue(fields, "tag"), "-")
+	assert.Equal(t, infoValue(fields, "signature"), "not verified")
+	assert.Equal(t, infoValue(fields, "hash pinned"), "no")
+	assert.Equal(t, infoValue(fields, "active"), "no")
+}
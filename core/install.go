@@ -0,0 +1,89 @@
+package core
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// installRef resolves ref, a release tag or (a prefix of) a commit, to the
+// branch the launcher of it is downloaded from and its full commit.
+func (t *Launcher) installRef(ref string) (string, string, error) {
+	commit, err := t.github.GetHeadCommit(ref)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	if ReleaseRef.MatchString(ref) {
+		return ref, commit, nil
+	}
+
+	runs, err := t.github.getRunsOfCommit(commit)
+	if err != nil {
+		return "", "", fmt.Errorf("get workflow runs: %w", err)
+	}
+	if len(runs) == 0 {
+		if !t.config.Artifacts.BuildFromSource {
+			return "", "", fmt.Errorf("%w for commit %s", ErrNoArtifact, commit)
+		}
+		return commit, commit, nil
+	}
+	ids := make([]uint, len(runs))
+	for i, run := range runs {
+		ids[i] = run.Id
+	}
+	t.github.setRuns(commit, ids)
+	return runs[0].HeadBranch, commit, nil
+}
+
+// runInstall downloads and verifies the launcher of a release tag or commit
+// without running it, so that a later start with it does not wait for the
+// download.
+func (t *Launcher) runInstall(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: install <release tag or commit>")
+	}
+	branch, commit, err := t.installRef(args[0])
+	if err != nil {
+		return err
+	}
+
+	t.branch = branch
+	if _, err := t.ensureLauncher(commit); err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s@%s\n", branch, shortCommit(commit))
+	return nil
+}
+
+// runPull resolves the branch and installs its launcher like a start does,
+// but never runs it, e.g. to provision machines, bake CI images or fetch
+// updates ahead of time. With --images or docker.pre-pull the docker images
+// of the network are pulled as well.
+func (t *Launcher) runPull(args []string) error {
+	fs := flag.NewFlagSet("pull", flag.ContinueOnError)
+	images := fs.Bool("images", false, "also pull the docker images of the network")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	commit, err := t.resolve()
+	if err != nil {
+		return err
+	}
+	if _, err := t.ensureLauncher(commit); err != nil {
+		if _, commit, err = t.fallBack(err); err != nil {
+			return err
+		}
+	}
+	if *images || t.config.Docker.PrePull {
+		if t.network == "" {
+			return ErrNetworkEmpty
+		}
+		t.setupBackend()
+		if err := t.prePullImages(commit); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Pulled %s\n", describeRef(t.branch, commit))
+	return nil
+}
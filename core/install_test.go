@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInstallRef(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	runs := `{"total_count": 1, "workflow_runs": [{"id": 42, "head_branch": "feat/foo", "head_sha": "` + commit + `"}]}`
+
+	c := NewGithubClient()
+	c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body := `{"sha": "` + commit + `"}`
+		if strings.HasSuffix(req.URL.Path, "/runs") {
+			body = runs
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body)), Header: make(http.Header)}, nil
+	})}
+	l := &Launcher{github: c, config: DefaultConfig()}
+
+	branch, resolved, err := l.installRef("21.02.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, branch, "21.02.10")
+	assert.Equal(t, resolved, commit)
+
+	branch, resolved, err = l.installRef("0123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, branch, "feat/foo")
+	assert.Equal(t, resolved, commit)
+	assert.Equal(t, c.runs[commit], []uint{42})
+
+	runs = `{"total_count": 0, "workflow_runs": []}`
+	_, _, err = l.installRef("0123456")
+	assert.Equal(t, errors.Is(err, ErrNoArtifact), true)
+
+	l.config.Artifacts.BuildFromSource = true
+	branch, _, err = l.installRef("0123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, branch, commit)
+}
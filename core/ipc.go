@@ -0,0 +1,167 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+type IPC struct {
+	Enabled bool `toml:"enabled" default:"false" comment:"Serve launcher state and events over a unix socket (a named pipe on Windows) for the desktop application"`
+}
+
+// ipcListener accepts connections on a unix socket or a Windows named pipe.
+type ipcListener interface {
+	Accept() (io.ReadWriteCloser, error)
+	Close() error
+}
+
+// ipcRequest is a single line sent by an IPC client, e.g.
+//
+//	{"id": 1, "method": "status"}
+//
+// Supported methods are status, versions and subscribe. After subscribing
+// the client receives every launcher event as {"event": {...}}.
+type ipcRequest struct {
+	Id     int    `json:"id"`
+	Method string `json:"method"`
+}
+
+type ipcMessage struct {
+	Id     int         `json:"id,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Event  *Event      `json:"event,omitempty"`
+}
+
+type ipcConn struct {
+	rwc io.ReadWriteCloser
+	out chan ipcMessage
+}
+
+type ipcServer struct {
+	launcher *Launcher
+	logger   *logrus.Entry
+
+	mu          sync.Mutex
+	subscribers map[*ipcConn]struct{}
+}
+
+func (t *Launcher) ipcPath() string {
+	if runtime.GOOS == "windows" {
+		return `\\.\pipe\opendex-launcher-` + t.network
+	}
+	return filepath.Join(t.launcherDir, t.network+".sock")
+}
+
+func (t *Launcher) startIPC() error {
+	path := t.ipcPath()
+	ln, err := listenIPC(path)
+	if err != nil {
+		return fmt.Errorf("ipc: %w", err)
+	}
+	s := &ipcServer{
+		launcher:    t,
+		logger:      logrus.NewEntry(logrus.StandardLogger()).WithField("name", "ipc"),
+		subscribers: make(map[*ipcConn]struct{}),
+	}
+	t.subscribe(s.broadcast)
+	go s.serve(ln)
+	if Debug {
+		fmt.Printf("IPC: %s\n", path)
+	}
+	return nil
+}
+
+func (t *ipcServer) serve(ln ipcListener) {
+	defer ln.Close()
+	for {
+		rwc, err := ln.Accept()
+		if err != nil {
+			t.logger.Errorf("Accept: %s", err)
+			return
+		}
+		go t.handle(rwc)
+	}
+}
+
+func (t *ipcServer) handle(rwc io.ReadWriteCloser) {
+	c := &ipcConn{rwc: rwc, out: make(chan ipcMessage, 64)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		encoder := json.NewEncoder(rwc)
+		for msg := range c.out {
+			if err := encoder.Encode(msg); err != nil {
+				t.logger.Debugf("Write: %s", err)
+				return
+			}
+		}
+	}()
+
+	decoder := json.NewDecoder(rwc)
+read:
+	for {
+		var req ipcRequest
+		if err := decoder.Decode(&req); err != nil {
+			if err != io.EOF {
+				t.logger.Debugf("Read: %s", err)
+			}
+			break
+		}
+		// the writer stops on a write error, replies must not wait for it
+		select {
+		case c.out <- t.call(c, req):
+		case <-done:
+			break read
+		}
+	}
+
+	t.mu.Lock()
+	delete(t.subscribers, c)
+	close(c.out)
+	t.mu.Unlock()
+	<-done
+	_ = rwc.Close()
+}
+
+func (t *ipcServer) call(c *ipcConn, req ipcRequest) ipcMessage {
+	msg := ipcMessage{Id: req.Id}
+	switch req.Method {
+	case "status":
+		msg.Result = t.launcher.status()
+	case "versions":
+		versions, err := t.launcher.installedVersions()
+		if err != nil {
+			msg.Error = err.Error()
+		} else {
+			msg.Result = versions
+		}
+	case "subscribe":
+		t.mu.Lock()
+		t.subscribers[c] = struct{}{}
+		t.mu.Unlock()
+		msg.Result = true
+	default:
+		msg.Error = fmt.Sprintf("unknown method: %s", req.Method)
+	}
+	return msg
+}
+
+// broadcast sends e to all subscribers. Slow subscribers miss events rather
+// than blocking the launcher.
+func (t *ipcServer) broadcast(e Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for c := range t.subscribers {
+		select {
+		case c.out <- ipcMessage{Event: &e}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type brokenWriter struct {
+	io.Reader
+}
+
+func (t brokenWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func (t brokenWriter) Close() error {
+	return nil
+}
+
+func TestIpcHandleStopsWhenWritesFail(t *testing.T) {
+	s := &ipcServer{logger: logrus.NewEntry(logrus.New()), subscribers: make(map[*ipcConn]struct{})}
+	requests := strings.Repeat(`{"id": 1, "method": "unknown"}`+"\n", 200)
+
+	done := make(chan struct{})
+	go func() {
+		s.handle(brokenWriter{strings.NewReader(requests)})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handle blocked on replies nobody writes")
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"io"
+	"net"
+	"os"
+)
+
+type unixListener struct {
+	net.Listener
+}
+
+func (t *unixListener) Accept() (io.ReadWriteCloser, error) {
+	return t.Listener.Accept()
+}
+
+func listenIPC(path string) (ipcListener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return &unixListener{ln}, nil
+}
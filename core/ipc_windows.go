@@ -0,0 +1,65 @@
+package core
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	pipeAccessDuplex        = 0x3
+	pipeTypeByte            = 0x0
+	pipeReadmodeByte        = 0x0
+	pipeWait                = 0x0
+	pipeRejectRemoteClients = 0x8
+	pipeUnlimitedInstances  = 255
+
+	errorPipeConnected syscall.Errno = 535
+)
+
+var (
+	modkernel32          = syscall.NewLazyDLL("kernel32.dll")
+	procCreateNamedPipeW = modkernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe = modkernel32.NewProc("ConnectNamedPipe")
+)
+
+// pipeListener creates a new instance of the named pipe for every client.
+type pipeListener struct {
+	name string
+}
+
+func (t *pipeListener) Accept() (io.ReadWriteCloser, error) {
+	name, err := syscall.UTF16PtrFromString(t.name)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := procCreateNamedPipeW.Call(
+		uintptr(unsafe.Pointer(name)),
+		pipeAccessDuplex,
+		pipeTypeByte|pipeReadmodeByte|pipeWait|pipeRejectRemoteClients,
+		pipeUnlimitedInstances,
+		4096,
+		4096,
+		0,
+		0,
+	)
+	h := syscall.Handle(r)
+	if h == syscall.InvalidHandle {
+		return nil, os.NewSyscallError("CreateNamedPipe", err)
+	}
+	r, _, err = procConnectNamedPipe.Call(uintptr(h), 0)
+	if r == 0 && err != errorPipeConnected {
+		_ = syscall.CloseHandle(h)
+		return nil, os.NewSyscallError("ConnectNamedPipe", err)
+	}
+	return os.NewFile(uintptr(h), t.name), nil
+}
+
+func (t *pipeListener) Close() error {
+	return nil
+}
+
+func listenIPC(path string) (ipcListener, error) {
+	return &pipeListener{name: path}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+// attachJob is only needed on Windows, where a console process does not go
+// away with the process which started it.
+func attachJob(pid int) error {
+	return nil
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"fmt"
+	"golang.org/x/sys/windows"
+	"sync"
+	"unsafe"
+)
+
+var (
+	jobOnce sync.Once
+	job     windows.Handle
+	jobErr  error
+)
+
+// killOnCloseJob returns the Job Object of this process. Windows closes the
+// handle when opendex-launcher exits in any way, and the kill-on-close limit
+// then terminates every process assigned to the job.
+func killOnCloseJob() (windows.Handle, error) {
+	jobOnce.Do(func() {
+		job, jobErr = windows.CreateJobObject(nil, nil)
+		if jobErr != nil {
+			return
+		}
+		info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{}
+		info.BasicLimitInformation.LimitFlags = windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE
+		_, jobErr = windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation, uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info)))
+	})
+	return job, jobErr
+}
+
+// attachJob assigns the launcher process pid to the kill-on-close job so
+// that it dies with opendex-launcher, like a Unix process group. Processes
+// it starts afterwards belong to the job as well.
+func attachJob(pid int) error {
+	job, err := killOnCloseJob()
+	if err != nil {
+		return fmt.Errorf("create job object: %w", err)
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		return fmt.Errorf("open process: %w", err)
+	}
+	defer windows.CloseHandle(h)
+	return windows.AssignProcessToJobObject(job, h)
+}
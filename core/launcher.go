@@ -1,16 +1,23 @@
 package core
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/mitchellh/go-homedir"
 	"github.com/opendexnetwork/opendex-launcher/build"
 	"github.com/opendexnetwork/opendex-launcher/utils"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -38,16 +45,70 @@ type Launcher struct {
 
 	network string
 	branch  string
+	// channel is the release channel the branch follows, if any.
+	channel string
+
+	// ctx is cancelled when the launcher should stop, nil unless started
+	// with a context or by StartArgs.
+	ctx context.Context
+	// cancel cancels ctx of StartArgs when a shutdown signal arrives before
+	// the launcher runs.
+	cancel context.CancelFunc
+	// options are the settings given to NewLauncher.
+	options options
 
 	homeDir             string
 	networkDir          string
 	launcherDir         string
 	launcherVersionsDir string
+	// ephemeralDirs are the network dirs created for --ephemeral.
+	ephemeralDirs []string
 
 	configFile string
 	config     *Config
+	// configMu guards replacing config against reads through Config.
+	configMu sync.RWMutex
 
-	github *GithubClient
+	args *bootstrapArgs
+
+	github   *GithubClient
+	resolver Resolver
+
+	logger *logrus.Entry
+
+	handlers []EventHandler
+	emitMu   sync.Mutex
+
+	// updating is 1 while update runs. Installing changes the working dir
+	// and the progress of the GitHub client, updates never overlap.
+	updating int32
+
+	mu            sync.Mutex
+	child         *child
+	restart       *restartRequest
+	stopRequested bool
+	output        *lineBuffer
+
+	metrics *launcherMetrics
+	trace   *tracer
+
+	// shipper forwards logs to remote sinks when configured.
+	shipper *logShipper
+
+	// lock is held while managing the network.
+	lock *os.File
+	// lockHolder is what the lock file records about this run.
+	lockHolder lockHolder
+
+	// childEnv is added to the environment of the launcher process.
+	childEnv []string
+
+	// readOnlyVersions is set when the versions dir cannot be written.
+	readOnlyVersions bool
+
+	// backend is the container backend the launcher runs with.
+	backend     containerBackend
+	backendOnce sync.Once
 }
 
 func getHomeDir() (string, error) {
@@ -57,7 +118,7 @@ func getHomeDir() (string, error) {
 	}
 	switch runtime.GOOS {
 	case "linux":
-		return filepath.Join(homeDir, ".opendex-docker"), nil
+		return filepath.Join(linuxHomeDir(homeDir), ".opendex-docker"), nil
 	case "darwin":
 		return filepath.Join(homeDir, "Library", "Application Support", "OpendexDocker"), nil
 	case "windows":
@@ -67,21 +128,9 @@ func getHomeDir() (string, error) {
 	}
 }
 
-func getNetwork() string {
-	if value, ok := os.LookupEnv("NETWORK"); ok {
-		return value
-	}
-	return "mainnet"
-}
-
-func getBranch() string {
-	if value, ok := os.LookupEnv("BRANCH"); ok {
-		return value
-	}
-	return "master"
-}
-
-func NewLauncher() *Launcher {
+// NewLauncher returns a Launcher configured by the environment and the
+// command line, with opts taking precedence over the environment.
+func NewLauncher(opts ...Option) *Launcher {
 	value, present := os.LookupEnv("DEBUG")
 	if present {
 		value = strings.TrimSpace(value)
@@ -91,7 +140,14 @@ func NewLauncher() *Launcher {
 		}
 	}
 
-	return &Launcher{}
+	t := &Launcher{
+		logger:  logrus.NewEntry(logrus.StandardLogger()).WithField("name", "launcher"),
+		metrics: &launcherMetrics{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 func (t *Launcher) init() error {
@@ -123,23 +179,34 @@ func (t *Launcher) parseConfig() error {
 		return err
 	}
 	if !exists {
-		t.config = &Config{}
-		return nil
+		if err := ioutil.WriteFile(t.configFile, []byte(defaultConfigTemplate()), 0600); err != nil {
+			t.logger.Warnf("Failed to create %s: %s", t.configFile, err)
+		} else {
+			t.logger.Infof("Created default config file %s", t.configFile)
+		}
+		t.setConfig(DefaultConfig())
+		return t.applyConfigOverrides(nil)
 	}
 
 	var c *Config
 
-	f, err := os.Open(t.configFile)
+	data, err := ioutil.ReadFile(t.configFile)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	c, err = parseConfig(f)
+	data, err = t.migrateConfigFile(data)
+	if err != nil {
+		return fmt.Errorf("migrate config: %w", err)
+	}
+	c, err = parseConfig(bytes.NewReader(data))
 	if err != nil {
 		return err
 	}
-	t.config = c
-	return nil
+	if err := t.checkConfigKeys(data, c.StrictMode); err != nil {
+		return err
+	}
+	t.setConfig(c)
+	return t.applyConfigOverrides(data)
 }
 
 // checkDir checks if path is a writable folder or creates a new folder when path missing.
@@ -149,7 +216,7 @@ func (t *Launcher) checkDir(path string) error {
 		return err
 	}
 	if !exists {
-		if err := os.Mkdir(path, 0755); err != nil {
+		if _, err := utils.MkdirAll(path, 0755); err != nil {
 			return err
 		}
 	}
@@ -171,9 +238,12 @@ func (t *Launcher) checkDir(path string) error {
 }
 
 func (t *Launcher) ensureHomeDir() error {
-	homeDir, err := getHomeDir()
-	if err != nil {
-		return err
+	homeDir := t.options.homeDir
+	if homeDir == "" {
+		var err error
+		if homeDir, err = getHomeDir(); err != nil {
+			return err
+		}
 	}
 	if err := t.checkDir(homeDir); err != nil {
 		return err
@@ -189,7 +259,18 @@ func (t *Launcher) ensureNetworkDir() error {
 	if t.network == "" {
 		return ErrNetworkEmpty
 	}
-	networkDir := filepath.Join(t.homeDir, t.network)
+	if t.args != nil && t.args.ephemeral {
+		networkDir, err := t.ephemeralNetworkDir(t.network)
+		if err != nil {
+			return err
+		}
+		t.networkDir = networkDir
+		return nil
+	}
+	networkDir, err := t.networkDirOf(t.network)
+	if err != nil {
+		return err
+	}
 	if err := t.checkDir(networkDir); err != nil {
 		return err
 	}
@@ -224,79 +305,480 @@ func (t *Launcher) ensureDirs() error {
 		return err
 	}
 
-	t.network = getNetwork()
+	t.network = t.resolveNetwork().Value
+	return nil
+}
+
+// ensureLauncher returns the launcher binary of commit, downloading it first
+// when it is not installed yet.
+func (t *Launcher) ensureLauncher(commit string) (string, error) {
+	launcher := t.launcherPath(commit)
+
+	exists, err := utils.FileExists(launcher)
+	if err != nil {
+		return "", err
+	}
+	if exists && !extracted(filepath.Dir(launcher)) {
+		t.logger.Infof("The install of %s was interrupted, installing it again", shortCommit(commit))
+		exists = false
+	}
+	if err := t.checkImported(commit, launcher, exists); err != nil {
+		t.logger.Errorf("%s", err)
+		return "", err
+	}
+	if !exists {
+		if err := t.checkWritableVersion(commit); err != nil {
+			t.logger.Errorf("%s", err)
+			return "", err
+		}
+		if err := t.evictVersions(commit); err != nil {
+			t.logger.Warnf("Failed to evict old versions: %s", err)
+		}
+		err := t.github.DownloadLatestBinary(t.branch, commit, t.launcherVersionsDir)
+		if errors.Is(err, ErrNoArtifact) && t.config.Artifacts.BuildFromSource {
+			err = t.buildFromSource(commit)
+		}
+		if err != nil {
+			t.emit(Event{Type: EventDownloadFailed, Commit: commit, Message: err.Error()})
+			return "", err
+		}
+		end := t.trace.span("verify-hash")
+		hash, err := t.verifyInstalled(commit, launcher)
+		end(err)
+		if err != nil {
+			return "", err
+		}
+		if err := t.checkPolicy(commit, launcher, true); err != nil {
+			return "", err
+		}
+		if saved, err := t.github.Cache.dedupe(launcher, hash); err != nil {
+			t.logger.Debugf("Failed to deduplicate %s: %s", launcher, err)
+		} else if saved > 0 {
+			t.logger.Debugf("Linked %s to an identical launcher, saved %d bytes", launcher, saved)
+		}
+		t.shareVersion(commit)
+		t.emit(Event{Type: EventInstalled, Commit: commit})
+	} else if err := t.verifyShared(commit, launcher); err != nil {
+		return "", err
+	} else if err := t.checkPolicy(commit, launcher, false); err != nil {
+		return "", err
+	}
+	if err := t.checkManifest(commit, launcher); err != nil {
+		return "", err
+	}
+
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		executable, err := utils.IsExecutable(launcher)
+		if err != nil {
+			return "", err
+		}
+		if ! executable {
+			if err := t.checkWritableVersion(commit); err != nil {
+				return "", err
+			}
+			if err := os.Chmod(launcher, 0755); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if Debug {
+		fmt.Printf("Launcher: %s\n", launcher)
+	}
+
+	return launcher, nil
+}
+
+// launch runs the child launcher between the pre-start and post-exit hooks.
+// The launcher is started again when a restart was requested while it ran.
+func (t *Launcher) launch(launcher string, commit string) error {
+	for {
+		if err := t.baseContext().Err(); err != nil {
+			return err
+		}
+		env := t.hookEnv(launcher, commit)
+		if err := t.runHook("pre-start", t.config.Hooks.PreStart, env); err != nil {
+			return fmt.Errorf("pre-start hook: %w", err)
+		}
+
+		t.reportPhase(PhaseLaunch, 100, "Starting launcher %s", shortCommit(commit))
+		t.markUsed(commit)
+		t.lockCommit(commit)
+		runErr := t.runChild(launcher, commit)
+		if runErr != nil && !t.takeStopRequested() {
+			t.emit(Event{Type: EventChildCrashed, Commit: commit, Message: runErr.Error()})
+		}
+
+		env = append(env, fmt.Sprintf("OPENDEX_EXIT_CODE=%d", exitCode(runErr)))
+		if err := t.runHook("post-exit", t.config.Hooks.PostExit, env); err != nil {
+			t.logger.Warnf("post-exit hook: %s", err)
+		}
+
+		next, ok := t.takeRestart()
+		if !ok {
+			if err := t.baseContext().Err(); err != nil {
+				return err
+			}
+			return runErr
+		}
+		atomic.AddInt64(&t.metrics.childRestarts, 1)
+		launcher, commit = next.launcher, next.commit
+	}
+}
+
+// resolve returns the head commit of the branch.
+func (t *Launcher) resolve() (string, error) {
+	if t.channel != "" {
+		if err := t.resolveChannel(); err != nil {
+			return "", err
+		}
+	}
+	t.emit(Event{Type: EventResolveStarted})
+	t.reportPhase(PhaseResolve, 0, "Resolving branch %s", t.branch)
+	atomic.AddInt64(&t.metrics.updateChecks, 1)
+	end := t.trace.span(PhaseResolve)
+	commit, err := t.resolver.GetHeadCommit(t.branch)
+	end(err)
+	if err != nil {
+		return "", fmt.Errorf("get branch head: %w", err)
+	}
+	t.reportPhase(PhaseResolve, 100, "Resolved branch %s to %s", t.branch, commit)
+	t.audit(AuditRecord{Action: AuditResolved, Commit: commit})
+	return commit, nil
+}
+
+// update installs the head of the branch, or rebuilds the launcher with
+// --dev, and switches the running launcher over to it when it runs a
+// different commit. It fails with ErrUpdateRunning while another update
+// runs.
+func (t *Launcher) update() (string, bool, error) {
+	if !atomic.CompareAndSwapInt32(&t.updating, 0, 1) {
+		return "", false, ErrUpdateRunning
+	}
+	defer atomic.StoreInt32(&t.updating, 0)
+	var launcher, commit string
+	var err error
+	dev := t.args != nil && t.args.dev != ""
+	if dev {
+		launcher, commit, err = t.buildDev()
+	} else {
+		commit, err = t.resolve()
+		if err == nil {
+			launcher, err = t.ensureLauncher(commit)
+		}
+	}
+	if err != nil {
+		return "", false, err
+	}
+	status := t.status()
+	if !status.Running || status.Commit == commit && !(dev && builtSince(launcher, status.StartedAt)) {
+		return commit, false, nil
+	}
+	t.logger.Infof("Updating to %s", describeRef(t.branch, commit))
+	if err := t.restartChild(launcher, commit); err != nil {
+		return "", false, err
+	}
+	t.audit(AuditRecord{Action: AuditUpdated, Commit: commit, Detail: "from " + status.Commit})
+	return commit, true, nil
+}
+
+// load parses the command line args, creates the directories and reads the
+// config file.
+func (t *Launcher) load(args []string) error {
+	parsed, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	t.args = parsed
+
+	if err := t.setupLogLevel(); err != nil {
+		return err
+	}
+
+	if err := t.ensureDirs(); err != nil {
+		return err
+	}
+
+	if err := t.parseConfig(); err != nil {
+		return err
+	}
+	if err := t.decryptConfig(); err != nil {
+		return err
+	}
+	// the config may move the network dir
 	if t.network != "" {
 		if err := t.ensureNetworkDir(); err != nil {
 			return err
 		}
 	}
+	if err := t.applyProfile(); err != nil {
+		t.logger.Errorf("%s", err)
+		return &ExitCodeError{Code: 1}
+	}
+	return nil
+}
+
+// setupGithub creates the client downloading the launcher from GitHub.
+func (t *Launcher) setupGithub() error {
+	var err error
+	t.github = NewGithubClient(t.accessTokens()...)
+	if t.options.httpClient != nil {
+		t.github.Client = t.options.httpClient
+	} else if t.github.Client, err = t.httpClient(); err != nil {
+		return err
+	}
+	t.github.Progress = t.reportProgress
+	t.github.Downloaded = t.metrics.downloaded
+	t.github.Transferred = t.transferred
+	t.github.Confirm = t.confirmDownload
+	if t.config.Artifacts.DeltaUpdates {
+		t.github.Previous = t.previousRelease
+	}
+	t.github.Span = t.trace.span
+	t.github.Retries = newRetryBudget(time.Duration(t.config.GitHub.RetryBudget) * time.Second)
+	t.github.MaxSkew = time.Duration(t.config.GitHub.MaxClockSkew) * time.Second
+	t.github.Verify = t.verifyArtifact
+	t.github.Cache = newArtifactCache(t.cacheDir())
+	t.github.Extras = t.config.Artifacts.Extra
+	t.github.Limits = t.config.Artifacts.limits()
+	t.github.Attempts = t.config.Artifacts.DownloadAttempts
+	t.github.Archs = hostArchs(runtime.GOOS, runtime.GOARCH, sysctl)
+	if t.github.Mirror, err = parseMirror(t.config.Artifacts.Mirror); err != nil {
+		return err
+	}
+	if runtime.GOOS == "darwin" && sysctl("sysctl.proc_translated") == "1" {
+		t.logger.Debugf("Running under Rosetta, preferring %s launcher builds", t.github.Archs[0])
+	}
+	if t.ctx != nil {
+		t.github.Client = withContext(t.github.Client, t.ctx)
+		t.github.Context = t.ctx
+	}
+	if t.config.Enterprise.Enabled {
+		t.github.Client = forbiddenClient()
+		t.resolver = &importedResolver{launcher: t}
+		return nil
+	}
+
+	t.resolver = t.github
+	if t.config.GitHub.GraphQL {
+		t.resolver = &graphqlResolver{client: t.github}
+	}
 	return nil
 }
 
+// setBranch sets the branch to run, following a release channel when it
+// names one.
+func (t *Launcher) setBranch() {
+	t.branch = t.resolveBranch().Value
+	if isChannel(t.branch) {
+		t.channel = t.branch
+	}
+}
+
+// Start runs opendex-launcher with the command line of the process.
 func (t *Launcher) Start() error {
-	if err := t.ensureDirs(); err != nil {
+	return t.StartArgs(os.Args[1:])
+}
+
+// StartWithContext runs opendex-launcher like Start. Cancelling ctx aborts
+// resolving and downloading the launcher and stops a running launcher the
+// way SIGTERM does.
+func (t *Launcher) StartWithContext(ctx context.Context) error {
+	t.ctx = ctx
+	return t.StartArgs(os.Args[1:])
+}
+
+// StartArgs runs opendex-launcher with the command line args, which do not
+// include the program name.
+func (t *Launcher) StartArgs(args []string) (err error) {
+	if len(args) > 0 && args[0] == sandboxCommand {
+		return runSandboxed(args[1:])
+	}
+
+	// deferred first so that the shipper closes after the last log line
+	defer func() {
+		if t.shipper != nil {
+			t.shipper.Close()
+		}
+	}()
+	if os.Getenv(detachedEnv) != "" {
+		// nobody sees the exit status of a detached opendex-launcher
+		defer func() {
+			var exitErr *ExitCodeError
+			if err != nil && !errors.As(err, &exitErr) {
+				t.logger.Errorf("%s", err)
+			}
+			t.removePidFile()
+		}()
+	}
+
+	defer t.removeEphemeral()
+	if err := t.load(args); err != nil {
+		return err
+	}
+	if t.args.printConfig {
+		return t.printConfig(os.Stdout)
+	}
+	if err := t.useSharedCache(); err != nil {
+		return fmt.Errorf("shared cache: %w", err)
+	}
+	if err := t.useVersionsDir(); err != nil {
 		return err
 	}
 
-	if err := t.parseConfig(); err != nil {
+	if err := t.setupProgress(); err != nil {
+		return err
+	}
+	if t.shipper, err = t.setupLogSinks(); err != nil {
 		return err
 	}
-	t.github = NewGithubClient(t.config.GitHub.AccessToken)
+	t.setupTracing()
+	defer func() {
+		t.finishTrace(err, "")
+	}()
+	if t.config.Webhook.Url != "" {
+		t.subscribe(newWebhookNotifier(t.config.Webhook).handle)
+	}
+	if t.config.Notifications.Desktop {
+		t.subscribe(newDesktopNotifier().handle)
+	}
+	t.subscribe(t.metrics.handle)
 
-	t.branch = getBranch()
+	// shutdown signals abort resolving and downloading through ctx
+	var cancel context.CancelFunc
+	t.ctx, cancel = context.WithCancel(t.baseContext())
+	t.cancel = cancel
+	defer cancel()
 
-	args := os.Args
+	if err := t.setupGithub(); err != nil {
+		return err
+	}
 
-	commit, err := t.github.GetHeadCommit(t.branch)
-	if err != nil {
-		return fmt.Errorf("get branch head: %w", err)
+	deadline := t.startDeadline()
+	defer func() {
+		err = deadline.check(err, t.logger)
+		deadline.lift()
+	}()
+	defer func() {
+		t.diagnoseNetwork(err)
+	}()
+
+	t.setBranch()
+
+	if !t.args.verbatim {
+		if ok, err := t.runCommand(t.args.rest); ok {
+			return err
+		}
 	}
 
-	if Debug {
-		fmt.Printf("Branch: %s (%s)\n", t.branch, commit)
-		fmt.Printf("Network: %s (%s)\n", t.network, t.networkDir)
+	if t.config.GitHub.ValidateToken && !t.config.Enterprise.Enabled {
+		if err := t.github.ValidateTokens(); err != nil {
+			return fmt.Errorf("validate access token: %w", err)
+		}
 	}
 
-	var launcher string
-	if runtime.GOOS == "windows" {
-		launcher = filepath.Join(t.launcherVersionsDir, commit, "launcher.exe")
-	} else {
-		launcher = filepath.Join(t.launcherVersionsDir, commit, "launcher")
+	if t.network != "" {
+		if err := t.lockNetwork(); err != nil {
+			t.logger.Errorf("%s", err)
+			return &ExitCodeError{Code: 1}
+		}
 	}
 
-	exists, err := utils.FileExists(launcher)
+	defer t.handleSignals()()
+
+	if t.args.dev != "" {
+		launcher, commit, err := t.buildDev()
+		if err != nil {
+			return err
+		}
+		// --timeout bounds the build, not the dev node
+		deadline.lift()
+		return t.runLauncher(launcher, commit)
+	}
+
+	var launcher string
+	commit, err := t.resolveResuming()
+	if err == nil {
+		if Debug {
+			fmt.Printf("Branch: %s (%s)\n", t.branch, commit)
+			fmt.Printf("Network: %s (%s)\n", t.network, t.networkDir)
+		}
+
+		launcher, err = t.ensureLauncher(commit)
+		if err != nil {
+			launcher, commit, err = t.fallBack(err)
+		}
+	}
+	if err != nil {
+		launcher, commit, err = t.outageFallback(err)
+	}
+	t.finishStart()
 	if err != nil {
 		return err
 	}
-	if !exists {
-		if err := t.github.DownloadLatestBinary(t.branch, commit, t.launcherVersionsDir); err != nil {
+
+	if t.config.GitHub.CheckAdvisories && !t.config.Enterprise.Enabled {
+		t.checkAdvisories()
+	}
+	deadline.lift()
+
+	t.setupBackend()
+
+	if t.config.Docker.PrePull {
+		if err := t.prePullImages(commit); err != nil {
 			return err
 		}
 	}
 
-	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
-		executable, err := utils.IsExecutable(launcher)
-		if err != nil {
+	if t.config.Docker.VerifyDigests {
+		if err := t.pinImages(); err != nil {
 			return err
 		}
-		if ! executable {
-			if err := os.Chmod(launcher, 0755); err != nil {
-				return err
-			}
+	}
+
+	return t.runLauncher(launcher, commit)
+}
+
+// runLauncher starts the services around the launcher and runs it.
+func (t *Launcher) runLauncher(launcher string, commit string) error {
+	if err := t.provisionSimnet(); err != nil {
+		return err
+	}
+	if t.config.API.Listen != "" {
+		t.output = newLineBuffer(1000)
+		if err := t.startAPI(); err != nil {
+			return err
 		}
 	}
 
-	if Debug {
-		fmt.Printf("Launcher: %s\n", launcher)
+	if t.config.Metrics.Listen != "" {
+		if err := t.startMetrics(); err != nil {
+			return err
+		}
 	}
 
-	if len(args) == 2 && args[1] == "version" {
-		fmt.Printf("opendex-launcher %s-%s\n", build.Version, build.GitCommit[:7])
+	if t.config.IPC.Enabled {
+		if err := t.startIPC(); err != nil {
+			return err
+		}
 	}
 
-	if err := t.Run(launcher, args[1:]...); err != nil {
-		return err
+	t.setupBackend()
+	if t.config.Monitor.enabled() {
+		go t.monitor()
+	}
+	if t.args.watch {
+		go t.watch()
+	}
+	if t.ctx != nil && t.ctx.Done() != nil {
+		go t.stopWhenDone()
 	}
 
-	return nil
+	t.startWatchdog()
+
+	if len(t.args.rest) == 1 && t.args.rest[0] == "version" {
+		fmt.Printf("opendex-launcher %s-%s\n", build.Version, build.GitCommit[:7])
+	}
+
+	return t.launch(launcher, commit)
 }
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+var ErrLauncherMissing = errors.New("launcher binary missing from archive")
+
+// launcherBinary is the name of the launcher binary in a version dir.
+func launcherBinary() string {
+	if runtime.GOOS == "windows" {
+		return "launcher.exe"
+	}
+	return "launcher"
+}
+
+// isLauncherCandidate tells whether name may be the launcher binary under
+// another name, e.g. opendex-launcher or launcher-linux-amd64.
+func isLauncherCandidate(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, ".exe"))
+	for _, prefix := range []string{"launcher", "opendex-launcher"} {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") || strings.HasPrefix(name, prefix+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// platformAliases are other names of an OS or architecture in the paths of
+// archives built for several platforms.
+var platformAliases = map[string][]string{
+	"amd64":  {"amd64", "x64"},
+	"arm64":  {"arm64", "aarch64"},
+	"darwin": {"darwin", "macos"},
+}
+
+// matchesPlatform tells whether the path of a binary names goos and arch,
+// e.g. launcher-linux-amd64 or linux_arm64/launcher.
+func matchesPlatform(path string, goos string, arch string) bool {
+	path = strings.ReplaceAll(strings.ToLower(strings.TrimSuffix(path, ".exe")), "x86_64", "amd64")
+	tokens := strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == '.'
+	})
+	names := func(name string) []string {
+		if aliases, ok := platformAliases[name]; ok {
+			return aliases
+		}
+		return []string{name}
+	}
+	return containsAny(tokens, names(goos)) && containsAny(tokens, names(arch))
+}
+
+func containsAny(list []string, items []string) bool {
+	for _, item := range items {
+		if containsString(list, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestBinary returns the launcher binary the manifest in dir lists for
+// goos and arch, or "" when it lists no binaries. The binaries of the other
+// platforms are removed.
+func manifestBinary(dir string, goos string, arch string) (string, error) {
+	m, err := readManifest(dir)
+	if err != nil || m == nil || len(m.Binaries) == 0 {
+		return "", err
+	}
+	platform := goos + "/" + arch
+	rel, ok := m.Binaries[platform]
+	if !ok {
+		var platforms []string
+		for p := range m.Binaries {
+			platforms = append(platforms, p)
+		}
+		sort.Strings(platforms)
+		return "", fmt.Errorf("%w: no binary for %s, the archive has %s", ErrLauncherMissing, platform, strings.Join(platforms, ", "))
+	}
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: binary %s for %s is outside the archive", ErrLauncherMissing, rel, platform)
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: binary %s for %s is not in the archive", ErrLauncherMissing, rel, platform)
+	}
+	for p, other := range m.Binaries {
+		if p != platform && other != rel {
+			_ = os.Remove(filepath.Join(dir, filepath.FromSlash(other)))
+		}
+	}
+	return path, nil
+}
+
+// normalizeLayout moves the launcher binary extracted into dir to the top of
+// dir when the archive renamed it or nested it in a subdirectory. Archives
+// built for several platforms keep only the binary of the host OS and arch,
+// found by their manifest or the names of the binaries. Without a single
+// launcher binary the error lists what the archive contained.
+func normalizeLayout(dir string, arch string) error {
+	target := filepath.Join(dir, launcherBinary())
+	if info, err := os.Stat(target); err == nil && info.Mode().IsRegular() {
+		return nil
+	}
+	binary, err := manifestBinary(dir, runtime.GOOS, arch)
+	if err != nil {
+		return err
+	}
+	if binary != "" {
+		return os.Rename(binary, target)
+	}
+
+	var files, candidates, names []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel == ".extras" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == "launcher.zip" || rel == MetadataFilename {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(rel))
+		if info.Mode().IsRegular() && isLauncherCandidate(info.Name()) {
+			candidates = append(candidates, path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	if len(candidates) > 1 {
+		var matching []string
+		for i, name := range names {
+			if matchesPlatform(name, runtime.GOOS, arch) {
+				matching = append(matching, candidates[i])
+			}
+		}
+		if len(matching) == 1 {
+			for _, candidate := range candidates {
+				if candidate != matching[0] {
+					_ = os.Remove(candidate)
+				}
+			}
+			return os.Rename(matching[0], target)
+		}
+	}
+
+	switch len(candidates) {
+	case 1:
+		return os.Rename(candidates[0], target)
+	case 0:
+		if len(files) == 0 {
+			return fmt.Errorf("%w: the archive is empty", ErrLauncherMissing)
+		}
+		return fmt.Errorf("%w: expected %s, the archive contains %s", ErrLauncherMissing, launcherBinary(), strings.Join(files, ", "))
+	default:
+		return fmt.Errorf("%w: expected %s, found several candidates: %s", ErrLauncherMissing, launcherBinary(), strings.Join(names, ", "))
+	}
+}
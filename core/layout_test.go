@@ -0,0 +1,119 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func layoutDir(t *testing.T, files ...string) string {
+	dir, err := ioutil.TempDir("", "layout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNormalizeLayoutNested(t *testing.T) {
+	dir := layoutDir(t, "launcher.zip", "dist/opendex-launcher-1.2.0/opendex-launcher", "dist/README.md")
+	defer os.RemoveAll(dir)
+
+	if err := normalizeLayout(dir, runtime.GOARCH); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, launcherBinary()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), "dist/opendex-launcher-1.2.0/opendex-launcher")
+}
+
+func TestNormalizeLayoutMissing(t *testing.T) {
+	dir := layoutDir(t, "launcher.zip", MetadataFilename, "bin/opendexd", "README.md")
+	defer os.RemoveAll(dir)
+
+	err := normalizeLayout(dir, runtime.GOARCH)
+
+	assert.Equal(t, errors.Is(err, ErrLauncherMissing), true)
+	assert.Equal(t, err.Error(), "launcher binary missing from archive: expected "+launcherBinary()+", the archive contains README.md, bin/opendexd")
+}
+
+func TestNormalizeLayoutAmbiguous(t *testing.T) {
+	dir := layoutDir(t, "launcher-linux-amd64", "launcher-darwin-amd64")
+	defer os.RemoveAll(dir)
+
+	// neither is for the arch
+	err := normalizeLayout(dir, "s390x")
+
+	assert.Equal(t, err.Error(), "launcher binary missing from archive: expected "+launcherBinary()+", found several candidates: launcher-darwin-amd64, launcher-linux-amd64")
+}
+
+func TestNormalizeLayoutMultiPlatform(t *testing.T) {
+	own := "dist/" + runtime.GOOS + "_" + runtime.GOARCH + "/" + launcherBinary()
+	dir := layoutDir(t, "dist/plan9_386/launcher", own, "opendex-launcher-"+runtime.GOOS+"-s390x")
+	defer os.RemoveAll(dir)
+
+	if err := normalizeLayout(dir, runtime.GOARCH); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, launcherBinary()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), own)
+	_, err = os.Stat(filepath.Join(dir, "dist", "plan9_386", "launcher"))
+	assert.Equal(t, os.IsNotExist(err), true)
+}
+
+func TestNormalizeLayoutManifest(t *testing.T) {
+	dir := layoutDir(t, "bin/a", "bin/b")
+	defer os.RemoveAll(dir)
+	manifest := `{"binaries": {"` + runtime.GOOS + `/` + runtime.GOARCH + `": "bin/a", "plan9/386": "bin/b"}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, ManifestFilename), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := normalizeLayout(dir, runtime.GOARCH); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, launcherBinary()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), "bin/a")
+	_, err = os.Stat(filepath.Join(dir, "bin", "b"))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	dir = layoutDir(t, "bin/b")
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, ManifestFilename), []byte(`{"binaries": {"plan9/386": "bin/b"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = normalizeLayout(dir, runtime.GOARCH)
+	assert.Equal(t, err.Error(), "launcher binary missing from archive: no binary for "+runtime.GOOS+"/"+runtime.GOARCH+", the archive has plan9/386")
+}
+
+func TestMatchesPlatform(t *testing.T) {
+	assert.Equal(t, matchesPlatform("launcher-linux-amd64", "linux", "amd64"), true)
+	assert.Equal(t, matchesPlatform("linux_x86_64/launcher", "linux", "amd64"), true)
+	assert.Equal(t, matchesPlatform("macos-aarch64/opendex-launcher", "darwin", "arm64"), true)
+	assert.Equal(t, matchesPlatform("launcher-windows-amd64.exe", "windows", "amd64"), true)
+	assert.Equal(t, matchesPlatform("launcher-linux-arm64", "linux", "amd64"), false)
+	assert.Equal(t, matchesPlatform("launcher-darwin-amd64", "linux", "amd64"), false)
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrArchiveLimit = errors.New("archive exceeds a safety limit")
+
+// archiveLimits protect against zip bombs and oversized downloads. Zero
+// means no limit.
+type archiveLimits struct {
+	MaxArchiveSize   int64
+	MaxExtractedSize int64
+	MaxEntries       int
+
+	// AllowSymlinks extracts symlinks pointing inside the target directory
+	// instead of rejecting the archive.
+	AllowSymlinks bool
+}
+
+// maxLinkTarget bounds the size of a symlink entry.
+const maxLinkTarget = 4096
+
+func (t *GithubClient) limitExceeded(format string, args ...interface{}) error {
+	err := fmt.Errorf("%w: %s", ErrArchiveLimit, fmt.Sprintf(format, args...))
+	t.Logger.Warnf("SECURITY WARNING: %s, the archive may be malicious or corrupted", err)
+	return err
+}
+
+// checkArchive checks the declared sizes of the zip entries before anything
+// is extracted.
+func (t *GithubClient) checkArchive(size int64, entries int, extracted uint64) error {
+	l := t.Limits
+	if l.MaxArchiveSize > 0 && size > l.MaxArchiveSize {
+		return t.limitExceeded("archive has %d bytes, the limit is %d", size, l.MaxArchiveSize)
+	}
+	if l.MaxEntries > 0 && entries > l.MaxEntries {
+		return t.limitExceeded("archive has %d entries, the limit is %d", entries, l.MaxEntries)
+	}
+	if l.MaxExtractedSize > 0 && extracted > uint64(l.MaxExtractedSize) {
+		return t.limitExceeded("archive extracts to %d bytes, the limit is %d", extracted, l.MaxExtractedSize)
+	}
+	return nil
+}
+
+// safeJoin joins the archive entry name to dir. It fails for names which
+// would escape dir.
+func safeJoin(dir string, name string) (string, bool) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) || filepath.VolumeName(clean) != "" {
+		return "", false
+	}
+	return filepath.Join(dir, clean), true
+}
+
+// checkEntry rejects entries which are neither files nor directories, like
+// devices and pipes, and symlinks unless they are allowed. With symlinks
+// allowed, it rejects entries below an extracted symlink, which could
+// redirect the write outside dir.
+func (t *GithubClient) checkEntry(dir string, name string, fpath string, mode os.FileMode) error {
+	switch {
+	case mode&os.ModeSymlink != 0:
+		if !t.Limits.AllowSymlinks {
+			return t.limitExceeded("symlink %s, set artifacts.allow-symlinks to extract symlinks", name)
+		}
+	case mode&os.ModeType&^os.ModeDir != 0:
+		return t.limitExceeded("special file %s", name)
+	}
+	if !t.Limits.AllowSymlinks {
+		return nil
+	}
+	rel, err := filepath.Rel(filepath.Join(dir, "."), fpath)
+	if err != nil {
+		return err
+	}
+	current := dir
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		if info, err := os.Lstat(current); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return t.limitExceeded("%s is below the symlink %s", name, part)
+		}
+	}
+	return nil
+}
+
+// safeLink reports whether the symlink target of the entry name stays in
+// the target directory. The target must be relative and may only go up at
+// its start, so that it never leaves a directory another symlink leads to.
+func safeLink(name string, target string) bool {
+	if target == "" || path.IsAbs(target) || filepath.IsAbs(target) || filepath.VolumeName(target) != "" {
+		return false
+	}
+	up := true
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		if part == ".." && !up {
+			return false
+		}
+		up = part == ".."
+	}
+	_, ok := safeJoin("", path.Join(path.Dir(name), target))
+	return ok
+}
+
+// extractSymlink creates the symlink of the entry f at fpath.
+func (t *GithubClient) extractSymlink(f *zip.File, dir string, fpath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(rc, maxLinkTarget+1))
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	target := string(data)
+	if len(data) > maxLinkTarget || !safeLink(f.Name, target) {
+		return t.limitExceeded("symlink %s points outside the directory", f.Name)
+	}
+	if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+	_ = os.Remove(fpath)
+	if err := os.Symlink(filepath.FromSlash(target), fpath); err != nil {
+		return fmt.Errorf("symlink: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"archive/zip"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSafeJoin(t *testing.T) {
+	_, ok := safeJoin("dir", "launcher")
+	assert.Equal(t, ok, true)
+	_, ok = safeJoin("dir", "../launcher")
+	assert.Equal(t, ok, false)
+	_, ok = safeJoin("dir", "/etc/passwd")
+	assert.Equal(t, ok, false)
+}
+
+func TestUnzipLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "launcher.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for _, name := range []string{"a", "b", "c"} {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write(make([]byte, 1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	c := NewGithubClient()
+	c.Limits = archiveLimits{MaxEntries: 2}
+	err = c.unzipTo(archive, filepath.Join(dir, "out"))
+	assert.Equal(t, errors.Is(err, ErrArchiveLimit), true)
+
+	c.Limits = archiveLimits{MaxExtractedSize: 2500}
+	err = c.unzipTo(archive, filepath.Join(dir, "out"))
+	assert.Equal(t, errors.Is(err, ErrArchiveLimit), true)
+
+	c.Limits = archiveLimits{MaxEntries: 3, MaxExtractedSize: 3000}
+	err = c.unzipTo(archive, filepath.Join(dir, "out"))
+	assert.Equal(t, err, nil)
+}
+
+func TestSafeLink(t *testing.T) {
+	assert.Equal(t, safeLink("bin/launcher", "opendex-launcher"), true)
+	assert.Equal(t, safeLink("bin/launcher", "../lib/launcher"), true)
+	assert.Equal(t, safeLink("launcher", "../launcher"), false)
+	assert.Equal(t, safeLink("bin/launcher", "lib/../../.."), false)
+	assert.Equal(t, safeLink("bin/launcher", "/etc/passwd"), false)
+	assert.Equal(t, safeLink("bin/launcher", ""), false)
+}
+
+func TestUnzipSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeZip := func(name string, entries ...[3]string) string {
+		archive := filepath.Join(dir, name)
+		f, err := os.Create(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		w := zip.NewWriter(f)
+		for _, e := range entries {
+			header := &zip.FileHeader{Name: e[0], Method: zip.Deflate}
+			header.SetMode(0644)
+			if e[2] == "link" {
+				header.SetMode(os.ModeSymlink | 0777)
+			}
+			entry, err := w.CreateHeader(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := entry.Write([]byte(e[1])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return archive
+	}
+
+	link := writeZip("link.zip", [3]string{"launcher", "opendex", ""}, [3]string{"current", "launcher", "link"})
+	c := NewGithubClient()
+	err = c.unzipTo(link, filepath.Join(dir, "denied"))
+	assert.Equal(t, errors.Is(err, ErrArchiveLimit), true)
+
+	c.Limits = archiveLimits{AllowSymlinks: true}
+	if err := c.unzipTo(link, filepath.Join(dir, "allowed")); err != nil {
+		t.Fatal(err)
+	}
+	target, err := os.Readlink(filepath.Join(dir, "allowed", "current"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, target, "launcher")
+
+	escape := writeZip("escape.zip", [3]string{"data", "..", "link"}, [3]string{"data/passwd", "x", ""})
+	err = c.unzipTo(escape, filepath.Join(dir, "escape"))
+	assert.Equal(t, errors.Is(err, ErrArchiveLimit), true)
+
+	through := writeZip("through.zip", [3]string{"data", ".", "link"}, [3]string{"data/passwd", "x", ""})
+	err = c.unzipTo(through, filepath.Join(dir, "through"))
+	assert.Equal(t, errors.Is(err, ErrArchiveLimit), true)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrNetworkLocked = errors.New("network is managed by another opendex-launcher")
+
+	errLocked = errors.New("locked")
+)
+
+// lockHolder is written to the lock file by the process holding the lock.
+type lockHolder struct {
+	Pid       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	// Commit is the version the network runs, once the launcher started.
+	Commit string `json:"commit,omitempty"`
+}
+
+func (t *Launcher) lockPath() string {
+	return filepath.Join(t.launcherDir, t.network+".lock")
+}
+
+// lockNetwork takes an advisory lock on the lock file of the network, which
+// is released when opendex-launcher exits. It fails with ErrNetworkLocked
+// when another opendex-launcher holds the lock.
+func (t *Launcher) lockNetwork() error {
+	f, err := os.OpenFile(t.lockPath(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if err := tryLock(f); err != nil {
+		defer f.Close()
+		if !errors.Is(err, errLocked) {
+			return fmt.Errorf("lock %s: %w", t.lockPath(), err)
+		}
+		var holder lockHolder
+		data, _ := ioutil.ReadAll(f)
+		if json.Unmarshal(data, &holder) != nil || holder.Pid == 0 {
+			return fmt.Errorf("%s: %w", t.network, ErrNetworkLocked)
+		}
+		return fmt.Errorf("%s: %w (pid %d, started %s)", t.network, ErrNetworkLocked, holder.Pid, holder.StartedAt.Local().Format(time.RFC3339))
+	}
+
+	t.lockHolder = lockHolder{Pid: os.Getpid(), StartedAt: time.Now().UTC()}
+	if err := writeLockHolder(f, t.lockHolder); err != nil {
+		return err
+	}
+	t.lock = f
+	return nil
+}
+
+func writeLockHolder(f *os.File, holder lockHolder) error {
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err = f.WriteAt(data, 0)
+	return err
+}
+
+// lockCommit records in the lock file that the network runs commit, so that
+// clean and the eviction of other runs keep that version.
+func (t *Launcher) lockCommit(commit string) {
+	if t.lock == nil {
+		return
+	}
+	t.lockHolder.Commit = commit
+	if err := writeLockHolder(t.lock, t.lockHolder); err != nil {
+		t.logger.Debugf("Failed to record %s in %s: %s", shortCommit(commit), t.lockPath(), err)
+	}
+}
+
+// versionsInUse returns the commits the networks of running
+// opendex-launchers run, as recorded in their lock files.
+func (t *Launcher) versionsInUse() map[string]bool {
+	inUse := make(map[string]bool)
+	files, err := filepath.Glob(filepath.Join(t.launcherDir, "*.lock"))
+	if err != nil {
+		return inUse
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var holder lockHolder
+		if json.Unmarshal(data, &holder) != nil || holder.Commit == "" || holder.Pid == 0 {
+			continue
+		}
+		if processAlive(holder.Pid) {
+			inUse[holder.Commit] = true
+		}
+	}
+	return inUse
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLockNetwork(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := &Launcher{network: "simnet", launcherDir: dir}
+	if err := first.lockNetwork(); err != nil {
+		t.Fatal(err)
+	}
+
+	second := &Launcher{network: "simnet", launcherDir: dir}
+	err = second.lockNetwork()
+	assert.Equal(t, errors.Is(err, ErrNetworkLocked), true)
+	assert.Equal(t, strings.Contains(err.Error(), fmt.Sprintf("pid %d", os.Getpid())), true)
+
+	other := &Launcher{network: "testnet", launcherDir: dir}
+	if err := other.lockNetwork(); err != nil {
+		t.Fatal(err)
+	}
+	other.lock.Close()
+
+	first.lock.Close()
+	if err := second.lockNetwork(); err != nil {
+		t.Fatal(err)
+	}
+	second.lock.Close()
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// tryLock takes an exclusive flock on f without waiting for it.
+func tryLock(f *os.File) error {
+	err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+	if err == unix.EWOULDBLOCK {
+		return errLocked
+	}
+	return err
+}
@@ -0,0 +1,17 @@
+package core
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// tryLock locks f exclusively without waiting for it. Windows locks are
+// mandatory, so a byte far behind the content is locked to keep the holder
+// readable.
+func tryLock(f *os.File) error {
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &windows.Overlapped{OffsetHigh: 1})
+	if err == windows.ERROR_LOCK_VIOLATION {
+		return errLocked
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// logKeys are the keys structured loggers use for the level, message and
+// time of a line, in order of preference.
+var (
+	levelKeys   = []string{"level", "lvl", "severity"}
+	messageKeys = []string{"msg", "message"}
+	timeKeys    = []string{"time", "ts", "timestamp"}
+)
+
+// logRenderer renders JSON log lines of the launcher, objects with a level
+// and a message, as colored text and drops those below level. Other output is passed through as it comes, so
+// prompts without a trailing newline keep working.
+type logRenderer struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level logrus.Level
+	color bool
+
+	// line holds the structured line being written, started is false in
+	// the middle of a line.
+	line       []byte
+	structured bool
+	started    bool
+}
+
+func newLogRenderer(w io.Writer, level logrus.Level, color bool) *logRenderer {
+	return &logRenderer{w: w, level: level, color: color, started: true}
+}
+
+func (t *logRenderer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(p)
+	for len(p) > 0 {
+		if t.started {
+			t.structured = p[0] == '{'
+			t.started = false
+		}
+		chunk := p
+		i := bytes.IndexByte(p, '\n')
+		if i >= 0 {
+			chunk = p[:i+1]
+		}
+		p = p[len(chunk):]
+
+		if !t.structured {
+			if _, err := t.w.Write(chunk); err != nil {
+				return 0, err
+			}
+		} else {
+			t.line = append(t.line, chunk...)
+			if i >= 0 {
+				err := t.writeLine(t.line)
+				t.line = nil
+				if err != nil {
+					return 0, err
+				}
+			}
+		}
+		if i >= 0 {
+			t.started = true
+		}
+	}
+	return n, nil
+}
+
+func (t *logRenderer) writeLine(line []byte) error {
+	var fields map[string]interface{}
+	// other JSON, e.g. printed by a command, is not a log line
+	if err := json.Unmarshal(line, &fields); err != nil || !hasField(fields, levelKeys) || !hasField(fields, messageKeys) {
+		_, err := t.w.Write(line)
+		return err
+	}
+	text, ok := t.render(fields)
+	if !ok {
+		return nil
+	}
+	_, err := io.WriteString(t.w, text+"\n")
+	return err
+}
+
+func hasField(fields map[string]interface{}, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := fields[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func takeField(fields map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok {
+			delete(fields, key)
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// render formats a structured log line and reports whether it passes the
+// level filter. Lines with an unknown level always pass.
+func (t *logRenderer) render(fields map[string]interface{}) (string, bool) {
+	levelName := takeField(fields, levelKeys)
+	message := takeField(fields, messageKeys)
+	timestamp := takeField(fields, timeKeys)
+
+	label := strings.ToUpper(levelName)
+	color := 0
+	if level, err := logrus.ParseLevel(levelName); err == nil {
+		if level > t.level {
+			return "", false
+		}
+		label = strings.ToUpper(level.String())
+		switch level {
+		case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+			color = 31
+		case logrus.WarnLevel:
+			color = 33
+			label = "WARN"
+		case logrus.InfoLevel:
+			color = 36
+		default:
+			color = 37
+		}
+	}
+	if len(label) > 4 {
+		label = label[:4]
+	}
+
+	var b strings.Builder
+	if t.color && color != 0 {
+		fmt.Fprintf(&b, "\x1b[%dm%-4s\x1b[0m", color, label)
+	} else {
+		fmt.Fprintf(&b, "%-4s", label)
+	}
+	if timestamp != "" {
+		fmt.Fprintf(&b, "[%s]", timestamp)
+	}
+	fmt.Fprintf(&b, " %s", message)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, fields[key])
+	}
+	return b.String(), true
+}
+
+// Flush writes out an incomplete structured line as it is.
+func (t *logRenderer) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.line) == 0 {
+		return nil
+	}
+	_, err := t.w.Write(t.line)
+	t.line = nil
+	return err
+}
+
+// logLevel returns the level given with --log-level. Without it every line
+// is shown.
+func (t *Launcher) logLevel() logrus.Level {
+	level, err := logrus.ParseLevel(t.args.logLevel)
+	if err != nil {
+		return logrus.TraceLevel
+	}
+	return level
+}
+
+// setupLogLevel applies --log-level to the logs of opendex-launcher itself.
+func (t *Launcher) setupLogLevel() error {
+	if t.args.logLevel == "" {
+		return nil
+	}
+	level, err := logrus.ParseLevel(t.args.logLevel)
+	if err != nil {
+		return fmt.Errorf("log level: %w", err)
+	}
+	logrus.SetLevel(level)
+	return nil
+}
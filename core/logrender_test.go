@@ -0,0 +1,31 @@
+package core
+
+import (
+	"bytes"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"testing"
+)
+
+func TestLogRenderer(t *testing.T) {
+	var b bytes.Buffer
+	r := newLogRenderer(&b, logrus.InfoLevel, false)
+
+	_, _ = r.Write([]byte(`{"level":"info","msg":"Starting","time":"12:00:00","service":"lndbtc"}` + "\n"))
+	_, _ = r.Write([]byte(`{"level":"debug","msg":"hidden"}` + "\n"))
+	_, _ = r.Write([]byte(`{"level":"warning",`))
+	_, _ = r.Write([]byte(`"message":"Slow"}` + "\nplain line\n{not json}\n"))
+	_, _ = r.Write([]byte(`{"id":"abc","level":1}` + "\n"))
+	_, _ = r.Write([]byte("Continue? "))
+
+	assert.Equal(t, b.String(), "INFO[12:00:00] Starting service=lndbtc\nWARN Slow\nplain line\n{not json}\n{\"id\":\"abc\",\"level\":1}\nContinue? ")
+}
+
+func TestLogRendererColor(t *testing.T) {
+	var b bytes.Buffer
+	r := newLogRenderer(&b, logrus.TraceLevel, true)
+
+	_, _ = r.Write([]byte(`{"level":"error","msg":"Failed"}` + "\n"))
+
+	assert.Equal(t, b.String(), "\x1b[31mERRO\x1b[0m Failed\n")
+}
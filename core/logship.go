@@ -0,0 +1,301 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LogSinks struct {
+	Syslog   string `toml:"syslog" comment:"Forward logs to syslog, \"local\" for the local daemon or udp://host:514 and tcp://host:514 for a remote one"`
+	Journald bool   `toml:"journald" comment:"Forward logs to the systemd journal"`
+	Loki     string `toml:"loki" comment:"Loki push endpoint to forward logs to, e.g. http://localhost:3100/loki/api/v1/push"`
+}
+
+// logLine is a line of opendex-launcher or launcher output forwarded to the
+// log sinks.
+type logLine struct {
+	Time    time.Time
+	Level   logrus.Level
+	Source  string
+	Message string
+}
+
+// logSink is a remote destination of log lines.
+type logSink interface {
+	send(line logLine) error
+	// flush sends buffered lines.
+	flush() error
+	close() error
+}
+
+// logShipper forwards log lines to the sinks from a goroutine of its own.
+// Lines are dropped when the sinks cannot keep up, the output of the
+// launcher is never blocked.
+type logShipper struct {
+	sinks []logSink
+	lines chan logLine
+	done  chan struct{}
+
+	// mu guards closed, the hook keeps firing after Close.
+	mu     sync.Mutex
+	closed bool
+}
+
+func newLogShipper(sinks []logSink) *logShipper {
+	t := &logShipper{
+		sinks: sinks,
+		lines: make(chan logLine, 1000),
+		done:  make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+func (t *logShipper) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-t.lines:
+			if !ok {
+				t.flush()
+				for _, sink := range t.sinks {
+					_ = sink.close()
+				}
+				return
+			}
+			for _, sink := range t.sinks {
+				if err := sink.send(line); err != nil && Debug {
+					fmt.Printf("Failed to ship log line: %s\n", err)
+				}
+			}
+		case <-ticker.C:
+			t.flush()
+		}
+	}
+}
+
+func (t *logShipper) flush() {
+	for _, sink := range t.sinks {
+		if err := sink.flush(); err != nil && Debug {
+			fmt.Printf("Failed to ship logs: %s\n", err)
+		}
+	}
+}
+
+func (t *logShipper) ship(line logLine) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.lines <- line:
+	default:
+	}
+}
+
+// Close sends the pending lines and closes the sinks. Lines shipped after
+// Close are dropped.
+func (t *logShipper) Close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	close(t.lines)
+	t.mu.Unlock()
+	<-t.done
+}
+
+// Levels implements logrus.Hook for the logs of opendex-launcher itself.
+func (t *logShipper) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (t *logShipper) Fire(entry *logrus.Entry) error {
+	message := entry.Message
+	if name, ok := entry.Data["name"]; ok {
+		message = fmt.Sprintf("[%v] %s", name, message)
+	}
+	t.ship(logLine{Time: entry.Time, Level: entry.Level, Source: "opendex-launcher", Message: message})
+	return nil
+}
+
+// shipWriter ships every line written to it as launcher output.
+type shipWriter struct {
+	shipper *logShipper
+	partial []byte
+}
+
+func (t *logShipper) writer() *shipWriter {
+	return &shipWriter{shipper: t}
+}
+
+// Write ships the complete lines of p. The level of JSON log lines is taken
+// from the line.
+func (t *shipWriter) Write(p []byte) (int, error) {
+	data := append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(data[:i]), "\r")
+		data = data[i+1:]
+		if line == "" {
+			continue
+		}
+		level := logrus.InfoLevel
+		if strings.HasPrefix(line, "{") {
+			var fields map[string]interface{}
+			if json.Unmarshal([]byte(line), &fields) == nil {
+				if l, err := logrus.ParseLevel(takeField(fields, levelKeys)); err == nil {
+					level = l
+				}
+			}
+		}
+		t.shipper.ship(logLine{Time: time.Now(), Level: level, Source: "launcher", Message: line})
+	}
+	t.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// syslogSeverity maps level to a syslog severity.
+func syslogSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// lokiSink pushes batches of lines to a Loki push endpoint.
+type lokiSink struct {
+	url     string
+	network string
+	client  *http.Client
+
+	mu    sync.Mutex
+	batch []logLine
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+func newLokiSink(url string, network string) *lokiSink {
+	return &lokiSink{url: url, network: network, client: &http.Client{Timeout: 10 * time.Second, Transport: newUserAgentTransport(nil)}}
+}
+
+// lokiPayload groups lines into one stream per source and level.
+func lokiPayload(network string, lines []logLine) lokiPush {
+	var push lokiPush
+	streams := make(map[string]int)
+	for _, line := range lines {
+		key := line.Source + "/" + line.Level.String()
+		i, ok := streams[key]
+		if !ok {
+			i = len(push.Streams)
+			streams[key] = i
+			push.Streams = append(push.Streams, lokiStream{Stream: map[string]string{
+				"job":     "opendex-launcher",
+				"network": network,
+				"source":  line.Source,
+				"level":   line.Level.String(),
+			}})
+		}
+		push.Streams[i].Values = append(push.Streams[i].Values, [2]string{strconv.FormatInt(line.Time.UnixNano(), 10), line.Message})
+	}
+	return push
+}
+
+func (t *lokiSink) send(line logLine) error {
+	t.mu.Lock()
+	t.batch = append(t.batch, line)
+	full := len(t.batch) >= 500
+	t.mu.Unlock()
+	if full {
+		return t.flush()
+	}
+	return nil
+}
+
+func (t *lokiSink) flush() error {
+	t.mu.Lock()
+	batch := t.batch
+	t.batch = nil
+	t.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(lokiPayload(t.network, batch))
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Post(t.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("loki: %s", resp.Status)
+	}
+	return nil
+}
+
+func (t *lokiSink) close() error {
+	return nil
+}
+
+// setupLogSinks starts forwarding logs to the configured sinks. It returns
+// nil when no sink is configured.
+func (t *Launcher) setupLogSinks() (*logShipper, error) {
+	config := t.config.LogSinks
+	var sinks []logSink
+	if config.Syslog != "" {
+		sink, err := newSyslogSink(config.Syslog, t.network)
+		if err != nil {
+			return nil, fmt.Errorf("syslog: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if config.Journald {
+		sink, err := newJournaldSink(t.network)
+		if err != nil {
+			return nil, fmt.Errorf("journald: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if config.Loki != "" {
+		sinks = append(sinks, newLokiSink(config.Loki, t.network))
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	shipper := newLogShipper(sinks)
+	logrus.AddHook(shipper)
+	return shipper, nil
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"testing"
+	"time"
+)
+
+func TestLokiPayload(t *testing.T) {
+	now := time.Unix(1614600000, 0)
+	push := lokiPayload("mainnet", []logLine{
+		{Time: now, Level: logrus.InfoLevel, Source: "launcher", Message: "a"},
+		{Time: now, Level: logrus.WarnLevel, Source: "opendex-launcher", Message: "b"},
+		{Time: now.Add(time.Second), Level: logrus.InfoLevel, Source: "launcher", Message: "c"},
+	})
+
+	assert.Equal(t, len(push.Streams), 2)
+	assert.Equal(t, push.Streams[0].Stream, map[string]string{"job": "opendex-launcher", "network": "mainnet", "source": "launcher", "level": "info"})
+	assert.Equal(t, push.Streams[0].Values, [][2]string{{"1614600000000000000", "a"}, {"1614600001000000000", "c"}})
+	assert.Equal(t, push.Streams[1].Stream["level"], "warning")
+}
+
+func TestShipWriter(t *testing.T) {
+	shipper := &logShipper{lines: make(chan logLine, 10)}
+	w := shipper.writer()
+
+	_, _ = w.Write([]byte("plain\n{\"level\":\"error\",\"msg\":\"x\"}\r\npart"))
+	_, _ = w.Write([]byte("ial\n\n"))
+	close(shipper.lines)
+
+	var lines []logLine
+	for line := range shipper.lines {
+		lines = append(lines, line)
+	}
+	assert.Equal(t, len(lines), 3)
+	assert.Equal(t, lines[0].Message, "plain")
+	assert.Equal(t, lines[1].Level, logrus.ErrorLevel)
+	assert.Equal(t, lines[2].Message, "partial")
+}
+
+func TestShipAfterClose(t *testing.T) {
+	shipper := newLogShipper(nil)
+	shipper.Close()
+
+	// the hook keeps firing after Close
+	shipper.ship(logLine{Message: "late"})
+	shipper.Close()
+}
@@ -0,0 +1,121 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/syslog"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// syslogSink writes lines to a syslog daemon.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// newSyslogSink connects to the local syslog daemon for "local" and to the
+// remote one of a udp:// or tcp:// address otherwise.
+func newSyslogSink(address string, network string) (*syslogSink, error) {
+	proto, raddr := "", ""
+	if address != "local" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "udp" && u.Scheme != "tcp" {
+			return nil, fmt.Errorf("unsupported syslog address: %s", address)
+		}
+		proto, raddr = u.Scheme, u.Host
+	}
+	w, err := syslog.Dial(proto, raddr, syslog.LOG_DAEMON|syslog.LOG_INFO, "opendex-launcher-"+network)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (t *syslogSink) send(line logLine) error {
+	message := line.Message
+	if line.Source != "opendex-launcher" {
+		message = line.Source + ": " + message
+	}
+	switch syslogSeverity(line.Level) {
+	case 2:
+		return t.w.Crit(message)
+	case 3:
+		return t.w.Err(message)
+	case 4:
+		return t.w.Warning(message)
+	case 6:
+		return t.w.Info(message)
+	default:
+		return t.w.Debug(message)
+	}
+}
+
+func (t *syslogSink) flush() error {
+	return nil
+}
+
+func (t *syslogSink) close() error {
+	return t.w.Close()
+}
+
+// journalSocket is where journald receives entries in its native protocol.
+const journalSocket = "/run/systemd/journal/socket"
+
+// journaldSink writes lines to the systemd journal.
+type journaldSink struct {
+	conn    net.Conn
+	network string
+}
+
+func newJournaldSink(network string) (*journaldSink, error) {
+	conn, err := net.Dial("unixgram", journalSocket)
+	if err != nil {
+		return nil, err
+	}
+	return &journaldSink{conn: conn, network: network}, nil
+}
+
+// journalEntry encodes fields in the native journal protocol. Values with
+// newlines are written with their length in front.
+func journalEntry(fields [][2]string) []byte {
+	var b bytes.Buffer
+	for _, field := range fields {
+		key, value := field[0], field[1]
+		if !strings.Contains(value, "\n") {
+			fmt.Fprintf(&b, "%s=%s\n", key, value)
+			continue
+		}
+		b.WriteString(key)
+		b.WriteByte('\n')
+		_ = binary.Write(&b, binary.LittleEndian, uint64(len(value)))
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+func (t *journaldSink) send(line logLine) error {
+	_, err := t.conn.Write(journalEntry([][2]string{
+		{"MESSAGE", line.Message},
+		{"PRIORITY", fmt.Sprint(syslogSeverity(line.Level))},
+		{"SYSLOG_IDENTIFIER", line.Source},
+		{"OPENDEX_NETWORK", t.network},
+	}))
+	return err
+}
+
+func (t *journaldSink) flush() error {
+	return nil
+}
+
+func (t *journaldSink) close() error {
+	return t.conn.Close()
+}
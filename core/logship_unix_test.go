@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestJournalEntry(t *testing.T) {
+	entry := journalEntry([][2]string{{"MESSAGE", "a\nb"}, {"PRIORITY", "6"}})
+
+	assert.Equal(t, entry, []byte("MESSAGE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\nPRIORITY=6\n"))
+}
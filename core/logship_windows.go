@@ -0,0 +1,13 @@
+package core
+
+import (
+	"errors"
+)
+
+func newSyslogSink(address string, network string) (logSink, error) {
+	return nil, errors.New("not supported on Windows")
+}
+
+func newJournaldSink(network string) (logSink, error) {
+	return nil, errors.New("not supported on Windows")
+}
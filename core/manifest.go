@@ -0,0 +1,89 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ManifestFilename is the optional manifest a launcher artifact ships next to
+// the launcher binary.
+const ManifestFilename = "manifest.json"
+
+var ErrIncompatible = errors.New("launcher needs a newer opendex-launcher")
+
+// ArtifactManifest describes the build of a launcher artifact.
+type ArtifactManifest struct {
+	Version   string `json:"version,omitempty"`
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	// MinBootstrapVersion is the oldest opendex-launcher able to run the
+	// launcher.
+	MinBootstrapVersion string `json:"min_bootstrap_version,omitempty"`
+	// Binaries are the launcher binaries of an archive built for several
+	// platforms by "<os>/<arch>", relative to the archive root.
+	Binaries map[string]string `json:"binaries,omitempty"`
+}
+
+// readManifest returns the manifest extracted into dir, or nil when the
+// artifact has none.
+func readManifest(dir string) (*ArtifactManifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m ArtifactManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", ManifestFilename, err)
+	}
+	return &m, nil
+}
+
+// checkCompatible fails when m requires a newer opendex-launcher than
+// version. Development builds have no version and run everything.
+func (m *ArtifactManifest) checkCompatible(version string) error {
+	if m == nil || m.MinBootstrapVersion == "" || version == "" {
+		return nil
+	}
+	if compareVersions(version, m.MinBootstrapVersion) < 0 {
+		return fmt.Errorf("%w: it requires %s or later, this is %s", ErrIncompatible, m.MinBootstrapVersion, version)
+	}
+	return nil
+}
+
+// manifestVersion returns the version of launcher from its manifest, or an
+// empty string when it shipped none.
+func manifestVersion(launcher string) string {
+	m, err := readMetadata(filepath.Dir(launcher))
+	if err != nil || m.Manifest == nil {
+		return ""
+	}
+	return m.Manifest.Version
+}
+
+// checkManifest checks the manifest of the installed launcher of commit
+// against this opendex-launcher.
+func (t *Launcher) checkManifest(commit string, launcher string) error {
+	m, err := readMetadata(filepath.Dir(launcher))
+	if err != nil {
+		return err
+	}
+	if m.Manifest == nil {
+		return nil
+	}
+	if m.Manifest.GitCommit != "" && m.Manifest.GitCommit != commit {
+		t.logger.Warnf("Launcher of %s was built from commit %s", shortCommit(commit), shortCommit(m.Manifest.GitCommit))
+	}
+	if err := m.Manifest.checkCompatible(build.Version); err != nil {
+		t.logger.Errorf("Cannot run %s: %s", shortCommit(commit), err)
+		return err
+	}
+	return nil
+}
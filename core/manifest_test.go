@@ -0,0 +1,45 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m, err := readManifest(dir)
+	assert.Equal(t, m == nil && err == nil, true)
+
+	data := `{"version": "21.03.01", "git_commit": "abc", "build_time": "2021-03-01T10:00:00Z", "min_bootstrap_version": "1.2.0"}`
+	if err := ioutil.WriteFile(filepath.Join(dir, ManifestFilename), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err = readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *m, ArtifactManifest{Version: "21.03.01", GitCommit: "abc", BuildTime: "2021-03-01T10:00:00Z", MinBootstrapVersion: "1.2.0"})
+}
+
+func TestCheckCompatible(t *testing.T) {
+	m := &ArtifactManifest{MinBootstrapVersion: "1.2.0"}
+
+	assert.Equal(t, m.checkCompatible("1.2.0"), nil)
+	assert.Equal(t, m.checkCompatible("1.10.0"), nil)
+	assert.Equal(t, m.checkCompatible(""), nil)
+	err := m.checkCompatible("1.1.9")
+	assert.Equal(t, errors.Is(err, ErrIncompatible), true)
+	assert.Equal(t, err.Error(), "launcher needs a newer opendex-launcher: it requires 1.2.0 or later, this is 1.1.9")
+
+	var none *ArtifactManifest
+	assert.Equal(t, none.checkCompatible("1.0.0"), nil)
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const MetadataFilename = "metadata.json"
+
+// VersionMetadata is stored next to an installed launcher version and
+// describes where it came from.
+type VersionMetadata struct {
+	Branch string `json:"branch,omitempty"`
+	Commit string `json:"commit"`
+
+	// Url is the artifact the version was downloaded from. ETag and
+	// LastModified are its cache validators, used to revalidate the cached
+	// archive instead of downloading it again.
+	Url          string `json:"url,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+
+	// ArchiveSha256 is the hash of launcher.zip, which keys it in the
+	// artifact cache.
+	ArchiveSha256 string `json:"archive_sha256,omitempty"`
+	// Sha256 is the hash of the launcher binary.
+	Sha256 string `json:"sha256,omitempty"`
+
+	// Signature is the cosign mode the archive was verified with, empty when
+	// its signature was not verified. A policy key adds its fingerprint,
+	// e.g. "key:0123456789abcdef".
+	Signature string `json:"signature,omitempty"`
+
+	// Imported is set for versions installed with bundle import.
+	Imported bool `json:"imported,omitempty"`
+	// Built is set for versions built from source on this machine.
+	Built bool `json:"built,omitempty"`
+	// Arch is the architecture of the launcher build, the native one when
+	// empty.
+	Arch string `json:"arch,omitempty"`
+
+	// Manifest is the manifest.json the artifact shipped, if any.
+	Manifest *ArtifactManifest `json:"manifest,omitempty"`
+
+	// Step is the last step of the install completed, empty for versions
+	// installed in one go.
+	Step string `json:"step,omitempty"`
+
+	InstalledAt time.Time `json:"installed_at,omitempty"`
+	LastUsedAt  time.Time `json:"last_used_at,omitempty"`
+}
+
+// readMetadata returns the metadata of the version in dir, or empty metadata
+// when there is none.
+func readMetadata(dir string) (*VersionMetadata, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, MetadataFilename))
+	if os.IsNotExist(err) {
+		return &VersionMetadata{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m VersionMetadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", MetadataFilename, err)
+	}
+	return &m, nil
+}
+
+func writeMetadata(dir string, m *VersionMetadata) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, MetadataFilename), data, 0644)
+}
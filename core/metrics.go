@@ -0,0 +1,93 @@
+package core
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type Metrics struct {
+	Listen string `toml:"listen" comment:"Loopback address to serve Prometheus metrics on, e.g. 127.0.0.1:9464, disabled when empty"`
+}
+
+// launcherMetrics are the counters exposed on /metrics. Only int64 fields
+// keep them aligned for atomic access on 32-bit platforms.
+type launcherMetrics struct {
+	updateChecks     int64
+	downloads        int64
+	downloadFailures int64
+	downloadBytes    int64
+	childRestarts    int64
+	childCrashes     int64
+	lastUpdate       int64
+}
+
+func (m *launcherMetrics) handle(e Event) {
+	switch e.Type {
+	case EventInstalled:
+		atomic.AddInt64(&m.downloads, 1)
+		atomic.StoreInt64(&m.lastUpdate, e.Time.Unix())
+	case EventDownloadFailed:
+		atomic.AddInt64(&m.downloadFailures, 1)
+	case EventChildCrashed:
+		atomic.AddInt64(&m.childCrashes, 1)
+	}
+}
+
+func (m *launcherMetrics) downloaded(n int64) {
+	atomic.AddInt64(&m.downloadBytes, n)
+}
+
+// writeMetrics writes m and the state of the launcher in the Prometheus text
+// format.
+func writeMetrics(w io.Writer, m *launcherMetrics, status ChildStatus, now time.Time) {
+	labels := fmt.Sprintf("{network=%q}", status.Network)
+	metric := func(name string, kind string, help string, value interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s%s %v\n", name, help, name, kind, name, labels, value)
+	}
+	metric("opendex_launcher_update_checks_total", "counter", "Number of times the branch head was resolved.", atomic.LoadInt64(&m.updateChecks))
+	metric("opendex_launcher_downloads_total", "counter", "Number of launcher builds downloaded and installed.", atomic.LoadInt64(&m.downloads))
+	metric("opendex_launcher_download_failures_total", "counter", "Number of failed launcher downloads.", atomic.LoadInt64(&m.downloadFailures))
+	metric("opendex_launcher_download_bytes_total", "counter", "Bytes of launcher archives downloaded.", atomic.LoadInt64(&m.downloadBytes))
+	metric("opendex_launcher_child_restarts_total", "counter", "Number of times the launcher was restarted.", atomic.LoadInt64(&m.childRestarts))
+	metric("opendex_launcher_child_crashes_total", "counter", "Number of times the launcher exited unexpectedly.", atomic.LoadInt64(&m.childCrashes))
+	metric("opendex_launcher_last_update_timestamp_seconds", "gauge", "Unix time a launcher build was last installed, 0 when none was installed yet.", atomic.LoadInt64(&m.lastUpdate))
+
+	up, uptime := 0, 0.0
+	if status.Running {
+		up = 1
+		uptime = now.Sub(status.StartedAt).Seconds()
+	}
+	metric("opendex_launcher_child_up", "gauge", "Whether the launcher is running.", up)
+	metric("opendex_launcher_child_uptime_seconds", "gauge", "Seconds since the launcher was started.", uptime)
+}
+
+// startMetrics serves /metrics on the configured loopback address.
+func (t *Launcher) startMetrics() error {
+	listen := t.config.Metrics.Listen
+	if err := checkLoopback(listen); err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeMetrics(w, t.metrics, t.status(), time.Now())
+	})
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			logrus.NewEntry(logrus.StandardLogger()).WithField("name", "metrics").Errorf("Serve: %s", err)
+		}
+	}()
+	if Debug {
+		fmt.Printf("Metrics: http://%s/metrics\n", ln.Addr())
+	}
+	return nil
+}
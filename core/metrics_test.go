@@ -0,0 +1,34 @@
+package core
+
+import (
+	"bytes"
+	"github.com/magiconair/properties/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	now := time.Unix(1614600000, 0)
+	m := &launcherMetrics{updateChecks: 2}
+	m.handle(Event{Type: EventInstalled, Time: now})
+	m.handle(Event{Type: EventDownloadFailed, Time: now})
+	m.downloaded(1024)
+
+	var b bytes.Buffer
+	writeMetrics(&b, m, ChildStatus{Network: "mainnet", Running: true, StartedAt: now.Add(-90 * time.Second)}, now)
+	out := b.String()
+
+	for _, line := range []string{
+		`opendex_launcher_update_checks_total{network="mainnet"} 2`,
+		`opendex_launcher_downloads_total{network="mainnet"} 1`,
+		`opendex_launcher_download_failures_total{network="mainnet"} 1`,
+		`opendex_launcher_download_bytes_total{network="mainnet"} 1024`,
+		`opendex_launcher_last_update_timestamp_seconds{network="mainnet"} 1614600000`,
+		`opendex_launcher_child_up{network="mainnet"} 1`,
+		`opendex_launcher_child_uptime_seconds{network="mainnet"} 90`,
+		"# TYPE opendex_launcher_child_restarts_total counter",
+	} {
+		assert.Equal(t, strings.Contains(out, line+"\n"), true, line)
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"github.com/pelletier/go-toml"
+	"io/ioutil"
+	"strings"
+)
+
+// CurrentConfigVersion is the config-version written to new config files.
+// Files without config-version are version 0.
+const CurrentConfigVersion = 1
+
+// configMigration upgrades a config file from version from to from+1. It
+// reports whether it changed the tree; migrations which only bump the version
+// keep the file (and its comments) as it is.
+type configMigration struct {
+	from        int
+	description string
+	apply       func(tree *toml.Tree) (bool, error)
+}
+
+var configMigrations = []configMigration{
+	{
+		from:        0,
+		description: "add config-version",
+		apply: func(tree *toml.Tree) (bool, error) {
+			return false, nil
+		},
+	},
+}
+
+func configVersion(tree *toml.Tree) (int, error) {
+	value := tree.Get("config-version")
+	if value == nil {
+		return 0, nil
+	}
+	version, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("config-version is not an integer: %v", value)
+	}
+	return int(version), nil
+}
+
+// setConfigVersion replaces or adds the config-version line without touching
+// the rest of the file.
+func setConfigVersion(data string, version int) string {
+	line := fmt.Sprintf("config-version = %d", version)
+	lines := strings.Split(data, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "config-version") {
+			lines[i] = line
+			return strings.Join(lines, "\n")
+		}
+	}
+	return line + "\n" + data
+}
+
+// migrateConfig upgrades data to CurrentConfigVersion. It returns the
+// version data had and the migrated data.
+func migrateConfig(data []byte) (int, []byte, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return 0, nil, fmt.Errorf("load: %w", err)
+	}
+	from, err := configVersion(tree)
+	if err != nil {
+		return 0, nil, err
+	}
+	if from > CurrentConfigVersion {
+		return from, nil, fmt.Errorf("config version %d is newer than the supported version %d, please update opendex-launcher", from, CurrentConfigVersion)
+	}
+	if from == CurrentConfigVersion {
+		return from, data, nil
+	}
+
+	result := string(data)
+	rewrite := false
+	for _, m := range configMigrations {
+		if m.from < from {
+			continue
+		}
+		changed, err := m.apply(tree)
+		if err != nil {
+			return from, nil, fmt.Errorf("migrate from version %d (%s): %w", m.from, m.description, err)
+		}
+		rewrite = rewrite || changed
+	}
+
+	if rewrite {
+		tree.Set("config-version", int64(CurrentConfigVersion))
+		result, err = tree.ToTomlString()
+		if err != nil {
+			return from, nil, fmt.Errorf("marshal: %w", err)
+		}
+		return from, []byte(result), nil
+	}
+	return from, []byte(setConfigVersion(result, CurrentConfigVersion)), nil
+}
+
+// migrateConfigFile upgrades the config file in place and keeps the original
+// as <file>.v<version>.bak.
+func (t *Launcher) migrateConfigFile(data []byte) ([]byte, error) {
+	from, migrated, err := migrateConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if from == CurrentConfigVersion {
+		return data, nil
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", t.configFile, from)
+	if err := ioutil.WriteFile(backup, data, 0600); err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	if err := ioutil.WriteFile(t.configFile, migrated, 0600); err != nil {
+		return nil, fmt.Errorf("write: %w", err)
+	}
+	t.logger.Infof("Migrated %s from version %d to %d (backup: %s)", t.configFile, from, CurrentConfigVersion, backup)
+	return migrated, nil
+}
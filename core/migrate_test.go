@@ -0,0 +1,44 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"strings"
+	"testing"
+)
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	legacy := `# my config
+[GitHub]
+access-token = "abc123"
+`
+	from, data, err := migrateConfig([]byte(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, from, 0)
+	assert.Equal(t, string(data), "config-version = 1\n"+legacy)
+
+	config, err := parseConfig(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, config.ConfigVersion, CurrentConfigVersion)
+	assert.Equal(t, config.GitHub.AccessToken, "abc123")
+}
+
+func TestMigrateCurrentConfig(t *testing.T) {
+	current := "config-version = 1\n"
+	from, data, err := migrateConfig([]byte(current))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, from, 1)
+	assert.Equal(t, string(data), current)
+}
+
+func TestMigrateNewerConfig(t *testing.T) {
+	_, _, err := migrateConfig([]byte("config-version = 99\n"))
+	assert.Equal(t, err != nil, true)
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// releaseDownloads is the prefix of the download URLs of release assets.
+const releaseDownloads = "https://github.com/opendexnetwork/opendex-docker/releases/download/"
+
+var ErrBadMirror = errors.New("bad mirror")
+
+// parseMirror checks the artifacts.mirror of the config, an http or https
+// base URL, and returns it without a trailing slash.
+func parseMirror(mirror string) (string, error) {
+	if mirror == "" {
+		return "", nil
+	}
+	u, err := url.Parse(mirror)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrBadMirror, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("%w: %s is not an http or https URL", ErrBadMirror, mirror)
+	}
+	return strings.TrimSuffix(mirror, "/"), nil
+}
+
+// mirrored returns the URL to download url from: the same path below the
+// mirror for release assets when a mirror is configured, url otherwise.
+// Sizes, caches and audit records keep using the GitHub URL.
+func (t *GithubClient) mirrored(url string) string {
+	if t.Mirror == "" || !strings.HasPrefix(url, releaseDownloads) {
+		return url
+	}
+	return t.Mirror + "/" + strings.TrimPrefix(url, releaseDownloads)
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestParseMirror(t *testing.T) {
+	mirror, err := parseMirror("https://artifacts.example.com/opendex-releases/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, mirror, "https://artifacts.example.com/opendex-releases")
+
+	for _, mirror := range []string{"artifacts.example.com/opendex", "ftp://artifacts.example.com/opendex", "https:///opendex"} {
+		_, err := parseMirror(mirror)
+		assert.Equal(t, errors.Is(err, ErrBadMirror), true, mirror)
+	}
+}
+
+func TestMirrored(t *testing.T) {
+	c := NewGithubClient()
+	url := releaseDownloads + "v1.2.0/launcher-linux-amd64.zip"
+	assert.Equal(t, c.mirrored(url), url)
+
+	c.Mirror = "https://artifacts.example.com/opendex-releases"
+	assert.Equal(t, c.mirrored(url), "https://artifacts.example.com/opendex-releases/v1.2.0/launcher-linux-amd64.zip")
+	// workflow artifacts are only on GitHub
+	assert.Equal(t, c.mirrored("https://api.github.com/repos/opendexnetwork/opendex-docker/actions/artifacts/1/zip"), "https://api.github.com/repos/opendexnetwork/opendex-docker/actions/artifacts/1/zip")
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat.
+const clockTicks = 100
+
+type Monitor struct {
+	Interval int `toml:"interval" default:"30" comment:"Seconds between two samples of the CPU and memory usage of the launcher process tree"`
+	MaxCPU   int `toml:"max-cpu" default:"0" comment:"Alert when the launcher process tree uses more CPU than this, in percent of one core, 0 to disable"`
+	MaxRSS   int `toml:"max-rss" default:"0" comment:"Alert when the resident memory of the launcher process tree exceeds this many MB, 0 to disable"`
+}
+
+func (t Monitor) enabled() bool {
+	return t.Interval > 0 && (t.MaxCPU > 0 || t.MaxRSS > 0)
+}
+
+// procStat holds the fields of /proc/<pid>/stat the monitor needs.
+type procStat struct {
+	pid   int
+	ppid  int
+	ticks uint64
+}
+
+func parseProcStat(data string) (procStat, error) {
+	var s procStat
+	open := strings.Index(data, "(")
+	end := strings.LastIndex(data, ")")
+	if open < 0 || end < open {
+		return s, fmt.Errorf("malformed stat: %q", data)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(data[:open]))
+	if err != nil {
+		return s, err
+	}
+	fields := strings.Fields(data[end+1:])
+	if len(fields) < 13 {
+		return s, fmt.Errorf("malformed stat: %q", data)
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return s, err
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return s, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return s, err
+	}
+	return procStat{pid: pid, ppid: ppid, ticks: utime + stime}, nil
+}
+
+// processTree returns pid and all of its descendants.
+func processTree(stats []procStat, pid int) []procStat {
+	children := make(map[int][]procStat)
+	var root *procStat
+	for i, s := range stats {
+		children[s.ppid] = append(children[s.ppid], s)
+		if s.pid == pid {
+			root = &stats[i]
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	tree := []procStat{*root}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i].pid]...)
+	}
+	return tree
+}
+
+// sampleTree returns the CPU ticks and the resident memory in bytes of the
+// process tree of pid.
+func sampleTree(pid int) (uint64, int64, error) {
+	dirs, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil {
+		return 0, 0, err
+	}
+	var stats []procStat
+	for _, dir := range dirs {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			// the process exited meanwhile
+			continue
+		}
+		s, err := parseProcStat(string(data))
+		if err != nil {
+			continue
+		}
+		stats = append(stats, s)
+	}
+
+	var ticks uint64
+	var rss int64
+	for _, s := range processTree(stats, pid) {
+		ticks += s.ticks
+		data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/statm", s.pid))
+		if err != nil {
+			continue
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) < 2 {
+			continue
+		}
+		pages, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		rss += pages * int64(os.Getpagesize())
+	}
+	return ticks, rss, nil
+}
+
+// cpuPercent returns the CPU usage of the ticks used since last within
+// interval, in percent of one core. The ticks of a tree drop when a process
+// exits, such samples are not usable.
+func cpuPercent(ticks uint64, last uint64, interval time.Duration) (float64, bool) {
+	if ticks < last {
+		return 0, false
+	}
+	return float64(ticks-last) / clockTicks / interval.Seconds() * 100, true
+}
+
+// monitor samples the running launcher until the process exits and alerts
+// once each time a threshold is crossed.
+func (t *Launcher) monitor() {
+	if runtime.GOOS != "linux" {
+		t.logger.Warnf("Resource monitoring is only supported on Linux")
+		return
+	}
+	cfg := t.config.Monitor
+	interval := time.Duration(cfg.Interval) * time.Second
+
+	var lastPid int
+	var lastTicks uint64
+	var cpuAlert, rssAlert bool
+	for range time.Tick(interval) {
+		status := t.status()
+		if !status.Running {
+			lastPid = 0
+			continue
+		}
+		ticks, rss, err := sampleTree(status.Pid)
+		if err != nil {
+			t.logger.Debugf("Failed to sample resources: %s", err)
+			continue
+		}
+		if status.Pid != lastPid {
+			lastPid, lastTicks = status.Pid, ticks
+			cpuAlert, rssAlert = false, false
+			continue
+		}
+		cpu, ok := cpuPercent(ticks, lastTicks, interval)
+		lastTicks = ticks
+		if !ok {
+			continue
+		}
+
+		if cfg.MaxCPU > 0 {
+			exceeded := cpu > float64(cfg.MaxCPU)
+			if exceeded && !cpuAlert {
+				t.alertResource(status.Commit, fmt.Sprintf("CPU usage %.0f%% exceeds %d%%", cpu, cfg.MaxCPU))
+			}
+			cpuAlert = exceeded
+		}
+		if cfg.MaxRSS > 0 {
+			mb := rss / 1024 / 1024
+			exceeded := mb > int64(cfg.MaxRSS)
+			if exceeded && !rssAlert {
+				t.alertResource(status.Commit, fmt.Sprintf("memory usage %d MB exceeds %d MB", mb, cfg.MaxRSS))
+			}
+			rssAlert = exceeded
+		}
+	}
+}
+
+func (t *Launcher) alertResource(commit string, message string) {
+	t.logger.Warnf("Launcher %s", message)
+	t.emit(Event{Type: EventResourceAlert, Commit: commit, Message: message})
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestParseProcStat(t *testing.T) {
+	s, err := parseProcStat("1234 (docker compose) S 1000 1234 1234 0 -1 4194560 100 0 0 0 250 50 0 0 20 0 1 0 100 1000 200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, s, procStat{pid: 1234, ppid: 1000, ticks: 300})
+}
+
+func TestProcessTree(t *testing.T) {
+	stats := []procStat{
+		{pid: 1, ppid: 0},
+		{pid: 10, ppid: 1},
+		{pid: 11, ppid: 10},
+		{pid: 12, ppid: 11},
+		{pid: 20, ppid: 1},
+	}
+	var pids []int
+	for _, s := range processTree(stats, 10) {
+		pids = append(pids, s.pid)
+	}
+	assert.Equal(t, pids, []int{10, 11, 12})
+}
+
+func TestCpuPercent(t *testing.T) {
+	cpu, ok := cpuPercent(250, 150, time.Second)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, cpu, float64(100))
+
+	// a descendant exited, the total dropped
+	_, ok = cpuPercent(150, 250, time.Second)
+	assert.Equal(t, ok, false)
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"github.com/mitchellh/go-homedir"
+	"path/filepath"
+)
+
+// networks are the networks a data dir can be configured for.
+var networks = []string{"simnet", "testnet", "mainnet"}
+
+// configuredNetworkDir returns the <network>-dir of the config, or "".
+func (t *Launcher) configuredNetworkDir(network string) string {
+	if t.config == nil {
+		return ""
+	}
+	switch network {
+	case "simnet":
+		return t.config.SimnetDir
+	case "testnet":
+		return t.config.TestnetDir
+	case "mainnet":
+		return t.config.MainnetDir
+	default:
+		return ""
+	}
+}
+
+// networkDirOf returns the data dir of network, by default the network below
+// the home dir. A <network>-dir of the config, e.g. on a larger disk for the
+// chain data of mainnet, may be relative to the home dir. No two networks
+// may share a data dir.
+func (t *Launcher) networkDirOf(network string) (string, error) {
+	resolve := func(network string) (string, error) {
+		dir := t.configuredNetworkDir(network)
+		if dir == "" {
+			return filepath.Join(t.homeDir, network), nil
+		}
+		dir, err := homedir.Expand(dir)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(t.homeDir, dir)
+		}
+		return filepath.Clean(dir), nil
+	}
+
+	dir, err := resolve(network)
+	if err != nil || t.configuredNetworkDir(network) == "" {
+		return dir, err
+	}
+	for _, other := range networks {
+		if other == network {
+			continue
+		}
+		otherDir, err := resolve(other)
+		if err != nil {
+			return "", err
+		}
+		if otherDir == dir {
+			return "", fmt.Errorf("%s-dir %s is the data dir of %s as well", network, dir, other)
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNetworkDirOf(t *testing.T) {
+	home, err := ioutil.TempDir("", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	disk := filepath.Join(home, "disk")
+
+	l := &Launcher{homeDir: home, config: DefaultConfig()}
+	dir, err := l.networkDirOf("testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dir, filepath.Join(home, "testnet"))
+
+	l.config.MainnetDir = disk
+	l.config.TestnetDir = "chains/testnet"
+	dir, err = l.networkDirOf("mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dir, disk)
+	dir, err = l.networkDirOf("testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dir, filepath.Join(home, "chains", "testnet"))
+
+	l.network = "mainnet"
+	if err := l.ensureNetworkDir(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, l.networkDir, disk)
+	_, err = os.Stat(disk)
+	assert.Equal(t, err, nil)
+
+	l.config.TestnetDir = disk + string(filepath.Separator)
+	_, err = l.networkDirOf("mainnet")
+	assert.Equal(t, err != nil, true)
+	l.config.MainnetDir = filepath.Join(home, "simnet")
+	l.config.TestnetDir = ""
+	_, err = l.networkDirOf("mainnet")
+	assert.Equal(t, err != nil, true)
+}
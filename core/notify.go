@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type Notifications struct {
+	Desktop bool `toml:"desktop" default:"false" comment:"Show native desktop notifications for installed updates and crashes"`
+}
+
+const windowsToastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName("text")
+$text.Item(0).AppendChild($template.CreateTextNode('%s')) | Out-Null
+$text.Item(1).AppendChild($template.CreateTextNode('%s')) | Out-Null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('opendex-launcher').Show($toast)
+`
+
+const (
+	// desktopQueueSize is the number of notifications waiting to be shown
+	// before new ones are dropped
+	desktopQueueSize = 8
+	// desktopTimeout bounds a notification command which hangs, e.g. on a
+	// missing session bus
+	desktopTimeout = 10 * time.Second
+)
+
+type desktopNotifier struct {
+	logger *logrus.Entry
+	queue  chan Event
+}
+
+func newDesktopNotifier() *desktopNotifier {
+	t := &desktopNotifier{
+		logger: logrus.NewEntry(logrus.StandardLogger()).WithField("name", "notify"),
+		queue:  make(chan Event, desktopQueueSize),
+	}
+	go t.loop()
+	return t
+}
+
+func (t *desktopNotifier) command(ctx context.Context, title string, message string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return exec.CommandContext(ctx, "notify-send", "--app-name", title, title, message), nil
+	case "darwin":
+		quote := func(s string) string {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+		}
+		script := fmt.Sprintf("display notification %s with title %s", quote(message), quote(title))
+		return exec.CommandContext(ctx, "osascript", "-e", script), nil
+	case "windows":
+		quote := func(s string) string {
+			return strings.ReplaceAll(s, "'", "''")
+		}
+		script := fmt.Sprintf(windowsToastScript, quote(title), quote(message))
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+}
+
+func (t *desktopNotifier) show(e Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), desktopTimeout)
+	defer cancel()
+	cmd, err := t.command(ctx, "opendex-launcher", e.String())
+	if err != nil {
+		t.logger.Debugf("Skip desktop notification: %s", err)
+		return
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.logger.Debugf("Desktop notification failed: %s: %s", err, output)
+	}
+}
+
+func (t *desktopNotifier) loop() {
+	for e := range t.queue {
+		t.show(e)
+	}
+}
+
+// handle runs under emitMu, so the notification command runs in the
+// background
+func (t *desktopNotifier) handle(e Event) {
+	if !e.Type.notable() {
+		return
+	}
+	select {
+	case t.queue <- e:
+	default:
+		t.logger.Debugf("Dropped %s desktop notification", e.Type)
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// Option configures a Launcher created by NewLauncher.
+type Option func(t *Launcher)
+
+type options struct {
+	network    string
+	branch     string
+	homeDir    string
+	httpClient *http.Client
+}
+
+// WithNetwork sets the network to run, like --network.
+func WithNetwork(network string) Option {
+	return func(t *Launcher) {
+		t.options.network = network
+	}
+}
+
+// WithBranch sets the branch, release tag or release channel to run, like
+// --branch.
+func WithBranch(branch string) Option {
+	return func(t *Launcher) {
+		t.options.branch = branch
+	}
+}
+
+// WithHomeDir sets the opendex-docker home directory, which holds the config
+// file, the installed versions and the network data.
+func WithHomeDir(dir string) Option {
+	return func(t *Launcher) {
+		t.options.homeDir = dir
+	}
+}
+
+// WithHTTPClient sets the client of the requests to GitHub. The http and
+// proxy sections of the config file do not apply to it.
+func WithHTTPClient(client *http.Client) Option {
+	return func(t *Launcher) {
+		t.options.httpClient = client
+	}
+}
+
+// WithLogger sets the logger of the launcher.
+func WithLogger(logger *logrus.Entry) Option {
+	return func(t *Launcher) {
+		t.logger = logger
+	}
+}
+
+// optionSetting replaces s by value given as an option unless s was given as
+// a flag, which takes precedence over options.
+func optionSetting(s Setting, option string, value string) Setting {
+	if s.Source == SourceFlag || value == "" {
+		return s
+	}
+	return Setting{Name: s.Name, Value: value, Source: SourceOption, Origin: option}
+}
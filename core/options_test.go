@@ -0,0 +1,33 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	home, err := ioutil.TempDir("", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	client := &http.Client{}
+
+	l := NewLauncher(WithNetwork("simnet"), WithBranch("feat/foo"), WithHomeDir(home), WithHTTPClient(client))
+	if err := l.load(nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, l.network, "simnet")
+	assert.Equal(t, l.homeDir, home)
+	assert.Equal(t, l.resolveBranch(), Setting{Name: "branch", Value: "feat/foo", Source: SourceOption, Origin: "WithBranch"})
+	if err := l.setupGithub(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, l.github.Client == client, true)
+
+	l.args.network = "testnet"
+	assert.Equal(t, l.resolveNetwork().Value, "testnet", "flags take precedence over options")
+}
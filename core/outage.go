@@ -0,0 +1,165 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/utils"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// githubStatusURL is the summary of the status page of GitHub.
+const githubStatusURL = "https://www.githubstatus.com/api/v2/summary.json"
+
+// statusTimeout bounds asking the status page, which may be down as well.
+const statusTimeout = 10 * time.Second
+
+// outageComponents are the components of the status page the launcher
+// depends on.
+var outageComponents = []string{"Actions", "API Requests"}
+
+type statusSummary struct {
+	Components []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"components"`
+	Incidents []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"incidents"`
+}
+
+// incident describes the disruption of a component the launcher depends on,
+// "" when they are all operational.
+func (s statusSummary) incident() string {
+	for _, c := range s.Components {
+		if c.Status == "operational" || !containsString(outageComponents, c.Name) {
+			continue
+		}
+		description := fmt.Sprintf("GitHub %s is having an incident (%s)", c.Name, strings.ReplaceAll(c.Status, "_", " "))
+		for _, i := range s.Incidents {
+			if i.Status != "resolved" && i.Status != "postmortem" {
+				description += ": " + i.Name
+				break
+			}
+		}
+		return description
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isServerError reports whether err is an error response of GitHub itself.
+func isServerError(err error) bool {
+	var respErr *ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode >= 500
+}
+
+// statusClient returns a client for the status page. The client of GitHub
+// is bound to the start deadline, which may have expired already.
+func (t *Launcher) statusClient() (*http.Client, error) {
+	var client http.Client
+	if t.options.httpClient != nil {
+		client = *t.options.httpClient
+	} else {
+		c, err := t.httpClient()
+		if err != nil {
+			return nil, err
+		}
+		client = *c
+	}
+	client.Timeout = statusTimeout
+	return &client, nil
+}
+
+// githubIncident asks the status page of GitHub for an incident. It reports
+// false when the status page cannot be reached either.
+func (t *Launcher) githubIncident() (string, bool) {
+	client, err := t.statusClient()
+	if err != nil {
+		return "", false
+	}
+	req, err := http.NewRequestWithContext(t.baseContext(), "GET", githubStatusURL, nil)
+	if err != nil {
+		return "", false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.logger.Debugf("Get GitHub status: %s", err)
+		return "", false
+	}
+	defer resp.Body.Close()
+	var summary statusSummary
+	if resp.StatusCode != http.StatusOK {
+		t.logger.Debugf("Get GitHub status: %s", resp.Status)
+		return "", false
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		t.logger.Debugf("Decode GitHub status: %s", err)
+		return "", false
+	}
+	return summary.incident(), true
+}
+
+// cachedVersion returns the most recently used installed version of the
+// branch, or "" when none is installed.
+func (t *Launcher) cachedVersion() string {
+	versions, err := t.installedVersions()
+	if err != nil {
+		return ""
+	}
+	var cached InstalledVersion
+	for _, v := range versions {
+		m, err := readMetadata(filepath.Dir(v.Path))
+		if err != nil || m.Branch != t.branch {
+			continue
+		}
+		if exists, _ := utils.FileExists(v.Path); !exists {
+			continue
+		}
+		if cached.Commit == "" || lastUsed(v).After(lastUsed(cached)) {
+			cached = v
+		}
+	}
+	return cached.Commit
+}
+
+// outageFallback runs the most recently used installed version of the branch
+// when err is caused by an outage of GitHub rather than the local network,
+// telling the user so instead of the raw error. The version goes through the
+// checks of ensureLauncher like any other. It returns err otherwise.
+func (t *Launcher) outageFallback(err error) (string, string, error) {
+	if !isServerError(err) && !isNetworkError(err) {
+		return "", "", err
+	}
+	incident, ok := t.githubIncident()
+	if !ok || incident == "" && !isServerError(err) {
+		// the network fails to reach GitHub, diagnosed by diagnoseNetwork
+		return "", "", err
+	}
+	if incident == "" {
+		incident = "GitHub is failing"
+	}
+	commit := t.cachedVersion()
+	if commit == "" {
+		return "", "", fmt.Errorf("%s and no launcher of %s is installed: %w", incident, t.branch, err)
+	}
+	// installed versions are checked without contacting GitHub
+	launcher, checkErr := t.ensureLauncher(commit)
+	if checkErr != nil {
+		return "", "", checkErr
+	}
+	t.logger.Warnf("%s — using the cached launcher %s (%s)", incident, shortCommit(commit), err)
+	return launcher, commit, nil
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testStatusSummary = `{
+  "components": [
+    {"name": "Git Operations", "status": "operational"},
+    {"name": "Actions", "status": "major_outage"}
+  ],
+  "incidents": [
+    {"name": "Incident with Actions", "status": "investigating"}
+  ]
+}`
+
+func TestStatusIncident(t *testing.T) {
+	var s statusSummary
+	if err := json.Unmarshal([]byte(testStatusSummary), &s); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, s.incident(), "GitHub Actions is having an incident (major outage): Incident with Actions")
+
+	s.Components[1].Status = "operational"
+	assert.Equal(t, s.incident(), "")
+}
+
+func TestIsServerError(t *testing.T) {
+	assert.Equal(t, isServerError(fmt.Errorf("get branch head: %w", &ResponseError{StatusCode: http.StatusBadGateway})), true)
+	assert.Equal(t, isServerError(&ResponseError{StatusCode: http.StatusNotFound}), false)
+	assert.Equal(t, isServerError(errors.New("502")), false)
+
+	c := NewGithubClient()
+	resp := &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway", Body: ioutil.NopCloser(strings.NewReader("<html>Unicorn!</html>"))}
+	assert.Equal(t, isServerError(c.getResponseError(resp)), true)
+}
+
+func TestOutageFallback(t *testing.T) {
+	home, err := ioutil.TempDir("", "outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	status := testStatusSummary
+	l := &Launcher{
+		branch:              "master",
+		homeDir:             home,
+		launcherDir:         home,
+		launcherVersionsDir: filepath.Join(home, "versions"),
+		config:              DefaultConfig(),
+		logger:              logrus.NewEntry(logrus.New()),
+		github:              NewGithubClient(),
+	}
+	l.options.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(status)), Header: make(http.Header)}, nil
+	})}
+	// the client of GitHub is never used for the status page
+	l.github.Client = forbiddenClient()
+	outage := fmt.Errorf("get branch head: %w", &ResponseError{StatusCode: http.StatusBadGateway, Message: "502 Bad Gateway"})
+
+	_, _, err = l.outageFallback(outage)
+	assert.Equal(t, strings.Contains(err.Error(), "no launcher of master is installed"), true)
+
+	for _, v := range []struct{ commit, branch string }{{"bbb", "feat/foo"}} {
+		dir := filepath.Join(l.launcherVersionsDir, v.commit)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, launcherBinary()), []byte("launcher"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeMetadata(dir, &VersionMetadata{Branch: v.branch, Commit: v.commit}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// never a version of another branch
+	_, _, err = l.outageFallback(outage)
+	assert.Equal(t, strings.Contains(err.Error(), "no launcher of master is installed"), true)
+
+	dir := filepath.Join(l.launcherVersionsDir, "aaa")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, launcherBinary()), []byte("launcher"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMetadata(dir, &VersionMetadata{Branch: "master", Commit: "aaa"}); err != nil {
+		t.Fatal(err)
+	}
+	launcher, commit, err := l.outageFallback(outage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, commit, "aaa")
+	assert.Equal(t, launcher, l.launcherPath("aaa"))
+
+	// the cached version is checked like any installed one
+	l.config.Enterprise.Enabled = true
+	_, _, err = l.outageFallback(outage)
+	assert.Equal(t, errors.Is(err, ErrEnterprise), true)
+
+	// errors other than outages are returned
+	_, _, err = l.outageFallback(ErrNotFound)
+	assert.Equal(t, err, ErrNotFound)
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lineBuffer keeps the last lines written to it.
+type lineBuffer struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial []byte
+}
+
+func newLineBuffer(max int) *lineBuffer {
+	return &lineBuffer{max: max}
+}
+
+func (t *lineBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		t.lines = append(t.lines, strings.TrimRight(string(data[:i]), "\r"))
+		data = data[i+1:]
+	}
+	t.partial = append([]byte(nil), data...)
+	if len(t.lines) > t.max {
+		t.lines = append([]string(nil), t.lines[len(t.lines)-t.max:]...)
+	}
+	return len(p), nil
+}
+
+// Tail returns up to n of the last complete lines.
+func (t *lineBuffer) Tail(n int) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n <= 0 || n > len(t.lines) {
+		n = len(t.lines)
+	}
+	return append([]string(nil), t.lines[len(t.lines)-n:]...)
+}
+
+// prefixWriter writes every line written to it to w, prefixed with the
+// network and the time the line was completed.
+type prefixWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	network string
+	now     func() time.Time
+	partial []byte
+}
+
+func newPrefixWriter(w io.Writer, network string) *prefixWriter {
+	return &prefixWriter{w: w, network: network, now: time.Now}
+}
+
+func (t *prefixWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if err := t.writeLine(data[:i+1]); err != nil {
+			return 0, err
+		}
+		data = data[i+1:]
+	}
+	t.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+func (t *prefixWriter) writeLine(line []byte) error {
+	prefix := fmt.Sprintf("[%s] %s ", t.network, t.now().UTC().Format(time.RFC3339))
+	_, err := t.w.Write(append([]byte(prefix), line...))
+	return err
+}
+
+// Flush writes out an incomplete last line.
+func (t *prefixWriter) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.partial) == 0 {
+		return nil
+	}
+	err := t.writeLine(append(t.partial, '\n'))
+	t.partial = nil
+	return err
+}
+
+// isTerminal reports whether f is a terminal, or another character device.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"bytes"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := newPrefixWriter(&b, "mainnet")
+	w.now = func() time.Time {
+		return time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	_, _ = w.Write([]byte("first\nsec"))
+	_, _ = w.Write([]byte("ond\n"))
+	_, _ = w.Write([]byte("Continue? "))
+	assert.Equal(t, b.String(), "[mainnet] 2021-03-01T12:00:00Z first\n[mainnet] 2021-03-01T12:00:00Z second\n")
+
+	_ = w.Flush()
+	assert.Equal(t, b.String(), "[mainnet] 2021-03-01T12:00:00Z first\n[mainnet] 2021-03-01T12:00:00Z second\n[mainnet] 2021-03-01T12:00:00Z Continue? \n")
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pelletier/go-toml"
+	"strings"
+)
+
+// overrideFlag collects the repeatable -o flag.
+type overrideFlag []string
+
+func (f *overrideFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *overrideFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// overrideValue parses value for key, as a TOML value unless key is a
+// string, where quotes are optional.
+func overrideValue(key ConfigKey, value string) (interface{}, error) {
+	tree, err := toml.Load("v = " + value)
+	if key.Type == "string" {
+		if err == nil {
+			if s, ok := tree.Get("v").(string); ok {
+				return s, nil
+			}
+		}
+		return value, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid %s", value, key.Type)
+	}
+	return tree.Get("v"), nil
+}
+
+// overrideConfig sets the section.key=value overrides in the config file
+// data.
+func overrideConfig(data []byte, overrides []string) ([]byte, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]ConfigKey)
+	var names []string
+	for _, key := range ConfigKeys() {
+		keys[strings.ToLower(key.Key)] = key
+		names = append(names, key.Key)
+	}
+	for _, override := range overrides {
+		i := strings.Index(override, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%s: expected key=value", override)
+		}
+		name, value := strings.TrimSpace(override[:i]), strings.TrimSpace(override[i+1:])
+		key, ok := keys[strings.ToLower(name)]
+		if !ok {
+			return nil, unknownConfigKey{Key: name, Suggestion: nearestKey(name, names)}
+		}
+		v, err := overrideValue(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key.Key, err)
+		}
+		tree.SetPath(strings.Split(key.Key, "."), v)
+	}
+	return tree.Marshal()
+}
+
+// applyConfigOverrides replaces the config by the config file data with the
+// -o overrides set.
+func (t *Launcher) applyConfigOverrides(data []byte) error {
+	if t.args == nil || len(t.args.overrides) == 0 {
+		return nil
+	}
+	data, err := overrideConfig(data, t.args.overrides)
+	if err != nil {
+		return fmt.Errorf("-o: %w", err)
+	}
+	c, err := parseConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("-o: %w", err)
+	}
+	t.setConfig(c)
+	return nil
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestOverrideConfig(t *testing.T) {
+	data, err := overrideConfig([]byte("simnet-dir = \"/data\"\n[GitHub]\nretry-budget = 10\n"), []string{
+		"github.graphql=false",
+		"GitHub.retry-budget = 30",
+		"testnet-dir=/mnt/testnet",
+		"webhook.url=\"https://example.com/hook\"",
+		`webhook.events=["installed"]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := parseConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, c.SimnetDir, "/data")
+	assert.Equal(t, c.TestnetDir, "/mnt/testnet")
+	assert.Equal(t, c.GitHub.GraphQL, false)
+	assert.Equal(t, c.GitHub.RetryBudget, 30)
+	assert.Equal(t, c.GitHub.ValidateToken, true)
+	assert.Equal(t, c.Webhook.Url, "https://example.com/hook")
+	assert.Equal(t, c.Webhook.Events, []string{"installed"})
+}
+
+func TestOverrideConfigErrors(t *testing.T) {
+	_, err := overrideConfig(nil, []string{"GitHub.grapql=false"})
+	assert.Equal(t, errors.Is(err, ErrUnknownConfigKey), true)
+	assert.Equal(t, err.Error(), "unknown config key: GitHub.grapql (did you mean GitHub.graphql?)")
+
+	_, err = overrideConfig(nil, []string{"GitHub.retry-budget=soon"})
+	assert.Equal(t, err.Error(), "GitHub.retry-budget: soon is not a valid integer")
+
+	_, err = overrideConfig(nil, []string{"GitHub.graphql"})
+	assert.Equal(t, err.Error(), "GitHub.graphql: expected key=value")
+}
+
+func TestParseArgsOverrides(t *testing.T) {
+	a, err := parseArgs([]string{"-o", "GitHub.graphql=false", "-o=cache.max-size=10", "setup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string(a.overrides), []string{"GitHub.graphql=false", "cache.max-size=10"})
+	assert.Equal(t, a.rest, []string{"setup"})
+}
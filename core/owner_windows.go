@@ -0,0 +1,7 @@
+package core
+
+// ownedByCurrentUser tells whether the current user owns path. The shared
+// cache is not shared between users on Windows.
+func ownedByCurrentUser(path string) bool {
+	return true
+}
@@ -0,0 +1,54 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"golang.org/x/sys/unix"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a process group of its own, so that the
+// whole tree of processes it starts can be signalled at once. When stdin is
+// a terminal, the group becomes its foreground group and keeps receiving
+// Ctrl-C and terminal input. It returns the previous foreground group, or 0.
+func setProcessGroup(cmd *exec.Cmd) int {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	pgrp, err := unix.IoctlGetInt(int(os.Stdin.Fd()), unix.TIOCGPGRP)
+	if err != nil {
+		return 0
+	}
+	cmd.SysProcAttr.Foreground = true
+	// Ctty is a descriptor of the child, where stdin is 0
+	cmd.SysProcAttr.Ctty = 0
+	return pgrp
+}
+
+// restoreForeground gives the terminal back to the process group pgrp after
+// the launcher exited.
+func restoreForeground(pgrp int) {
+	if pgrp == 0 {
+		return
+	}
+	// a background group changing the foreground group gets SIGTTOU
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(int(os.Stdin.Fd()), unix.TIOCSPGRP, pgrp)
+}
+
+// signalGroup sends sig to the process group led by pid.
+func signalGroup(pid int, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		s = syscall.SIGINT
+	}
+	return syscall.Kill(-pid, s)
+}
+
+var shutdownSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
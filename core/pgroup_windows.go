@@ -0,0 +1,41 @@
+package core
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a console process group of its own, so that
+// Ctrl-Break can be sent to the launcher tree alone. The Job Object ties the
+// tree to opendex-launcher.
+func setProcessGroup(cmd *exec.Cmd) int {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_NEW_PROCESS_GROUP
+	return 0
+}
+
+func restoreForeground(pgrp int) {
+}
+
+// signalGroup sends Ctrl-Break to the process group led by pid, which Go
+// programs receive as os.Interrupt, or kills the leader for os.Kill. Its
+// children go away with the Job Object. A group without a console, e.g. of a
+// detached opendex-launcher, cannot get Ctrl-Break and is killed as well.
+func signalGroup(pid int, sig os.Signal) error {
+	if sig != os.Kill {
+		if err := windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(pid)); err == nil {
+			return nil
+		}
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
+
+var shutdownSignals = []os.Signal{os.Interrupt}
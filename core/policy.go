@@ -0,0 +1,151 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PolicyFilename is the policy file used when artifacts.policy-file is not
+// set, in the system config directory.
+const PolicyFilename = "policy.json"
+
+// systemPolicyFile is the policy file used when artifacts.policy-file is not
+// set. It is outside the home directory, which the user running the
+// launcher can write to, so only an administrator can change it.
+var systemPolicyFile = defaultSystemPolicyFile()
+
+func defaultSystemPolicyFile() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "opendex-launcher", PolicyFilename)
+	}
+	return filepath.Join("/etc/opendex-launcher", PolicyFilename)
+}
+
+var ErrNotApproved = errors.New("launcher is not approved by the policy")
+
+// Policy lists the launchers approved for change-controlled deployments,
+// e.g.
+//
+//	{"sha256": ["<launcher hash>"], "keys": ["/etc/opendex/release.pub"]}
+//
+// A launcher is approved when its hash is listed or its release archive is
+// signed by one of the cosign public keys.
+type Policy struct {
+	Sha256 []string `json:"sha256"`
+	Keys   []string `json:"keys"`
+}
+
+// policyFile returns the policy file and whether it must exist.
+func (t *Launcher) policyFile() (string, bool) {
+	if file := t.config.Artifacts.PolicyFile; file != "" {
+		return file, true
+	}
+	return systemPolicyFile, false
+}
+
+// policy reads the policy file, nil when there is none.
+func (t *Launcher) policy() (*Policy, error) {
+	file, required := t.policyFile()
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", filepath.Base(file), err)
+	}
+	return &p, nil
+}
+
+// keyFingerprint identifies a public key by the start of its sha256.
+func keyFingerprint(key string) (string, error) {
+	hash, err := sha256File(key)
+	if err != nil {
+		return "", err
+	}
+	return hash[:16], nil
+}
+
+// approves reports whether the launcher with hash, verified with cosign as
+// signature records, is approved by the policy.
+func (p *Policy) approves(hash string, signature string) bool {
+	for _, approved := range p.Sha256 {
+		if strings.EqualFold(approved, hash) {
+			return true
+		}
+	}
+	fingerprint := strings.TrimPrefix(signature, CosignKey+":")
+	if fingerprint == signature {
+		return false
+	}
+	for _, key := range p.Keys {
+		if f, err := keyFingerprint(key); err == nil && f == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPolicy refuses to run the launcher of commit unless the policy
+// approves it. A launcher installed just now is removed again.
+func (t *Launcher) checkPolicy(commit string, launcher string, installed bool) error {
+	p, err := t.policy()
+	if err != nil || p == nil {
+		return err
+	}
+	hash, err := sha256File(launcher)
+	if err != nil {
+		return fmt.Errorf("hash: %w", err)
+	}
+	m, err := readMetadata(filepath.Dir(launcher))
+	if err != nil {
+		return err
+	}
+	if p.approves(hash, m.Signature) {
+		return nil
+	}
+	err = fmt.Errorf("%w: launcher of %s has sha256 %s", ErrNotApproved, shortCommit(commit), hash)
+	t.logger.Errorf("%s", err)
+	if installed {
+		if err := os.RemoveAll(filepath.Dir(launcher)); err != nil {
+			t.logger.Warnf("Failed to remove %s: %s", filepath.Dir(launcher), err)
+		} else {
+			t.audit(AuditRecord{Action: AuditPurged, Commit: commit, Sha256: hash, Detail: "not approved by the policy"})
+		}
+	}
+	return err
+}
+
+// verifyPolicyKeys checks the signature bundle of the release asset file
+// against the keys of the policy. It returns the cosign mode with the
+// fingerprint of the key that signed file, or "" when none did.
+func (t *Launcher) verifyPolicyKeys(p *Policy, file string, bundle string) string {
+	for _, key := range p.Keys {
+		c := t.config.Cosign
+		c.Key = key
+		args, err := c.verifyBlobArgs(CosignKey, file, bundle)
+		if err != nil {
+			continue
+		}
+		if output, err := t.runCosign(args); err != nil {
+			t.logger.Debugf("Not signed by %s: %s: %s", key, err, output)
+			continue
+		}
+		fingerprint, err := keyFingerprint(key)
+		if err != nil {
+			t.logger.Warnf("Failed to read policy key %s: %s", key, err)
+			continue
+		}
+		return CosignKey + ":" + fingerprint
+	}
+	return ""
+}
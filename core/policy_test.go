@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicyApproves(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key := filepath.Join(dir, "release.pub")
+	if err := ioutil.WriteFile(key, []byte("public key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := keyFingerprint(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Policy{Sha256: []string{"ABCDEF"}, Keys: []string{key}}
+	assert.Equal(t, p.approves("abcdef", ""), true)
+	assert.Equal(t, p.approves("012345", ""), false)
+	assert.Equal(t, p.approves("012345", CosignKey), false)
+	assert.Equal(t, p.approves("012345", CosignKey+":"+fingerprint), true)
+	assert.Equal(t, p.approves("012345", CosignKey+":0000000000000000"), false)
+}
+
+func TestCheckPolicy(t *testing.T) {
+	home, err := ioutil.TempDir("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	versionDir := filepath.Join(home, "versions", "0123456")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	launcher := filepath.Join(versionDir, "launcher")
+	if err := ioutil.WriteFile(launcher, []byte("launcher"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := sha256File(launcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Launcher{homeDir: home, config: DefaultConfig(), logger: logrus.NewEntry(logrus.New())}
+	policy := filepath.Join(home, PolicyFilename)
+	defer func(file string) { systemPolicyFile = file }(systemPolicyFile)
+	systemPolicyFile = policy
+	assert.Equal(t, l.checkPolicy("0123456", launcher, true), nil)
+
+	if err := ioutil.WriteFile(policy, []byte(`{"sha256": ["`+hash+`"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, l.checkPolicy("0123456", launcher, true), nil)
+
+	if err := ioutil.WriteFile(policy, []byte(`{"sha256": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = l.checkPolicy("0123456", launcher, false)
+	assert.Equal(t, errors.Is(err, ErrNotApproved), true)
+	_, err = os.Stat(launcher)
+	assert.Equal(t, err, nil)
+
+	// a policy.json in the home directory is only used when configured
+	systemPolicyFile = filepath.Join(home, "etc", PolicyFilename)
+	assert.Equal(t, l.checkPolicy("0123456", launcher, true), nil)
+	l.config.Artifacts.PolicyFile = policy
+	err = l.checkPolicy("0123456", launcher, true)
+	assert.Equal(t, errors.Is(err, ErrNotApproved), true)
+	_, err = os.Stat(versionDir)
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	l.config.Artifacts.PolicyFile = filepath.Join(home, "missing.json")
+	_, err = l.policy()
+	assert.Equal(t, err != nil, true)
+}
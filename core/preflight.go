@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// githubHost is the host every GitHub API request goes to first.
+const githubHost = "api.github.com"
+
+// networkCheck is the outcome of one layer of the network preflight.
+type networkCheck struct {
+	Layer  string
+	Target string
+	Err    error
+}
+
+func (c networkCheck) String() string {
+	if c.Err != nil {
+		return fmt.Sprintf("%s %s: %s", c.Layer, c.Target, c.Err)
+	}
+	return fmt.Sprintf("%s %s: ok", c.Layer, c.Target)
+}
+
+// networkPreflight checks the layers below a HTTPS request one after the
+// other: the proxy, DNS resolution, the TCP connection and the TLS
+// handshake.
+type networkPreflight struct {
+	lookup  func(ctx context.Context, host string) ([]string, error)
+	dial    func(ctx context.Context, network string, address string) (net.Conn, error)
+	tls     *tls.Config
+	proxy   func(req *http.Request) (*url.URL, error)
+	timeout time.Duration
+}
+
+func newNetworkPreflight() *networkPreflight {
+	dialer := &net.Dialer{}
+	return &networkPreflight{
+		lookup:  net.DefaultResolver.LookupHost,
+		dial:    dialer.DialContext,
+		proxy:   http.ProxyFromEnvironment,
+		timeout: 5 * time.Second,
+	}
+}
+
+// run checks the way to host:port and stops at the first layer that fails.
+func (p *networkPreflight) run(host string, port string) []networkCheck {
+	var checks []networkCheck
+	check := func(layer string, target string, f func(ctx context.Context) error) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		defer cancel()
+		c := networkCheck{Layer: layer, Target: target, Err: f(ctx)}
+		checks = append(checks, c)
+		return c.Err == nil
+	}
+
+	address := net.JoinHostPort(host, port)
+	req, err := http.NewRequest("GET", "https://"+address, nil)
+	if err != nil {
+		return append(checks, networkCheck{Layer: "request", Target: address, Err: err})
+	}
+	proxy, err := p.proxy(req)
+	if err != nil {
+		return append(checks, networkCheck{Layer: "proxy", Target: address, Err: err})
+	}
+	if proxy != nil {
+		// the proxy resolves and connects to host itself
+		proxyHost := proxy.Hostname()
+		proxyPort := proxy.Port()
+		if proxyPort == "" {
+			switch proxy.Scheme {
+			case "https":
+				proxyPort = "443"
+			case "socks5":
+				proxyPort = "1080"
+			default:
+				proxyPort = "80"
+			}
+		}
+		if !check("dns", proxyHost, func(ctx context.Context) error {
+			_, err := p.lookup(ctx, proxyHost)
+			return err
+		}) {
+			return checks
+		}
+		check("proxy", net.JoinHostPort(proxyHost, proxyPort), func(ctx context.Context) error {
+			conn, err := p.dial(ctx, "tcp", net.JoinHostPort(proxyHost, proxyPort))
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		})
+		return checks
+	}
+
+	if !check("dns", host, func(ctx context.Context) error {
+		_, err := p.lookup(ctx, host)
+		return err
+	}) {
+		return checks
+	}
+	var conn net.Conn
+	if !check("tcp", address, func(ctx context.Context) error {
+		conn, err = p.dial(ctx, "tcp", address)
+		return err
+	}) {
+		return checks
+	}
+	defer conn.Close()
+	check("tls", address, func(ctx context.Context) error {
+		config := &tls.Config{ServerName: host}
+		if p.tls != nil {
+			config = p.tls.Clone()
+			config.ServerName = host
+		}
+		client := tls.Client(conn, config)
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		return client.Handshake()
+	})
+	return checks
+}
+
+// networkDiagnosis explains in one sentence which layer of checks failed.
+func networkDiagnosis(checks []networkCheck) string {
+	for _, c := range checks {
+		if c.Err == nil {
+			continue
+		}
+		switch c.Layer {
+		case "dns":
+			return fmt.Sprintf("DNS resolution of %s failed, check the DNS settings of this machine", c.Target)
+		case "proxy":
+			return fmt.Sprintf("the proxy %s is not reachable, check HTTPS_PROXY or proxy.socks5", c.Target)
+		case "tcp":
+			return fmt.Sprintf("%s resolved but does not accept connections, check the firewall or the internet connection", c.Target)
+		case "tls":
+			return fmt.Sprintf("the TLS handshake with %s failed, a proxy or firewall may intercept HTTPS", c.Target)
+		default:
+			return c.String()
+		}
+	}
+	return "the network looks fine, GitHub may be down, see https://www.githubstatus.com"
+}
+
+// isNetworkError tells whether err is a failure to reach a server rather
+// than an error response or an expired deadline.
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// diagnoseNetwork runs the network preflight when err failed to reach
+// GitHub and logs which layer is broken.
+func (t *Launcher) diagnoseNetwork(err error) {
+	if err == nil || !isNetworkError(err) {
+		return
+	}
+	p := newNetworkPreflight()
+	if dialer, err := newFamilyDialer(t.config.HTTP.IPFamily); err == nil {
+		p.dial = dialer.DialContext
+	}
+	if proxy, err := t.proxyFunc(); err == nil {
+		p.proxy = proxy
+	}
+	checks := p.run(githubHost, "443")
+	for _, c := range checks {
+		t.logger.Debugf("Network check: %s", c)
+	}
+	t.logger.Errorf("Cannot reach GitHub: %s (%s)", networkDiagnosis(checks), err)
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func testPreflight(server *httptest.Server) *networkPreflight {
+	p := newNetworkPreflight()
+	p.lookup = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"127.0.0.1"}, nil
+	}
+	p.dial = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		return net.Dial(network, server.Listener.Addr().String())
+	}
+	p.proxy = func(req *http.Request) (*url.URL, error) {
+		return nil, nil
+	}
+	p.tls = server.Client().Transport.(*http.Transport).TLSClientConfig
+	return p
+}
+
+func TestNetworkPreflight(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	checks := testPreflight(server).run("example.com", "443")
+
+	assert.Equal(t, len(checks), 3)
+	assert.Equal(t, checks[2].String(), "tls example.com:443: ok")
+	assert.Equal(t, networkDiagnosis(checks), "the network looks fine, GitHub may be down, see https://www.githubstatus.com")
+}
+
+func TestNetworkPreflightDNS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	p := testPreflight(server)
+	p.lookup = func(ctx context.Context, host string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+
+	checks := p.run("api.github.com", "443")
+
+	assert.Equal(t, len(checks), 1)
+	assert.Equal(t, networkDiagnosis(checks), "DNS resolution of api.github.com failed, check the DNS settings of this machine")
+}
+
+func TestNetworkPreflightTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	p := testPreflight(server)
+	p.tls = nil
+
+	checks := p.run("example.com", "443")
+
+	assert.Equal(t, len(checks), 3)
+	assert.Equal(t, networkDiagnosis(checks), "the TLS handshake with example.com:443 failed, a proxy or firewall may intercept HTTPS")
+}
+
+func TestNetworkPreflightProxy(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	p := testPreflight(server)
+	p.proxy = func(req *http.Request) (*url.URL, error) {
+		return url.Parse("http://proxy.internal:3128")
+	}
+	p.dial = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	checks := p.run("api.github.com", "443")
+
+	assert.Equal(t, checks[len(checks)-1].Target, "proxy.internal:3128")
+	assert.Equal(t, networkDiagnosis(checks), "the proxy proxy.internal:3128 is not reachable, check HTTPS_PROXY or proxy.socks5")
+}
+
+func TestIsNetworkError(t *testing.T) {
+	assert.Equal(t, isNetworkError(&url.Error{Op: "Get", URL: "https://api.github.com", Err: errors.New("dial tcp: no such host")}), true)
+	assert.Equal(t, isNetworkError(&url.Error{Op: "Get", URL: "https://api.github.com", Err: context.DeadlineExceeded}), false)
+	assert.Equal(t, isNetworkError(&ResponseError{StatusCode: 502, Message: "Bad Gateway"}), false)
+}
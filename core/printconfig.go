@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"github.com/pelletier/go-toml"
+	"io"
+	"reflect"
+)
+
+// secretMask replaces secret config values in printed configs.
+const secretMask = "********"
+
+// maskSecrets replaces the values of fields tagged secret:"true" in the
+// struct v. Slices are replaced rather than changed in place.
+func maskSecrets(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			maskSecrets(value)
+		case field.Tag.Get("secret") != "true":
+		case field.Type.Kind() == reflect.String && value.Len() > 0:
+			value.SetString(secretMask)
+		case field.Type.Kind() == reflect.Slice && value.Len() > 0:
+			masked := reflect.MakeSlice(field.Type, value.Len(), value.Len())
+			for j := 0; j < value.Len(); j++ {
+				masked.Index(j).SetString(secretMask)
+			}
+			value.Set(masked)
+		}
+	}
+}
+
+// effectiveConfig returns the config with the access token given with a flag
+// or environment variable applied and secrets masked.
+func (t *Launcher) effectiveConfig() Config {
+	c := *t.config
+	if c.ConfigVersion == 0 {
+		c.ConfigVersion = CurrentConfigVersion
+	}
+	if s := t.resolveAccessToken(); s.Source == SourceFlag || s.Source == SourceEnv {
+		c.GitHub.AccessToken = t.accessTokens()[0]
+		c.GitHub.AccessTokens = nil
+	}
+	maskSecrets(reflect.ValueOf(&c).Elem())
+	return c
+}
+
+// printConfig writes the effective config in the format of the config file.
+// Keys are sorted, which keeps top-level keys in front of the tables.
+func (t *Launcher) printConfig(w io.Writer) error {
+	fmt.Fprintln(w, "# Effective configuration of opendex-launcher, secrets are masked")
+	for _, s := range []Setting{t.resolveNetwork(), t.resolveBranch()} {
+		fmt.Fprintf(w, "# %s: %s, from %s\n", s.Name, s.Value, s)
+	}
+	return toml.NewEncoder(w).Encode(t.effectiveConfig())
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"os"
+	"testing"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	os.Unsetenv("GITHUB_ACCESS_TOKEN")
+	config := DefaultConfig()
+	config.GitHub.AccessToken = "ghp_first"
+	config.GitHub.AccessTokens = []string{"ghp_second"}
+	config.Webhook.Url = "https://hooks.slack.com/services/T0/B0/secret"
+	l := &Launcher{config: config, args: &bootstrapArgs{}}
+
+	c := l.effectiveConfig()
+	assert.Equal(t, c.GitHub.AccessToken, secretMask)
+	assert.Equal(t, c.GitHub.AccessTokens, []string{secretMask})
+	assert.Equal(t, c.Webhook.Url, secretMask)
+	assert.Equal(t, c.ConfigVersion, CurrentConfigVersion)
+	assert.Equal(t, config.GitHub.AccessTokens, []string{"ghp_second"})
+
+	l.args.accessToken = "ghp_flag"
+	c = l.effectiveConfig()
+	assert.Equal(t, c.GitHub.AccessToken, secretMask)
+	assert.Equal(t, len(c.GitHub.AccessTokens), 0)
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Profile bundles the settings of a launch, e.g. a testnet experiment next to
+// the mainnet node, in a [profile.<name>] section selected with --profile.
+type Profile struct {
+	Network string            `toml:"network" comment:"Network to run"`
+	Branch  string            `toml:"branch" comment:"Branch, release tag or release channel to run"`
+	Env     map[string]string `toml:"env" comment:"Environment variables set for the launcher"`
+	Args    []string          `toml:"args" comment:"Arguments passed to the launcher when none are given on the command line"`
+}
+
+// profile returns the profile selected with --profile.
+func (t *Launcher) profile() (Profile, bool) {
+	if t.args == nil || t.args.profile == "" || t.config == nil {
+		return Profile{}, false
+	}
+	p, ok := t.config.Profiles[t.args.profile]
+	return p, ok
+}
+
+// profileSetting replaces s by value of the profile unless s was given as a
+// flag, which takes precedence over the profile.
+func (t *Launcher) profileSetting(s Setting, key string, value string) Setting {
+	if s.Source == SourceFlag || value == "" {
+		return s
+	}
+	return Setting{Name: s.Name, Value: value, Source: SourceConfig, Origin: fmt.Sprintf("profile.%s.%s", t.args.profile, key)}
+}
+
+// profileEnv returns the environment variables of p sorted by name.
+func profileEnv(p Profile) []string {
+	var env []string
+	for key, value := range p.Env {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// applyProfile switches to the network of the profile selected with
+// --profile and adds its environment and arguments for the launcher. The
+// arguments of the profile are used when none are given on the command
+// line.
+func (t *Launcher) applyProfile() error {
+	if t.args.profile == "" {
+		return nil
+	}
+	p, ok := t.profile()
+	if !ok {
+		var names []string
+		for name := range t.config.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("%w: %s (configured: %s)", ErrUnknownProfile, t.args.profile, strings.Join(names, ", "))
+	}
+
+	if network := t.resolveNetwork().Value; network != t.network {
+		t.network = network
+		if err := t.ensureNetworkDir(); err != nil {
+			return err
+		}
+	}
+	t.childEnv = append(t.childEnv, profileEnv(p)...)
+	if len(t.args.rest) == 0 && !t.args.verbatim {
+		t.args.rest = p.Args
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProfileConfig(t *testing.T) {
+	config, err := parseConfig(strings.NewReader(`
+[profile.experiment]
+network = "testnet"
+branch = "feat/foo"
+args = ["status"]
+
+[profile.experiment.env]
+B = "2"
+A = "1"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := config.Profiles["experiment"]
+	assert.Equal(t, p.Network, "testnet")
+	assert.Equal(t, p.Args, []string{"status"})
+	assert.Equal(t, profileEnv(p), []string{"A=1", "B=2"})
+}
+
+func TestProfileSettings(t *testing.T) {
+	os.Unsetenv("NETWORK")
+	config := DefaultConfig()
+	config.Profiles = map[string]Profile{"experiment": {Network: "testnet"}}
+	l := &Launcher{args: &bootstrapArgs{profile: "experiment"}, config: config}
+
+	s := l.resolveNetwork()
+	assert.Equal(t, s.Value, "testnet")
+	assert.Equal(t, s.Origin, "profile.experiment.network")
+	assert.Equal(t, l.resolveBranch().Source, SourceDefault)
+
+	l.args.network = "simnet"
+	assert.Equal(t, l.resolveNetwork().Value, "simnet", "flags take precedence over the profile")
+
+	l.args.profile = "missing"
+	assert.Equal(t, errors.Is(l.applyProfile(), ErrUnknownProfile), true)
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	PhaseResolve  = "resolve"
+	PhaseDownload = "download"
+	PhaseExtract  = "extract"
+	PhaseBuild    = "build"
+	PhaseLaunch   = "launch"
+)
+
+// ProgressFunc reports that current of total bytes of phase are done. total
+// is zero when the size is unknown.
+type ProgressFunc func(phase string, current int64, total int64)
+
+// progressReader reports the bytes read through it, at most every
+// progressInterval and once when the end is reached.
+type progressReader struct {
+	r        io.Reader
+	phase    string
+	current  int64
+	total    int64
+	last     time.Time
+	progress ProgressFunc
+}
+
+const progressInterval = 250 * time.Millisecond
+
+func (t *progressReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	t.current += int64(n)
+	if t.progress != nil && (err == io.EOF || time.Since(t.last) >= progressInterval) {
+		t.last = time.Now()
+		t.progress(t.phase, t.current, t.total)
+	}
+	return n, err
+}
+
+func percent(current int64, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(current) * 100 / float64(total)
+}
+
+func (t *Launcher) reportProgress(phase string, current int64, total int64) {
+	t.emit(Event{
+		Type:    EventProgress,
+		Phase:   phase,
+		Percent: percent(current, total),
+		Bytes:   current,
+		Total:   total,
+	})
+}
+
+func (t *Launcher) reportPhase(phase string, percent float64, format string, args ...interface{}) {
+	t.emit(Event{
+		Type:    EventProgress,
+		Phase:   phase,
+		Percent: percent,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// jsonEventWriter writes every event as a line of JSON.
+func jsonEventWriter(w io.Writer) EventHandler {
+	encoder := json.NewEncoder(w)
+	return func(e Event) {
+		_ = encoder.Encode(e)
+	}
+}
+
+func (t *Launcher) setupProgress() error {
+	switch t.args.progress {
+	case "":
+	case "json":
+		t.subscribe(jsonEventWriter(os.Stdout))
+	default:
+		return fmt.Errorf("unsupported progress format: %s", t.args.progress)
+	}
+	return nil
+}
+
+// progressAggregate sums the download progress of concurrent downloads into
+// a single report.
+type progressAggregate struct {
+	mu       sync.Mutex
+	progress ProgressFunc
+	current  map[string]int64
+	total    map[string]int64
+}
+
+func newProgressAggregate(progress ProgressFunc) *progressAggregate {
+	return &progressAggregate{
+		progress: progress,
+		current:  make(map[string]int64),
+		total:    make(map[string]int64),
+	}
+}
+
+// reporter returns the ProgressFunc of the download name. Other phases are
+// passed through as they are.
+func (t *progressAggregate) reporter(name string) ProgressFunc {
+	return func(phase string, current int64, total int64) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.progress == nil {
+			return
+		}
+		if phase != PhaseDownload {
+			t.progress(phase, current, total)
+			return
+		}
+		t.current[name] = current
+		t.total[name] = total
+		var sumCurrent, sumTotal int64
+		for key, value := range t.current {
+			sumCurrent += value
+			if t.total[key] > 0 {
+				sumTotal += t.total[key]
+			}
+		}
+		t.progress(PhaseDownload, sumCurrent, sumTotal)
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestProgressAggregate(t *testing.T) {
+	var current, total int64
+	a := newProgressAggregate(func(phase string, c int64, t int64) {
+		current, total = c, t
+	})
+
+	a.reporter("launcher")(PhaseDownload, 10, 100)
+	a.reporter("templates")(PhaseDownload, 5, 50)
+	assert.Equal(t, current, int64(15))
+	assert.Equal(t, total, int64(150))
+
+	a.reporter("launcher")(PhaseDownload, 100, 100)
+	assert.Equal(t, current, int64(105))
+	assert.Equal(t, total, int64(150))
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+type Proxy struct {
+	SOCKS5   string `toml:"socks5" comment:"SOCKS5 proxy as host:port to send all GitHub traffic through, e.g. 127.0.0.1:9050 for Tor"`
+	Username string `toml:"username" comment:"Username for the SOCKS5 proxy"`
+	Password string `toml:"password" secret:"true" comment:"Password for the SOCKS5 proxy"`
+}
+
+// url returns the SOCKS5 proxy, or nil when none is configured. Host names
+// are resolved by the proxy, so DNS queries do not leak around Tor.
+func (p Proxy) url() (*url.URL, error) {
+	if p.SOCKS5 == "" {
+		return nil, nil
+	}
+	if _, _, err := net.SplitHostPort(p.SOCKS5); err != nil {
+		return nil, fmt.Errorf("invalid socks5 proxy %s: %w", p.SOCKS5, err)
+	}
+	u := &url.URL{Scheme: "socks5", Host: p.SOCKS5}
+	if p.Username != "" || p.Password != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u, nil
+}
+
+// proxyFunc returns the proxy selection of the GitHub client: the SOCKS5
+// proxy when configured, otherwise HTTPS_PROXY and friends.
+func (t *Launcher) proxyFunc() (func(req *http.Request) (*url.URL, error), error) {
+	u, err := t.config.Proxy.url()
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return http.ProxyFromEnvironment, nil
+	}
+	return http.ProxyURL(u), nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/magiconair/properties/assert"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serveSOCKS5 accepts a single CONNECT with username and password auth and
+// connects it to backend, reporting the requested destination.
+func serveSOCKS5(l net.Listener, backend string, dest chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	buf := make([]byte, 262)
+	// greeting: version, methods
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 2})
+	// username and password
+	io.ReadFull(conn, buf[:2])
+	user := make([]byte, buf[1])
+	io.ReadFull(conn, user)
+	io.ReadFull(conn, buf[:1])
+	pass := make([]byte, buf[0])
+	io.ReadFull(conn, pass)
+	if string(user) != "tor" || string(pass) != "secret" {
+		conn.Write([]byte{1, 1})
+		return
+	}
+	conn.Write([]byte{1, 0})
+	// request: version, command, reserved, address type 3 (domain)
+	io.ReadFull(conn, buf[:5])
+	host := make([]byte, buf[4])
+	io.ReadFull(conn, host)
+	io.ReadFull(conn, buf[:2])
+	dest <- fmt.Sprintf("%s:%d", host, binary.BigEndian.Uint16(buf[:2]))
+
+	upstream, err := net.Dial("tcp", backend)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
+
+func TestSOCKS5Proxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "through the proxy")
+	}))
+	defer backend.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	dest := make(chan string, 1)
+	go serveSOCKS5(l, backend.Listener.Addr().String(), dest)
+
+	launcher := &Launcher{config: DefaultConfig()}
+	launcher.config.Proxy = Proxy{SOCKS5: l.Addr().String(), Username: "tor", Password: "secret"}
+	client, err := launcher.httpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get("http://api.github.onion/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, string(body), "through the proxy")
+	assert.Equal(t, <-dest, "api.github.onion:80")
+}
+
+func TestProxyURL(t *testing.T) {
+	u, err := Proxy{}.url()
+	assert.Equal(t, u == nil && err == nil, true)
+
+	u, err = Proxy{SOCKS5: "127.0.0.1:9050"}.url()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, u.String(), "socks5://127.0.0.1:9050")
+
+	_, err = Proxy{SOCKS5: "127.0.0.1"}.url()
+	assert.Equal(t, err.Error(), "invalid socks5 proxy 127.0.0.1: address 127.0.0.1: missing port in address")
+}
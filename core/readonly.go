@@ -0,0 +1,59 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var ErrReadOnlyVersions = errors.New("the versions directory is read-only")
+
+// writableDir reports whether files can be created in dir. Unlike the mode
+// of dir this covers read-only mounts.
+func writableDir(dir string) bool {
+	f, err := ioutil.TempFile(dir, ".write-test-")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	_ = os.Remove(f.Name())
+	return true
+}
+
+// useVersionsDir switches to the versions dir of cache.versions-dir. When
+// it is read-only, e.g. baked into an image, only the versions it holds are
+// run and nothing in it is changed, while the config, logs and network data
+// stay in the home directory.
+func (t *Launcher) useVersionsDir() error {
+	dir := t.config.Cache.VersionsDir
+	if dir == "" {
+		return nil
+	}
+	if t.config.Cache.SharedDir != "" {
+		return errors.New("cache.versions-dir and cache.shared-dir cannot be used together")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("versions dir: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a folder: %s", dir)
+	}
+	t.launcherVersionsDir = dir
+	t.readOnlyVersions = !writableDir(dir)
+	if t.readOnlyVersions {
+		t.logger.Debugf("Versions dir %s is read-only", dir)
+	}
+	return nil
+}
+
+// checkWritableVersion fails when the version of commit cannot be installed
+// or changed because the versions dir is read-only.
+func (t *Launcher) checkWritableVersion(commit string) error {
+	if !t.readOnlyVersions {
+		return nil
+	}
+	return fmt.Errorf("%w: %s cannot be installed or changed in %s", ErrReadOnlyVersions, shortCommit(commit), filepath.Dir(filepath.Dir(t.launcherPath(commit))))
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadOnlyVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	assert.Equal(t, writableDir(dir), true)
+	assert.Equal(t, writableDir(filepath.Join(dir, "missing")), false)
+
+	l := &Launcher{config: DefaultConfig(), logger: logrus.NewEntry(logrus.New())}
+	l.config.Cache.VersionsDir = dir
+	if err := l.useVersionsDir(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, l.launcherVersionsDir, dir)
+	assert.Equal(t, l.readOnlyVersions, false)
+
+	l.config.Cache.SharedDir = dir
+	assert.Equal(t, l.useVersionsDir() != nil, true)
+
+	installed := filepath.Join(dir, "0123456")
+	if err := os.MkdirAll(installed, 0755); err != nil {
+		t.Fatal(err)
+	}
+	l.readOnlyVersions = true
+	_, err = l.ensureLauncher("fedcba9")
+	assert.Equal(t, errors.Is(err, ErrReadOnlyVersions), true)
+	err = l.removeVersion("0123456", "test")
+	assert.Equal(t, errors.Is(err, ErrReadOnlyVersions), true)
+	l.markUsed("0123456")
+	_, err = os.Stat(filepath.Join(installed, MetadataFilename))
+	assert.Equal(t, os.IsNotExist(err), true)
+}
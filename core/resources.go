@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+)
+
+// Resources bounds what the launcher process tree may take from the host so
+// that the management stack cannot starve the node containers.
+type Resources struct {
+	Nice    int    `toml:"nice" default:"0" comment:"Niceness of the launcher process on Unix, -20 (highest priority) to 19 (lowest)"`
+	IOClass string `toml:"io-class" comment:"I/O scheduling class of the launcher process on Linux: realtime, best-effort or idle"`
+	IOLevel int    `toml:"io-level" default:"4" comment:"I/O priority within the best-effort and realtime classes, 0 (highest) to 7 (lowest)"`
+
+	PriorityClass string `toml:"priority-class" comment:"Priority class of the launcher process on Windows: idle, below-normal, normal, above-normal or high"`
+
+	MemoryMax int    `toml:"memory-max" default:"0" comment:"Memory limit in MB of the launcher cgroup on Linux (cgroup v2), 0 for no limit"`
+	CPUMax    int    `toml:"cpu-max" default:"0" comment:"CPU limit of the launcher cgroup on Linux in percent of one core, 0 for no limit"`
+	Cgroup    string `toml:"cgroup" default:"opendex-launcher" comment:"cgroup below /sys/fs/cgroup the launcher is moved to when a cgroup limit is set"`
+}
+
+const cgroupPeriod = 100000
+
+// ioPriority returns the ioprio_set(2) value of class and level.
+func ioPriority(class string, level int) (int, error) {
+	if level < 0 || level > 7 {
+		return 0, fmt.Errorf("io-level must be between 0 and 7: %d", level)
+	}
+	switch class {
+	case "realtime":
+		return 1<<13 | level, nil
+	case "best-effort":
+		return 2<<13 | level, nil
+	case "idle":
+		return 3 << 13, nil
+	default:
+		return 0, fmt.Errorf("unknown io-class: %s", class)
+	}
+}
+
+// cpuMaxValue returns the cpu.max content limiting a cgroup to percent of
+// one core.
+func cpuMaxValue(percent int) string {
+	return fmt.Sprintf("%d %d", percent*cgroupPeriod/100, cgroupPeriod)
+}
+
+// priorityClass returns the Windows priority class constant of name.
+func priorityClass(name string) (uint32, error) {
+	switch name {
+	case "idle":
+		return 0x40, nil
+	case "below-normal":
+		return 0x4000, nil
+	case "normal":
+		return 0x20, nil
+	case "above-normal":
+		return 0x8000, nil
+	case "high":
+		return 0x80, nil
+	default:
+		return 0, fmt.Errorf("unknown priority-class: %s", name)
+	}
+}
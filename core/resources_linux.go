@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+const ioprioWhoProcess = 1
+
+// applyResources applies the configured niceness, I/O priority and cgroup
+// limits to the started launcher process pid.
+func (t *Launcher) applyResources(pid int) error {
+	r := t.config.Resources
+	if r.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, r.Nice); err != nil {
+			return fmt.Errorf("set niceness: %w", err)
+		}
+	}
+	if r.IOClass != "" {
+		prio, err := ioPriority(r.IOClass, r.IOLevel)
+		if err != nil {
+			return err
+		}
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(pid), uintptr(prio)); errno != 0 {
+			return fmt.Errorf("set io priority: %w", errno)
+		}
+	}
+	if r.MemoryMax > 0 || r.CPUMax > 0 {
+		if err := t.joinCgroup(pid); err != nil {
+			return fmt.Errorf("cgroup: %w", err)
+		}
+	}
+	return nil
+}
+
+// joinCgroup moves pid into a cgroup v2 group per network with the
+// configured memory and CPU bounds. The cgroup has to be writable, e.g.
+// delegated by systemd or created by root.
+func (t *Launcher) joinCgroup(pid int) error {
+	r := t.config.Resources
+	parent := filepath.Join("/sys/fs/cgroup", r.Cgroup)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+cpu +memory"), 0644); err != nil {
+		return fmt.Errorf("enable controllers: %w", err)
+	}
+	dir := filepath.Join(parent, t.network)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if r.MemoryMax > 0 {
+		value := strconv.FormatInt(int64(r.MemoryMax)*1024*1024, 10)
+		if err := ioutil.WriteFile(filepath.Join(dir, "memory.max"), []byte(value), 0644); err != nil {
+			return fmt.Errorf("memory.max: %w", err)
+		}
+	}
+	if r.CPUMax > 0 {
+		if err := ioutil.WriteFile(filepath.Join(dir, "cpu.max"), []byte(cpuMaxValue(r.CPUMax)), 0644); err != nil {
+			return fmt.Errorf("cpu.max: %w", err)
+		}
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+}
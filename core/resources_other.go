@@ -0,0 +1,24 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package core
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// applyResources sets the configured niceness of the started launcher
+// process pid. I/O priorities and cgroups are Linux only.
+func (t *Launcher) applyResources(pid int) error {
+	r := t.config.Resources
+	if r.IOClass != "" || r.MemoryMax > 0 || r.CPUMax > 0 {
+		t.logger.Warnf("io-class, memory-max and cpu-max are only supported on Linux")
+	}
+	if r.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, r.Nice); err != nil {
+			return fmt.Errorf("set niceness: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestIOPriority(t *testing.T) {
+	prio, err := ioPriority("best-effort", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, prio, 2<<13|7)
+
+	prio, err = ioPriority("idle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, prio, 3<<13)
+
+	_, err = ioPriority("best-effort", 8)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestCPUMaxValue(t *testing.T) {
+	assert.Equal(t, cpuMaxValue(50), "50000 100000")
+	assert.Equal(t, cpuMaxValue(200), "200000 100000")
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"fmt"
+	"syscall"
+)
+
+const processSetInformation = 0x0200
+
+var procSetPriorityClass = modkernel32.NewProc("SetPriorityClass")
+
+// applyResources sets the configured priority class of the started launcher
+// process pid.
+func (t *Launcher) applyResources(pid int) error {
+	r := t.config.Resources
+	if r.Nice != 0 || r.IOClass != "" || r.MemoryMax > 0 || r.CPUMax > 0 {
+		t.logger.Warnf("nice, io-class, memory-max and cpu-max are not supported on Windows, use priority-class")
+	}
+	if r.PriorityClass == "" {
+		return nil
+	}
+	class, err := priorityClass(r.PriorityClass)
+	if err != nil {
+		return err
+	}
+	h, err := syscall.OpenProcess(processSetInformation, false, uint32(pid))
+	if err != nil {
+		return fmt.Errorf("open process: %w", err)
+	}
+	defer syscall.CloseHandle(h)
+	if ok, _, err := procSetPriorityClass.Call(uintptr(h), uintptr(class)); ok == 0 {
+		return fmt.Errorf("set priority class: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Steps of an install recorded in the metadata of the version, so that an
+// interrupted install resumes after the last one completed.
+const (
+	StepDownloaded = "downloaded"
+	StepVerified   = "verified"
+	StepExtracted  = "extracted"
+)
+
+// maxResumeAge is how long an interrupted start is resumed with the commit
+// it resolved. Older ones resolve the branch again.
+const maxResumeAge = 24 * time.Hour
+
+// resumable tells whether the install described by m downloaded the archive
+// at url completely before it was interrupted.
+func (t *GithubClient) resumable(m *VersionMetadata, url string) bool {
+	return t.resumableIn(".", m, url)
+}
+
+// resumableIn is resumable for the install in dir.
+func (t *GithubClient) resumableIn(dir string, m *VersionMetadata, url string) bool {
+	if m.Url != url || (m.Step != StepDownloaded && m.Step != StepVerified) {
+		return false
+	}
+	exists, err := utils.FileExists(filepath.Join(dir, "launcher.zip"))
+	return err == nil && exists
+}
+
+// extracted tells whether the install in dir was not interrupted before
+// the launcher was extracted. Versions installed in one go record no step.
+func extracted(dir string) bool {
+	m, err := readMetadata(dir)
+	return err == nil && (m.Step == "" || m.Step == StepExtracted)
+}
+
+// startState is the commit a start of the network resolved. It is removed
+// once the launcher is installed, so a state left behind belongs to a start
+// which was interrupted.
+type startState struct {
+	Branch     string    `json:"branch"`
+	Commit     string    `json:"commit"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+func (t *Launcher) startStateFile() string {
+	return filepath.Join(t.launcherDir, fmt.Sprintf("start-%s.json", t.network))
+}
+
+// interruptedStart returns the commit resolved by an interrupted start of
+// the branch.
+func (t *Launcher) interruptedStart(now time.Time) (string, bool) {
+	if t.network == "" || t.channel != "" {
+		return "", false
+	}
+	data, err := ioutil.ReadFile(t.startStateFile())
+	if err != nil {
+		return "", false
+	}
+	var s startState
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.logger.Debugf("Unmarshal %s: %s", filepath.Base(t.startStateFile()), err)
+		return "", false
+	}
+	if s.Branch != t.branch || s.Commit == "" || now.Sub(s.ResolvedAt) > maxResumeAge {
+		return "", false
+	}
+	return s.Commit, true
+}
+
+// resolveResuming returns the commit of an interrupted start of the branch
+// or resolves the branch and records the commit until the start completes.
+func (t *Launcher) resolveResuming() (string, error) {
+	if commit, ok := t.interruptedStart(time.Now()); ok {
+		t.logger.Infof("Resuming the interrupted start of %s", describeRef(t.branch, commit))
+		return commit, nil
+	}
+	commit, err := t.resolve()
+	if err != nil || t.network == "" {
+		return commit, err
+	}
+	data, err := json.Marshal(startState{Branch: t.branch, Commit: commit, ResolvedAt: time.Now()})
+	if err == nil {
+		err = ioutil.WriteFile(t.startStateFile(), data, 0644)
+	}
+	if err != nil {
+		t.logger.Debugf("Failed to record the start: %s", err)
+	}
+	return commit, nil
+}
+
+// finishStart forgets the commit of the start unless installing it was
+// interrupted. Failed starts resolve the branch again next time.
+func (t *Launcher) finishStart() {
+	if t.network == "" || t.github.context().Err() != nil {
+		return
+	}
+	if err := os.Remove(t.startStateFile()); err != nil && !os.IsNotExist(err) {
+		t.logger.Debugf("Failed to remove %s: %s", t.startStateFile(), err)
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInterruptedStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	l := &Launcher{launcherDir: dir, network: "testnet", branch: "master", github: NewGithubClient(), logger: logrus.NewEntry(logrus.New())}
+	state := `{"branch": "master", "commit": "abc", "resolved_at": "2021-03-01T10:00:00Z"}`
+	if err := ioutil.WriteFile(l.startStateFile(), []byte(state), 0644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	commit, ok := l.interruptedStart(now)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, commit, "abc")
+
+	_, ok = l.interruptedStart(now.Add(maxResumeAge))
+	assert.Equal(t, ok, false)
+
+	l.branch = "feat/foo"
+	_, ok = l.interruptedStart(now)
+	assert.Equal(t, ok, false)
+
+	l.branch = "master"
+	l.finishStart()
+	_, ok = l.interruptedStart(now)
+	assert.Equal(t, ok, false)
+}
+
+func TestDownloadLauncherResumes(t *testing.T) {
+	const url = "https://example.com/launcher.zip"
+	for _, step := range []string{StepDownloaded, StepVerified} {
+		dir, err := ioutil.TempDir("", "resume")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		if err := ioutil.WriteFile(filepath.Join(dir, "launcher.zip"), launcherZip(t), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeMetadata(dir, &VersionMetadata{Commit: "abc", Url: url, Step: step}); err != nil {
+			t.Fatal(err)
+		}
+
+		verified := 0
+		c := NewGithubClient()
+		c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("unexpected request")
+		})}
+		c.Verify = func(ref string, file string) (string, error) {
+			verified++
+			return "", nil
+		}
+		if err := c.downloadLauncher(url, "master", "abc", dir); err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := readMetadata(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, m.Step, StepExtracted)
+		assert.Equal(t, verified == 0, step == StepVerified)
+		_, err = os.Stat(filepath.Join(dir, "launcher"))
+		assert.Equal(t, err, nil)
+	}
+}
+
+func TestInterruptedExtraction(t *testing.T) {
+	const url = "https://example.com/launcher.zip"
+	dir, err := ioutil.TempDir("", "resume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "launcher.zip"), launcherZip(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the extraction was interrupted halfway through the binary
+	if err := ioutil.WriteFile(filepath.Join(dir, "launcher"), []byte("lau"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMetadata(dir, &VersionMetadata{Commit: "abc", Url: url, Step: StepVerified}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, extracted(dir), false)
+
+	c := NewGithubClient()
+	c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("unexpected request")
+	})}
+	if err := c.downloadLauncher(url, "master", "abc", dir); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, extracted(dir), true)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "launcher"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), "launcher")
+	_, err = os.Stat(filepath.Join(dir, extractStaging))
+	assert.Equal(t, os.IsNotExist(err), true)
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// retryBudget bounds the total time a client sleeps for Retry-After
+// responses over its lifetime.
+type retryBudget struct {
+	mu        sync.Mutex
+	remaining time.Duration
+}
+
+func newRetryBudget(total time.Duration) *retryBudget {
+	return &retryBudget{remaining: total}
+}
+
+// take reserves d of the budget and reports whether it was available.
+func (b *retryBudget) take(d time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if d > b.remaining {
+		return false
+	}
+	b.remaining -= d
+	return true
+}
+
+// retryAfter returns how long to wait before retrying a 403 or 429 response
+// that carries a Retry-After header, as sent for secondary rate limits.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			seconds = 0
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := date.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// do sends req and retries it after the delay requested by Retry-After as
+// long as the retry budget allows. Only requests without a body are retried.
+func (t *GithubClient) do(req *http.Request) (*http.Response, error) {
+	for {
+		resp, err := t.Client.Do(req)
+		if err != nil {
+			return nil, clockHint(err)
+		}
+		t.checkClock(req, resp, time.Now())
+		delay, ok := retryAfter(resp, time.Now())
+		if !ok || req.Body != nil || t.Retries == nil || !t.Retries.take(delay) {
+			return resp, nil
+		}
+		t.Logger.Warnf("GitHub asked to retry %s in %s", req.URL.Path, delay)
+		_ = resp.Body.Close()
+		select {
+		case <-time.After(delay):
+		case <-t.context().Done():
+			return nil, t.context().Err()
+		}
+	}
+}
+
+func (t *GithubClient) context() context.Context {
+	if t.Context == nil {
+		return context.Background()
+	}
+	return t.Context
+}
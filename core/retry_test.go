@@ -0,0 +1,38 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+
+	_, ok := retryAfter(resp, now)
+	assert.Equal(t, ok, false)
+
+	resp.Header.Set("Retry-After", "30")
+	d, ok := retryAfter(resp, now)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, d, 30*time.Second)
+
+	resp.StatusCode = http.StatusTooManyRequests
+	resp.Header.Set("Retry-After", "Mon, 01 Mar 2021 12:01:00 GMT")
+	d, ok = retryAfter(resp, now)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, d, time.Minute)
+
+	resp.StatusCode = http.StatusNotFound
+	_, ok = retryAfter(resp, now)
+	assert.Equal(t, ok, false)
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := newRetryBudget(time.Minute)
+	assert.Equal(t, b.take(40*time.Second), true)
+	assert.Equal(t, b.take(30*time.Second), false)
+	assert.Equal(t, b.take(20*time.Second), true)
+}
@@ -0,0 +1,28 @@
+package core
+
+// sandboxCommand is the hidden first argument which makes opendex-launcher
+// act as the sandbox helper: it restricts itself and then executes the
+// launcher in its place.
+const sandboxCommand = "__sandbox"
+
+// Sandbox restricts what a compromised launcher binary could do. Linux only.
+type Sandbox struct {
+	Enabled         bool `toml:"enabled" default:"false" comment:"Run the launcher in a restricted environment (Linux only)"`
+	Namespaces      bool `toml:"namespaces" default:"false" comment:"Give the launcher its own mount and IPC namespaces, requires root"`
+	NoNewPrivileges bool `toml:"no-new-privileges" default:"true" comment:"Prevent the launcher from gaining privileges through setuid binaries"`
+	Seccomp         bool `toml:"seccomp" default:"true" comment:"Deny system calls a launcher never needs, such as loading kernel modules or tracing processes"`
+}
+
+// sandboxArgs returns the arguments of the sandbox helper running launcher
+// with args.
+func (t Sandbox) sandboxArgs(launcher string, args []string) []string {
+	result := []string{sandboxCommand}
+	if t.NoNewPrivileges {
+		result = append(result, "--no-new-privileges")
+	}
+	if t.Seccomp {
+		result = append(result, "--seccomp")
+	}
+	result = append(result, "--", launcher)
+	return append(result, args...)
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	prSetNoNewPrivs   = 38
+	prSetSeccomp      = 22
+	seccompModeFilter = 2
+
+	seccompRetKill  = 0x00000000
+	seccompRetErrno = 0x00050000
+	seccompRetAllow = 0x7fff0000
+
+	bpfLdWAbs = 0x20
+	bpfJeqK   = 0x15
+	bpfJgeK   = 0x35
+	bpfRetK   = 0x06
+
+	auditArchX86_64  = 0xc000003e
+	auditArchAarch64 = 0xc00000b7
+	x32SyscallBit    = 0x40000000
+)
+
+// deniedSyscalls are refused with EPERM, following the system calls the
+// default Docker seccomp profile blocks for unprivileged containers.
+var deniedSyscalls = []uint32{
+	unix.SYS_ACCT,
+	unix.SYS_ADD_KEY,
+	unix.SYS_DELETE_MODULE,
+	unix.SYS_FINIT_MODULE,
+	unix.SYS_INIT_MODULE,
+	unix.SYS_KEXEC_LOAD,
+	unix.SYS_KEYCTL,
+	unix.SYS_MOUNT,
+	unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_PERF_EVENT_OPEN,
+	unix.SYS_PIVOT_ROOT,
+	unix.SYS_PROCESS_VM_READV,
+	unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_PTRACE,
+	unix.SYS_REBOOT,
+	unix.SYS_REQUEST_KEY,
+	unix.SYS_SETNS,
+	unix.SYS_SETTIMEOFDAY,
+	unix.SYS_SWAPOFF,
+	unix.SYS_SWAPON,
+	unix.SYS_UMOUNT2,
+	unix.SYS_UNSHARE,
+}
+
+// seccompFilter builds a BPF program which kills processes using a foreign
+// system call ABI and denies deniedSyscalls.
+func seccompFilter(arch string) ([]syscall.SockFilter, error) {
+	var audit uint32
+	switch arch {
+	case "amd64":
+		audit = auditArchX86_64
+	case "arm64":
+		audit = auditArchAarch64
+	default:
+		return nil, fmt.Errorf("seccomp is not supported on %s", arch)
+	}
+
+	filter := []syscall.SockFilter{
+		{Code: bpfLdWAbs, K: 4},
+		{Code: bpfJeqK, Jt: 1, K: audit},
+		{Code: bpfRetK, K: seccompRetKill},
+		{Code: bpfLdWAbs, K: 0},
+	}
+	// the x32 ABI shares the architecture of amd64
+	deny := len(deniedSyscalls) + 1
+	if arch == "amd64" {
+		filter = append(filter, syscall.SockFilter{Code: bpfJgeK, Jt: uint8(deny), K: x32SyscallBit})
+	}
+	for i, nr := range deniedSyscalls {
+		filter = append(filter, syscall.SockFilter{Code: bpfJeqK, Jt: uint8(deny - i - 1), K: nr})
+	}
+	return append(filter,
+		syscall.SockFilter{Code: bpfRetK, K: seccompRetAllow},
+		syscall.SockFilter{Code: bpfRetK, K: seccompRetErrno | uint32(syscall.EPERM)},
+	), nil
+}
+
+func prctl(option uintptr, arg uintptr) error {
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, option, arg, 0, 0, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// sandbox makes cmd run through the sandbox helper.
+func (t *Launcher) sandbox(cmd *exec.Cmd) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cfg := t.config.Sandbox
+	cmd.Path = executable
+	cmd.Args = append([]string{executable}, cfg.sandboxArgs(cmd.Args[0], cmd.Args[1:])...)
+	if cfg.Namespaces {
+		if os.Geteuid() != 0 {
+			// cloning them fails with a bare EPERM otherwise
+			return errors.New("namespaces require root, disable sandbox.namespaces")
+		}
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNS | syscall.CLONE_NEWIPC
+	}
+	return nil
+}
+
+// runSandboxed restricts the current process and replaces it by the
+// launcher. It only returns on errors.
+func runSandboxed(args []string) error {
+	var noNewPrivs, seccomp bool
+	for len(args) > 0 && args[0] != "--" {
+		switch args[0] {
+		case "--no-new-privileges":
+			noNewPrivs = true
+		case "--seccomp":
+			seccomp = true
+		default:
+			return fmt.Errorf("sandbox: unknown flag %s", args[0])
+		}
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return errors.New("sandbox: missing launcher")
+	}
+	args = args[1:]
+
+	runtime.LockOSThread()
+	if noNewPrivs || seccomp {
+		if err := prctl(prSetNoNewPrivs, 1); err != nil {
+			return fmt.Errorf("sandbox: no_new_privs: %w", err)
+		}
+	}
+	if seccomp {
+		filter, err := seccompFilter(runtime.GOARCH)
+		if err != nil {
+			return fmt.Errorf("sandbox: %w", err)
+		}
+		prog := syscall.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetSeccomp, seccompModeFilter, uintptr(unsafe.Pointer(&prog))); errno != 0 {
+			return fmt.Errorf("sandbox: seccomp: %w", errno)
+		}
+	}
+	return syscall.Exec(args[0], args, os.Environ())
+}
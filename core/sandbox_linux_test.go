@@ -0,0 +1,34 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"syscall"
+	"testing"
+)
+
+func TestSeccompFilter(t *testing.T) {
+	filter, err := seccompFilter("amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every deny jump lands on the final errno return
+	deny := len(filter) - 1
+	for i, f := range filter {
+		if f.Code == bpfJeqK && i > 3 {
+			assert.Equal(t, i+1+int(f.Jt), deny)
+		}
+		if f.Code == bpfJgeK {
+			assert.Equal(t, i+1+int(f.Jt), deny)
+		}
+	}
+	assert.Equal(t, filter[deny].K, uint32(seccompRetErrno|uint32(syscall.EPERM)))
+	assert.Equal(t, filter[deny-1].K, uint32(seccompRetAllow))
+
+	_, err = seccompFilter("mips")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestSandboxArgs(t *testing.T) {
+	args := DefaultConfig().Sandbox.sandboxArgs("/launcher", []string{"setup"})
+	assert.Equal(t, args, []string{sandboxCommand, "--no-new-privileges", "--seccomp", "--", "/launcher", "setup"})
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package core
+
+import (
+	"errors"
+	"os/exec"
+)
+
+var errSandboxUnsupported = errors.New("sandboxing is only supported on Linux")
+
+func (t *Launcher) sandbox(cmd *exec.Cmd) error {
+	return errSandboxUnsupported
+}
+
+func runSandboxed(args []string) error {
+	return errSandboxUnsupported
+}
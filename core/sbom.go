@@ -0,0 +1,179 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// sbomAssets are the release assets looked up for the SBOM of a release, in
+// order.
+var sbomAssets = []string{
+	"sbom.spdx.json",
+	"sbom.cdx.json",
+}
+
+type sbomPackage struct {
+	Name    string
+	Version string
+	License string
+}
+
+// parseSBOM returns the format and the packages of an SPDX or CycloneDX JSON
+// document.
+func parseSBOM(data []byte) (string, []sbomPackage, error) {
+	var doc struct {
+		SpdxVersion string `json:"spdxVersion"`
+		Packages    []struct {
+			Name             string `json:"name"`
+			VersionInfo      string `json:"versionInfo"`
+			LicenseConcluded string `json:"licenseConcluded"`
+			LicenseDeclared  string `json:"licenseDeclared"`
+		} `json:"packages"`
+
+		BomFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+		Components  []struct {
+			Name     string `json:"name"`
+			Version  string `json:"version"`
+			Licenses []struct {
+				License struct {
+					Id   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"license"`
+				Expression string `json:"expression"`
+			} `json:"licenses"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	var packages []sbomPackage
+	switch {
+	case doc.SpdxVersion != "":
+		for _, p := range doc.Packages {
+			license := p.LicenseConcluded
+			if license == "" || license == "NOASSERTION" {
+				license = p.LicenseDeclared
+			}
+			packages = append(packages, sbomPackage{Name: p.Name, Version: p.VersionInfo, License: license})
+		}
+		return doc.SpdxVersion, packages, nil
+	case doc.BomFormat == "CycloneDX":
+		for _, c := range doc.Components {
+			var licenses []string
+			for _, l := range c.Licenses {
+				switch {
+				case l.Expression != "":
+					licenses = append(licenses, l.Expression)
+				case l.License.Id != "":
+					licenses = append(licenses, l.License.Id)
+				case l.License.Name != "":
+					licenses = append(licenses, l.License.Name)
+				}
+			}
+			packages = append(packages, sbomPackage{Name: c.Name, Version: c.Version, License: strings.Join(licenses, " OR ")})
+		}
+		return "CycloneDX-" + doc.SpecVersion, packages, nil
+	default:
+		return "", nil, errors.New("neither an SPDX nor a CycloneDX document")
+	}
+}
+
+// releaseOfVersion returns the release tag of version, which is either a
+// release tag or (a prefix of) an installed commit.
+func (t *Launcher) releaseOfVersion(version string) (string, error) {
+	if ReleaseRef.MatchString(version) {
+		return version, nil
+	}
+	v, err := t.findVersion(version)
+	if err != nil {
+		return "", err
+	}
+	m, err := readMetadata(filepath.Dir(v.Path))
+	if err != nil {
+		return "", err
+	}
+	if !ReleaseRef.MatchString(m.Branch) {
+		return "", fmt.Errorf("%s was installed from branch %q, only releases publish an SBOM", shortCommit(v.Commit), m.Branch)
+	}
+	return m.Branch, nil
+}
+
+// getSBOM returns the SBOM of release tag, downloading it into the cache
+// when needed.
+func (t *Launcher) getSBOM(tag string) ([]byte, error) {
+	dir := filepath.Join(t.launcherDir, "sbom", tag)
+	for _, name := range sbomAssets {
+		if data, err := ioutil.ReadFile(filepath.Join(dir, name)); err == nil {
+			return data, nil
+		}
+	}
+	for _, name := range sbomAssets {
+		data, err := t.github.GetReleaseAsset(tag, name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("release %s has no SBOM (%s): %w", tag, strings.Join(sbomAssets, ", "), ErrNotFound)
+}
+
+func (t *Launcher) runSBOM(args []string) error {
+	fs := flag.NewFlagSet("sbom", flag.ContinueOnError)
+	raw := fs.Bool("raw", false, "print the SBOM document as it is")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: sbom [--raw] <release or installed commit>")
+	}
+
+	tag, err := t.releaseOfVersion(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	data, err := t.getSBOM(tag)
+	if err != nil {
+		return err
+	}
+	if *raw {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	format, packages, err := parseSBOM(data)
+	if err != nil {
+		return fmt.Errorf("parse SBOM: %w", err)
+	}
+	fmt.Printf("SBOM of %s (%s, %d packages)\n\n", tag, format, len(packages))
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tLICENSE")
+	for _, p := range packages {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, orDash(p.Version), orDash(p.License))
+	}
+	return w.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestParseSBOM(t *testing.T) {
+	format, packages, err := parseSBOM([]byte(`{
+  "spdxVersion": "SPDX-2.2",
+  "packages": [
+    {"name": "github.com/sirupsen/logrus", "versionInfo": "v1.7.0", "licenseConcluded": "NOASSERTION", "licenseDeclared": "MIT"}
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, format, "SPDX-2.2")
+	assert.Equal(t, packages, []sbomPackage{{Name: "github.com/sirupsen/logrus", Version: "v1.7.0", License: "MIT"}})
+
+	format, packages, err = parseSBOM([]byte(`{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "components": [
+    {"name": "go-toml", "version": "v1.8.1", "licenses": [{"license": {"id": "MIT"}}]}
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, format, "CycloneDX-1.4")
+	assert.Equal(t, packages, []sbomPackage{{Name: "go-toml", Version: "v1.8.1", License: "MIT"}})
+
+	_, _, err = parseSBOM([]byte(`{}`))
+	assert.Equal(t, err != nil, true)
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("access token is invalid or expired")
+
+// tokenScopes returns the scopes of token. Fine-grained tokens carry no
+// X-OAuth-Scopes header, for them ok is false.
+func (t *GithubClient) tokenScopes(token string) (scopes []string, ok bool, err error) {
+	req, err := http.NewRequest("GET", "https://api.github.com/rate_limit", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "token "+token)
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, false, ErrInvalidToken
+	}
+	if err := t.getResponseError(resp); err != nil {
+		return nil, false, err
+	}
+	header, ok := resp.Header["X-Oauth-Scopes"]
+	if !ok {
+		return nil, false, nil
+	}
+	for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, true, nil
+}
+
+// ValidateTokens checks every access token before anything is downloaded, so
+// an invalid or expired token fails with a clear message instead of a 401
+// halfway through. Tokens that cannot be checked are warned about.
+func (t *GithubClient) ValidateTokens() error {
+	for i, token := range t.Tokens.tokens {
+		scopes, ok, err := t.tokenScopes(token)
+		if errors.Is(err, ErrInvalidToken) {
+			return fmt.Errorf("token %d: %w", i+1, err)
+		}
+		if err != nil {
+			t.Logger.Warnf("Failed to validate access token %d: %s", i+1, err)
+			continue
+		}
+		if !ok {
+			t.Logger.Debugf("Access token %d is a fine-grained token, make sure it has read access to Actions and Contents", i+1)
+			continue
+		}
+		// classic tokens read the artifacts of public repositories without
+		// any scope
+		t.Logger.Debugf("Access token %d has the scopes: %s", i+1, strings.Join(scopes, ", "))
+	}
+	return nil
+}
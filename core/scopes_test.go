@@ -0,0 +1,32 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateTokens(t *testing.T) {
+	c := NewGithubClient("valid", "expired")
+	c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		header := make(http.Header)
+		status := http.StatusOK
+		switch req.Header.Get("Authorization") {
+		case "token valid":
+			header.Set("X-OAuth-Scopes", "gist")
+		default:
+			status = http.StatusUnauthorized
+		}
+		return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader("{}")), Header: header}, nil
+	})}
+	err := c.ValidateTokens()
+	assert.Equal(t, errors.Is(err, ErrInvalidToken), true)
+	assert.Equal(t, strings.HasPrefix(err.Error(), "token 2:"), true)
+
+	// a classic token without scopes reads public artifacts
+	c.Tokens = NewTokenPool("valid")
+	assert.Equal(t, c.ValidateTokens(), nil)
+}
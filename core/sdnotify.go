@@ -0,0 +1,70 @@
+package core
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotify sends state to systemd when opendex-launcher runs as a
+// Type=notify service and does nothing otherwise.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	conn, err := net.Dial("unixgram", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// watchdogInterval returns how often systemd has to be told that
+// opendex-launcher is alive, half of WatchdogSec as recommended.
+func watchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond / 2, true
+}
+
+// notifyReady tells systemd that the launcher is up once it kept running for
+// a moment after being started.
+func (t *Launcher) notifyReady(c *child) {
+	select {
+	case <-c.done:
+		return
+	case <-time.After(time.Second):
+	}
+	if err := sdNotify("READY=1\nSTATUS=Running launcher " + shortCommit(c.commit)); err != nil {
+		t.logger.Warnf("Failed to notify systemd: %s", err)
+	}
+}
+
+// startWatchdog sends systemd watchdog keep-alives while opendex-launcher is
+// responsive, so that a hung supervisor gets restarted.
+func (t *Launcher) startWatchdog() {
+	interval, ok := watchdogInterval()
+	if !ok {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			// status takes the lock shared with the supervisor loop
+			t.status()
+			if err := sdNotify("WATCHDOG=1"); err != nil {
+				t.logger.Warnf("Failed to notify systemd: %s", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,300 @@
+package core
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// EncryptedPrefix marks secret config values encrypted with
+// "config encrypt".
+const EncryptedPrefix = "enc:v1:"
+
+// PassphraseEnv holds the passphrase the key of encrypted config values is
+// derived from.
+const PassphraseEnv = "OPENDEX_CONFIG_PASSPHRASE"
+
+// Key sources of encrypted config values.
+const (
+	KeySourcePassphrase = "passphrase"
+	KeySourceKeychain   = "keychain"
+)
+
+const (
+	keychainService = "opendex-launcher"
+	keychainAccount = "config-key"
+	// pbkdf2Iterations stretches passphrases against guessing.
+	pbkdf2Iterations = 100000
+	saltSize         = 16
+)
+
+var (
+	ErrNoConfigKey      = errors.New("no key to decrypt the config")
+	ErrDecryptSecret    = errors.New("cannot decrypt config value")
+	ErrNoKeychain       = errors.New("no keychain")
+	ErrUnknownKeySource = errors.New("unknown key source")
+)
+
+type Secrets struct {
+	KeySource string `toml:"key-source" default:"passphrase" comment:"Where the key of enc:v1: values of secret keys comes from: passphrase ($OPENDEX_CONFIG_PASSPHRASE) or keychain (macOS Keychain or the Secret Service on Linux)"`
+}
+
+// pbkdf2 derives a key of size bytes from secret and salt with
+// HMAC-SHA256, as specified in RFC 8018.
+func pbkdf2(secret []byte, salt []byte, iterations int, size int) []byte {
+	prf := hmac.New(sha256.New, secret)
+	var key []byte
+	for block := uint32(1); len(key) < size; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], block)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:size]
+}
+
+func secretCipher(secret string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2([]byte(secret), salt, pbkdf2Iterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptSecret encrypts value with a key derived from secret.
+func encryptSecret(secret string, value string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	aead, err := secretCipher(secret, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	data := append(salt, nonce...)
+	data = aead.Seal(data, nonce, []byte(value), nil)
+	return EncryptedPrefix + base64.RawStdEncoding.EncodeToString(data), nil
+}
+
+// decryptSecret decrypts a value returned by encryptSecret.
+func decryptSecret(secret string, value string) (string, error) {
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrDecryptSecret, err)
+	}
+	if len(data) < saltSize {
+		return "", fmt.Errorf("%w: too short", ErrDecryptSecret)
+	}
+	aead, err := secretCipher(secret, data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: too short", ErrDecryptSecret)
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: wrong key or damaged value", ErrDecryptSecret)
+	}
+	return string(plain), nil
+}
+
+// decryptSecrets replaces the encrypted values of fields tagged
+// secret:"true" in the struct v, asking key for the key on the first one.
+func decryptSecrets(v reflect.Value, key func() (string, error)) error {
+	decrypt := func(value reflect.Value) error {
+		if !strings.HasPrefix(value.String(), EncryptedPrefix) {
+			return nil
+		}
+		secret, err := key()
+		if err != nil {
+			return err
+		}
+		plain, err := decryptSecret(secret, value.String())
+		if err != nil {
+			return err
+		}
+		value.SetString(plain)
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			if err := decryptSecrets(value, key); err != nil {
+				return err
+			}
+		case field.Tag.Get("secret") != "true":
+		case field.Type.Kind() == reflect.String:
+			if err := decrypt(value); err != nil {
+				return fmt.Errorf("%s: %w", field.Tag.Get("toml"), err)
+			}
+		case field.Type.Kind() == reflect.Slice:
+			// the slice may be shared with another config
+			decrypted := reflect.MakeSlice(field.Type, value.Len(), value.Len())
+			reflect.Copy(decrypted, value)
+			for j := 0; j < decrypted.Len(); j++ {
+				if err := decrypt(decrypted.Index(j)); err != nil {
+					return fmt.Errorf("%s: %w", field.Tag.Get("toml"), err)
+				}
+			}
+			value.Set(decrypted)
+		}
+	}
+	return nil
+}
+
+// keychainKey returns the key stored in the keychain of the OS, creating it
+// when create is set and there is none yet.
+func keychainKey(create bool) (string, error) {
+	var lookup *exec.Cmd
+	var store func(key string) *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		lookup = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount, "-w")
+		store = func(key string) *exec.Cmd {
+			// security -i reads the command from stdin, command lines are
+			// readable by every local user
+			cmd := exec.Command("security", "-i")
+			cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s %q -a %q -w %q\n", keychainService, keychainAccount, key))
+			return cmd
+		}
+	case "linux":
+		lookup = exec.Command("secret-tool", "lookup", "service", keychainService, "account", keychainAccount)
+		store = func(key string) *exec.Cmd {
+			cmd := exec.Command("secret-tool", "store", "--label", "opendex-launcher config key", "service", keychainService, "account", keychainAccount)
+			cmd.Stdin = strings.NewReader(key)
+			return cmd
+		}
+	default:
+		return "", fmt.Errorf("%w on %s, use a passphrase", ErrNoKeychain, runtime.GOOS)
+	}
+	if output, err := lookup.Output(); err == nil && strings.TrimSpace(string(output)) != "" {
+		return strings.TrimSpace(string(output)), nil
+	}
+	if !create {
+		return "", fmt.Errorf("%w: none in the keychain", ErrNoConfigKey)
+	}
+
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(data)
+	if output, err := store(key).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("store key in the keychain: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return key, nil
+}
+
+// configKey returns the secret encrypted config values are decrypted with,
+// creating a keychain key when create is set.
+func (t *Launcher) configKey(create bool) (string, error) {
+	switch t.config.Secrets.KeySource {
+	case KeySourcePassphrase, "":
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return "", fmt.Errorf("%w: set $%s", ErrNoConfigKey, PassphraseEnv)
+		}
+		return passphrase, nil
+	case KeySourceKeychain:
+		return keychainKey(create)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownKeySource, t.config.Secrets.KeySource)
+	}
+}
+
+// decryptConfig decrypts the encrypted secret values of the config in
+// memory. The key is only looked up when there are any.
+func (t *Launcher) decryptConfig() error {
+	var secret string
+	key := func() (string, error) {
+		if secret != "" {
+			return secret, nil
+		}
+		var err error
+		secret, err = t.configKey(false)
+		return secret, err
+	}
+	c := *t.config
+	if err := decryptSecrets(reflect.ValueOf(&c).Elem(), key); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	t.setConfig(&c)
+	return nil
+}
+
+// runConfigEncrypt reads a secret value from stdin, so that it stays out
+// of the shell history, and prints it encrypted for the config file.
+func (t *Launcher) runConfigEncrypt(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: config encrypt < value")
+	}
+	if isTerminal(os.Stdin) {
+		fmt.Fprintln(os.Stderr, "Value to encrypt:")
+	}
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	value = strings.TrimRight(value, "\r\n")
+	if value == "" {
+		if err != nil {
+			return fmt.Errorf("read value: %w", err)
+		}
+		return errors.New("empty value")
+	}
+	secret, err := t.configKey(true)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptSecret(secret, value)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encrypted)
+	return nil
+}
+
+// childEnviron returns the environment of opendex-launcher without the
+// passphrase of the config, for the launcher and hooks.
+func childEnviron() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, PassphraseEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestPbkdf2(t *testing.T) {
+	key := pbkdf2([]byte("password"), []byte("salt"), 1, 32)
+	assert.Equal(t, hex.EncodeToString(key), "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b")
+	key = pbkdf2([]byte("password"), []byte("salt"), 2, 32)
+	assert.Equal(t, hex.EncodeToString(key), "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43")
+}
+
+func TestEncryptSecret(t *testing.T) {
+	encrypted, err := encryptSecret("passphrase", "ghp_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, encrypted[:len(EncryptedPrefix)], EncryptedPrefix)
+
+	plain, err := decryptSecret("passphrase", encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, plain, "ghp_token")
+
+	_, err = decryptSecret("wrong", encrypted)
+	assert.Equal(t, errors.Is(err, ErrDecryptSecret), true)
+	_, err = decryptSecret("passphrase", EncryptedPrefix+"AAAA")
+	assert.Equal(t, errors.Is(err, ErrDecryptSecret), true)
+}
+
+func TestDecryptSecrets(t *testing.T) {
+	token, err := encryptSecret("passphrase", "ghp_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := encryptSecret("passphrase", "https://hooks.example.com/secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := []string{"plain", token}
+	c := DefaultConfig()
+	c.GitHub.AccessToken = token
+	c.GitHub.AccessTokens = tokens
+	c.Webhook.Url = webhook
+	c.Webhook.Format = token
+
+	asked := 0
+	key := func() (string, error) {
+		asked++
+		return "passphrase", nil
+	}
+	if err := decryptSecrets(reflect.ValueOf(c).Elem(), key); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, c.GitHub.AccessToken, "ghp_token")
+	assert.Equal(t, c.GitHub.AccessTokens, []string{"plain", "ghp_token"})
+	assert.Equal(t, c.Webhook.Url, "https://hooks.example.com/secret")
+	// only keys tagged secret are decrypted
+	assert.Equal(t, c.Webhook.Format, token)
+	assert.Equal(t, tokens[1], token)
+	assert.Equal(t, asked, 3)
+
+	plain := DefaultConfig()
+	err = decryptSecrets(reflect.ValueOf(plain).Elem(), func() (string, error) {
+		return "", ErrNoConfigKey
+	})
+	assert.Equal(t, err, nil)
+}
+
+func TestChildEnviron(t *testing.T) {
+	os.Setenv(PassphraseEnv, "passphrase")
+	defer os.Unsetenv(PassphraseEnv)
+
+	for _, kv := range childEnviron() {
+		assert.Equal(t, kv == PassphraseEnv+"=passphrase", false)
+	}
+}
@@ -0,0 +1,281 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// serviceArgs returns the command line opendex-launcher is started with as
+// a service.
+func (t *Launcher) serviceArgs(rest []string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if exe, err = filepath.Abs(exe); err != nil {
+		return nil, err
+	}
+	args := []string{exe, "--network", t.network, "--branch", t.branch}
+	if t.args != nil && t.args.profile != "" {
+		args = append(args, "--profile", t.args.profile)
+	}
+	if len(rest) == 0 || rest[0] != "start" {
+		args = append(args, "--")
+	}
+	return append(args, rest...), nil
+}
+
+func (t *Launcher) runServiceInstall(args []string) error {
+	fs := flag.NewFlagSet("service install", flag.ContinueOnError)
+	daemon := fs.Bool("daemon", false, "install a system-wide LaunchDaemon instead of a LaunchAgent of the user (macOS, as root)")
+	method := fs.String("method", "task", "start at logon with a scheduled task (task) or a Run key entry (run-key) (Windows)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if t.network == "" {
+		return ErrNetworkEmpty
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		serviceArgs, err := t.serviceArgs(fs.Args())
+		if err != nil {
+			return err
+		}
+		return t.installLaunchd(serviceArgs, *daemon)
+	case "windows":
+		// there is no supervisor restarting it, run in the background
+		// instead of keeping a console window open
+		serviceArgs, err := t.serviceArgs(append([]string{"start", "--detach"}, fs.Args()...))
+		if err != nil {
+			return err
+		}
+		return t.installAutostart(serviceArgs, *method)
+	default:
+		return fmt.Errorf("service install is not supported on %s, see the README for systemd", runtime.GOOS)
+	}
+}
+
+func (t *Launcher) runServiceUninstall(args []string) error {
+	fs := flag.NewFlagSet("service uninstall", flag.ContinueOnError)
+	daemon := fs.Bool("daemon", false, "remove the system-wide LaunchDaemon (macOS, as root)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if t.network == "" {
+		return ErrNetworkEmpty
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return t.uninstallLaunchd(*daemon)
+	case "windows":
+		return t.uninstallAutostart()
+	default:
+		return fmt.Errorf("service uninstall is not supported on %s", runtime.GOOS)
+	}
+}
+
+func (t *Launcher) launchdLabel() string {
+	return "network.opendex.launcher." + t.network
+}
+
+func (t *Launcher) launchdPlistPath(daemon bool) (string, error) {
+	name := t.launchdLabel() + ".plist"
+	if daemon {
+		if os.Geteuid() != 0 {
+			return "", errors.New("a LaunchDaemon has to be installed as root")
+		}
+		return filepath.Join("/Library/LaunchDaemons", name), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", name), nil
+}
+
+func writePlistString(b *bytes.Buffer, s string) {
+	b.WriteString("<string>")
+	_ = xml.EscapeText(b, []byte(s))
+	b.WriteString("</string>")
+}
+
+// launchdPlist renders a launchd job starting args at load and again when it
+// did not exit successfully.
+func launchdPlist(label string, args []string, env map[string]string, logFile string) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	b.WriteString("\t<key>Label</key>\n\t")
+	writePlistString(&b, label)
+	b.WriteString("\n\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range args {
+		b.WriteString("\t\t")
+		writePlistString(&b, arg)
+		b.WriteString("\n")
+	}
+	b.WriteString("\t</array>\n")
+	if len(env) > 0 {
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t", key)
+			writePlistString(&b, env[key])
+			b.WriteString("\n")
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	for _, key := range []string{"StandardOutPath", "StandardErrorPath"} {
+		fmt.Fprintf(&b, "\t<key>%s</key>\n\t", key)
+		writePlistString(&b, logFile)
+		b.WriteString("\n")
+	}
+	b.WriteString("</dict>\n</plist>\n")
+	return b.Bytes()
+}
+
+func (t *Launcher) installLaunchd(args []string, daemon bool) error {
+	file, err := t.launchdPlistPath(daemon)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.logFile()), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	// launchd starts jobs with a minimal PATH, docker is usually installed
+	// in /usr/local/bin
+	env := map[string]string{
+		"HOME": os.Getenv("HOME"),
+		"PATH": "/usr/local/bin:/opt/homebrew/bin:/usr/bin:/bin:/usr/sbin:/sbin",
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	if err := ioutil.WriteFile(file, launchdPlist(t.launchdLabel(), args, env, t.logFile()), 0644); err != nil {
+		return err
+	}
+	if output, err := exec.Command("launchctl", "load", "-w", file).CombinedOutput(); err != nil {
+		return fmt.Errorf("launchctl load: %w: %s", err, bytes.TrimSpace(output))
+	}
+	when := "login"
+	if daemon {
+		when = "boot"
+	}
+	fmt.Printf("Installed %s, %s now starts at %s\n", file, t.network, when)
+	return nil
+}
+
+func (t *Launcher) uninstallLaunchd(daemon bool) error {
+	file, err := t.launchdPlistPath(daemon)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("%s is not installed: %w", t.launchdLabel(), err)
+	}
+	if output, err := exec.Command("launchctl", "unload", "-w", file).CombinedOutput(); err != nil {
+		t.logger.Warnf("launchctl unload: %s: %s", err, bytes.TrimSpace(output))
+	}
+	if err := os.Remove(file); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s\n", file)
+	return nil
+}
+
+// runKey is where Windows keeps the programs started at logon of the user.
+const runKey = `HKCU\Software\Microsoft\Windows\CurrentVersion\Run`
+
+func (t *Launcher) autostartName() string {
+	return "opendex-launcher-" + t.network
+}
+
+// windowsCommandLine quotes args the way Windows programs split their
+// command line again.
+func windowsCommandLine(args []string) string {
+	var b strings.Builder
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+			b.WriteString(arg)
+			continue
+		}
+		b.WriteByte('"')
+		backslashes := 0
+		for _, c := range arg {
+			switch c {
+			case '\\':
+				backslashes++
+				continue
+			case '"':
+				// backslashes in front of a quote are escaped, and the quote too
+				b.WriteString(strings.Repeat("\\", 2*backslashes+1))
+			default:
+				b.WriteString(strings.Repeat("\\", backslashes))
+			}
+			backslashes = 0
+			b.WriteRune(c)
+		}
+		// as are backslashes in front of the closing quote
+		b.WriteString(strings.Repeat("\\", 2*backslashes))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// installAutostart registers args to run at logon with a scheduled task or
+// a Run key entry.
+func (t *Launcher) installAutostart(args []string, method string) error {
+	commandLine := windowsCommandLine(args)
+	var cmd *exec.Cmd
+	switch method {
+	case "task":
+		cmd = exec.Command("schtasks", "/Create", "/TN", t.autostartName(), "/TR", commandLine, "/SC", "ONLOGON", "/RL", "LIMITED", "/F")
+	case "run-key":
+		cmd = exec.Command("reg", "add", runKey, "/v", t.autostartName(), "/t", "REG_SZ", "/d", commandLine, "/f")
+	default:
+		return fmt.Errorf("unsupported method: %s (task or run-key)", method)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, bytes.TrimSpace(output))
+	}
+	fmt.Printf("%s now starts at logon (%s)\n", t.network, method)
+	return nil
+}
+
+// uninstallAutostart removes both the scheduled task and the Run key entry,
+// whichever exists.
+func (t *Launcher) uninstallAutostart() error {
+	removed := false
+	if err := exec.Command("schtasks", "/Delete", "/TN", t.autostartName(), "/F").Run(); err == nil {
+		removed = true
+	}
+	if err := exec.Command("reg", "delete", runKey, "/v", t.autostartName(), "/f").Run(); err == nil {
+		removed = true
+	}
+	if !removed {
+		return fmt.Errorf("%s is not installed", t.autostartName())
+	}
+	fmt.Printf("%s no longer starts at logon\n", t.network)
+	return nil
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := launchdPlist("network.opendex.launcher.mainnet",
+		[]string{"/usr/local/bin/opendex-launcher", "--network", "mainnet", "--", "setup", "a&b"},
+		map[string]string{"PATH": "/usr/bin", "HOME": "/Users/me"},
+		"/Users/me/.opendex-docker/launcher/logs/mainnet.log")
+
+	assert.Equal(t, string(plist), `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>network.opendex.launcher.mainnet</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/usr/local/bin/opendex-launcher</string>
+		<string>--network</string>
+		<string>mainnet</string>
+		<string>--</string>
+		<string>setup</string>
+		<string>a&amp;b</string>
+	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>HOME</key>
+		<string>/Users/me</string>
+		<key>PATH</key>
+		<string>/usr/bin</string>
+	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>StandardOutPath</key>
+	<string>/Users/me/.opendex-docker/launcher/logs/mainnet.log</string>
+	<key>StandardErrorPath</key>
+	<string>/Users/me/.opendex-docker/launcher/logs/mainnet.log</string>
+</dict>
+</plist>
+`)
+}
+
+func TestWindowsCommandLine(t *testing.T) {
+	line := windowsCommandLine([]string{`C:\Program Files\opendex-launcher.exe`, "--network", "mainnet", "", `say "hi"`, `C:\dir\`, `a\\b`})
+
+	assert.Equal(t, line, `"C:\Program Files\opendex-launcher.exe" --network mainnet "" "say \"hi\"" C:\dir\ a\\b`)
+}
+
+func TestWindowsCommandLineTrailingBackslash(t *testing.T) {
+	assert.Equal(t, windowsCommandLine([]string{`C:\my dir\`}), `"C:\my dir\\"`)
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+)
+
+// Source tells where the effective value of a setting came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceConfig  Source = "config file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	SourceOption  Source = "option"
+)
+
+// Setting is an effective setting value together with its origin.
+type Setting struct {
+	Name   string
+	Value  string
+	Source Source
+	// Origin names the variable, key or flag the value was read from.
+	Origin string
+}
+
+func (s Setting) String() string {
+	if s.Origin == "" {
+		return string(s.Source)
+	}
+	return fmt.Sprintf("%s (%s)", s.Source, s.Origin)
+}
+
+func envSetting(name string, key string, defaultValue string) Setting {
+	if value, ok := os.LookupEnv(key); ok {
+		return Setting{Name: name, Value: value, Source: SourceEnv, Origin: key}
+	}
+	return Setting{Name: name, Value: defaultValue, Source: SourceDefault}
+}
+
+// flagSetting prefers the flag value over the environment variable key.
+func flagSetting(name string, flagName string, flagValue string, key string, defaultValue string) Setting {
+	if flagValue != "" {
+		return Setting{Name: name, Value: flagValue, Source: SourceFlag, Origin: "--" + flagName}
+	}
+	return envSetting(name, key, defaultValue)
+}
+
+func (t *Launcher) resolveNetwork() Setting {
+	p, _ := t.profile()
+	s := t.profileSetting(flagSetting("network", "network", t.args.network, "NETWORK", "mainnet"), "network", p.Network)
+	return optionSetting(s, "WithNetwork", t.options.network)
+}
+
+func (t *Launcher) resolveBranch() Setting {
+	p, _ := t.profile()
+	s := t.profileSetting(flagSetting("branch", "branch", t.args.branch, "BRANCH", "master"), "branch", p.Branch)
+	return optionSetting(s, "WithBranch", t.options.branch)
+}
+
+// accessTokens returns the GitHub access tokens to use. A token given with
+// --access-token or $GITHUB_ACCESS_TOKEN replaces the tokens of the config
+// file.
+func (t *Launcher) accessTokens() []string {
+	if t.args != nil && t.args.accessToken != "" {
+		return []string{t.args.accessToken}
+	}
+	if value := os.Getenv("GITHUB_ACCESS_TOKEN"); value != "" {
+		return []string{value}
+	}
+	if t.config == nil {
+		return nil
+	}
+	return t.config.GitHub.Tokens()
+}
+
+func (t *Launcher) resolveAccessToken() Setting {
+	s := Setting{Name: "access token", Value: "not set", Source: SourceDefault}
+	if t.args != nil && t.args.accessToken != "" {
+		return Setting{Name: s.Name, Value: "set", Source: SourceFlag, Origin: "--access-token"}
+	}
+	if os.Getenv("GITHUB_ACCESS_TOKEN") != "" {
+		return Setting{Name: s.Name, Value: "set", Source: SourceEnv, Origin: "GITHUB_ACCESS_TOKEN"}
+	}
+	if t.config == nil {
+		return s
+	}
+	n := NewTokenPool(t.config.GitHub.Tokens()...).Len()
+	switch {
+	case n == 0:
+		return s
+	case n == 1:
+		s.Value = "set"
+	default:
+		s.Value = fmt.Sprintf("%d tokens", n)
+	}
+	s.Origin = "GitHub.access-token"
+	if len(t.config.GitHub.AccessTokens) > 0 {
+		s.Origin = "GitHub.access-tokens"
+	}
+	s.Source = SourceConfig
+	return s
+}
+
+func (t *Launcher) resolveProxy() Setting {
+	s := Setting{Name: "proxy", Value: "none", Source: SourceDefault}
+	if socks5, err := t.config.Proxy.url(); err == nil && socks5 != nil {
+		if socks5.User != nil {
+			socks5.User = url.User("xxxxx")
+		}
+		s.Value = socks5.String()
+		s.Source = SourceConfig
+		s.Origin = "proxy.socks5"
+		return s
+	}
+	req, err := http.NewRequest("GET", "https://api.github.com", nil)
+	if err != nil {
+		return s
+	}
+	proxy, err := http.ProxyFromEnvironment(req)
+	if err != nil || proxy == nil {
+		return s
+	}
+	if proxy.User != nil {
+		proxy.User = url.User("xxxxx")
+	}
+	s.Value = proxy.String()
+	s.Source = SourceEnv
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if _, ok := os.LookupEnv(key); ok {
+			s.Origin = key
+			break
+		}
+	}
+	return s
+}
+
+// effectiveSettings resolves every setting which influences what gets
+// downloaded and executed.
+func (t *Launcher) effectiveSettings() []Setting {
+	return []Setting{
+		t.resolveNetwork(),
+		t.resolveBranch(),
+		optionSetting(Setting{Name: "home dir", Value: t.homeDir, Source: SourceDefault}, "WithHomeDir", t.options.homeDir),
+		t.resolveAccessToken(),
+		t.resolveProxy(),
+	}
+}
+
+func (t *Launcher) runEnv(args []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, s := range t.effectiveSettings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.Value, s)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrSharedDirUnsafe = errors.New("shared cache dir is writable by everyone")
+
+// sharedDirMode is the mode of the directories of the shared cache. The
+// setgid bit makes new entries inherit the group of the shared dir.
+const sharedDirMode = os.ModeDir | os.ModeSetgid | 0775
+
+// checkSharedDir creates dir for several users of its group, or checks that
+// an existing dir is not writable by everyone, which would let any user
+// swap the launchers the others run.
+func checkSharedDir(dir string) error {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0775); err != nil {
+			return err
+		}
+		// the mode of Mkdir is masked by the umask, Chmod is not
+		return os.Chmod(dir, sharedDirMode)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a folder: %s", dir)
+	}
+	if info.Mode().Perm()&0002 != 0 {
+		return fmt.Errorf("%w: %s", ErrSharedDirUnsafe, dir)
+	}
+	return nil
+}
+
+// sharedMode returns mode with group access to everything the owner may
+// read, write or run.
+func sharedMode(mode os.FileMode) os.FileMode {
+	shared := mode | (mode&0700)>>3
+	if mode.IsDir() {
+		shared |= os.ModeSetgid
+	}
+	return shared
+}
+
+// shareTree grants the group of the shared cache access to the files below
+// root, regardless of the umask they were created with. Files of other users
+// are left alone, only their owner may change them.
+func shareTree(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		mode := sharedMode(info.Mode())
+		if mode == info.Mode() {
+			return nil
+		}
+		if err := os.Chmod(path, mode&(os.ModePerm|os.ModeSetgid)); err != nil && !os.IsPermission(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// cacheDir returns the dir of the artifact cache.
+func (t *Launcher) cacheDir() string {
+	if t.config.Cache.SharedDir != "" {
+		return filepath.Join(t.config.Cache.SharedDir, "cache")
+	}
+	return filepath.Join(t.launcherDir, "cache")
+}
+
+// useSharedCache moves the versions dir into cache.shared-dir.
+func (t *Launcher) useSharedCache() error {
+	dir := t.config.Cache.SharedDir
+	if dir == "" {
+		return nil
+	}
+	if err := checkSharedDir(dir); err != nil {
+		return err
+	}
+	for _, sub := range []string{"versions", "cache"} {
+		if err := checkSharedDir(filepath.Join(dir, sub)); err != nil {
+			return err
+		}
+	}
+	t.launcherVersionsDir = filepath.Join(dir, "versions")
+	return nil
+}
+
+// verifyShared checks that a launcher of the shared cache, which another user
+// may have installed, has the hash pinned for commit by this user. The
+// launcher is left in place for its other users. Launchers built from source
+// are not pinned.
+func (t *Launcher) verifyShared(commit string, launcher string) error {
+	if t.config.Cache.SharedDir == "" {
+		return nil
+	}
+	m, err := readMetadata(filepath.Dir(launcher))
+	if err != nil {
+		return err
+	}
+	if m.Built {
+		return nil
+	}
+	_, err = pinHash(filepath.Join(t.launcherDir, KnownHashesFilename), commit, t.versionArch(m), launcher)
+	if errors.Is(err, ErrArtifactChanged) {
+		t.emit(Event{Type: EventArtifactChanged, Commit: commit, Message: err.Error()})
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("pin hash: %w", err)
+	}
+	return nil
+}
+
+// shareVersion grants the group of the shared cache access to the installed
+// version commit and the cached archives.
+func (t *Launcher) shareVersion(commit string) {
+	if t.config.Cache.SharedDir == "" {
+		return
+	}
+	for _, dir := range []string{filepath.Dir(t.launcherPath(commit)), t.cacheDir()} {
+		if err := shareTree(dir); err != nil {
+			t.logger.Warnf("Failed to share %s: %s", dir, err)
+		}
+	}
+}
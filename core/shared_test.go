@@ -0,0 +1,80 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSharedMode(t *testing.T) {
+	assert.Equal(t, sharedMode(0644), os.FileMode(0664))
+	assert.Equal(t, sharedMode(0755), os.FileMode(0775))
+	assert.Equal(t, sharedMode(os.ModeDir|0700), os.ModeDir|os.ModeSetgid|0770)
+}
+
+func TestShareTree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no group permissions on windows")
+	}
+	dir, err := ioutil.TempDir("", "shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	commitDir := filepath.Join(dir, "abc")
+	if err := os.Mkdir(commitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	launcher := filepath.Join(commitDir, "launcher")
+	if err := ioutil.WriteFile(launcher, []byte("launcher"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(launcher, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := shareTree(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(commitDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, info.Mode(), os.ModeDir|os.ModeSetgid|0775)
+	info, err = os.Stat(launcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, info.Mode(), os.FileMode(0770))
+}
+
+func TestCheckSharedDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no group permissions on windows")
+	}
+	dir, err := ioutil.TempDir("", "shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	shared := filepath.Join(dir, "cache")
+	if err := checkSharedDir(shared); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, info.Mode(), sharedDirMode)
+
+	if err := os.Chmod(shared, 0777); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, errors.Is(checkSharedDir(shared), ErrSharedDirUnsafe), true)
+}
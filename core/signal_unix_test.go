@@ -0,0 +1,33 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"context"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalBeforeLaunch(t *testing.T) {
+	l := &Launcher{config: DefaultConfig(), logger: logrus.NewEntry(logrus.New())}
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	defer l.cancel()
+	stop := l.handleSignals()
+	defer stop()
+
+	// no launcher runs yet, the start is aborted instead of exiting
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-l.ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("start not aborted")
+	}
+	assert.Equal(t, l.takeStopRequested(), true)
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Simnet struct {
+	Provision bool `toml:"provision" default:"true" comment:"Create the directories and config file simnet expects in its data directory before starting it"`
+}
+
+// simnetDirs are created in the data dir of simnet with their permissions.
+var simnetDirs = []struct {
+	name string
+	perm os.FileMode
+}{
+	{"data", 0755},
+	{"logs", 0755},
+	{"backup", 0700},
+}
+
+// simnetConfig is written to simnet.conf unless the file exists. The
+// launcher falls back to its defaults for every setting left out.
+const simnetConfig = `# Settings of the simnet launcher, created by opendex-launcher.
+#
+# Simnet is a local simulation network: its chains, coins and wallets are
+# not real and can be thrown away at any time. The launcher uses its
+# defaults for every setting not given here.
+`
+
+// provisionSimnet creates the directory structure and default config of
+// simnet in its data dir and tells the launcher to run simnet, so that a
+// single command gets developers a working simnet. Existing files are left
+// alone.
+func (t *Launcher) provisionSimnet() error {
+	if t.network != "simnet" || !t.config.Simnet.Provision || t.networkDir == "" {
+		return nil
+	}
+	var created []string
+	for _, d := range simnetDirs {
+		dir := filepath.Join(t.networkDir, d.name)
+		exists, err := utils.FileExists(dir)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := os.Mkdir(dir, d.perm); err != nil {
+			return fmt.Errorf("provision simnet: %w", err)
+		}
+		created = append(created, d.name)
+	}
+	conf := filepath.Join(t.networkDir, "simnet.conf")
+	exists, err := utils.FileExists(conf)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := ioutil.WriteFile(conf, []byte(simnetConfig), 0644); err != nil {
+			return fmt.Errorf("provision simnet: %w", err)
+		}
+		created = append(created, "simnet.conf")
+	}
+	if len(created) > 0 {
+		t.logger.Infof("Provisioned simnet in %s: %s", t.networkDir, strings.Join(created, ", "))
+	}
+	t.childEnv = append(t.childEnv, "NETWORK=simnet")
+	return nil
+}
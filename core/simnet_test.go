@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProvisionSimnet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "simnet.conf"), []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l := &Launcher{network: "simnet", networkDir: dir, config: DefaultConfig(), logger: logrus.NewEntry(logrus.New())}
+
+	if err := l.provisionSimnet(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"data", "logs", "backup"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, info.IsDir(), true)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "simnet.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(data), "custom")
+	assert.Equal(t, l.childEnv, []string{"NETWORK=simnet"})
+
+	testnet, err := ioutil.TempDir("", "testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testnet)
+	l = &Launcher{network: "testnet", networkDir: testnet, config: DefaultConfig()}
+	if err := l.provisionSimnet(); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := ioutil.ReadDir(testnet)
+	assert.Equal(t, len(entries), 0)
+}
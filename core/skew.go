@@ -0,0 +1,54 @@
+package core
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// clockSkew returns how far the clock at now is ahead of the Date header of
+// resp, negative when it is behind.
+func clockSkew(resp *http.Response, now time.Time) (time.Duration, bool) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	// the header has a resolution of one second
+	return now.Truncate(time.Second).Sub(date), true
+}
+
+// checkClock warns once when the system clock differs from the Date of a
+// response of GitHub by more than MaxSkew. A wrong clock breaks TLS and the
+// node stack in ways that are hard to trace back to it.
+func (t *GithubClient) checkClock(req *http.Request, resp *http.Response, now time.Time) {
+	if t.MaxSkew <= 0 || !isGitHubHost(req.URL.Host) {
+		return
+	}
+	skew, ok := clockSkew(resp, now)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.skewWarned || skew <= t.MaxSkew && skew >= -t.MaxSkew {
+		return
+	}
+	t.skewWarned = true
+	direction := "ahead of"
+	if skew < 0 {
+		direction, skew = "behind", -skew
+	}
+	t.Logger.Warnf("CLOCK SKEW: the system clock is %s %s GitHub, TLS connections and the node stack are likely to fail, synchronize it (e.g. enable NTP)", skew, direction)
+}
+
+// clockHint points out that a certificate rejected as expired or not yet
+// valid may be caused by a wrong system clock.
+func clockHint(err error) error {
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &invalid) && invalid.Reason == x509.Expired {
+		return fmt.Errorf("%w (check the system clock, it is %s)", err, time.Now().UTC().Format(time.RFC3339))
+	}
+	return err
+}
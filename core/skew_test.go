@@ -0,0 +1,59 @@
+package core
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockSkew(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 10, 0, 500, time.UTC)
+	resp := &http.Response{Header: http.Header{}}
+
+	_, ok := clockSkew(resp, now)
+	assert.Equal(t, ok, false)
+
+	resp.Header.Set("Date", "Mon, 01 Mar 2021 12:00:00 GMT")
+	skew, ok := clockSkew(resp, now)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, skew, 10*time.Minute)
+}
+
+func TestCheckClock(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	c := NewGithubClient()
+	c.Logger = logrus.NewEntry(logger)
+	c.MaxSkew = 5 * time.Minute
+	req, _ := http.NewRequest("GET", "https://api.github.com/repos", nil)
+	resp := &http.Response{Header: http.Header{"Date": {"Mon, 01 Mar 2021 12:00:00 GMT"}}}
+
+	c.checkClock(req, resp, time.Date(2021, 3, 1, 12, 4, 0, 0, time.UTC))
+	assert.Equal(t, out.Len(), 0)
+
+	c.checkClock(req, resp, time.Date(2021, 3, 1, 11, 50, 0, 0, time.UTC))
+	assert.Equal(t, strings.Count(out.String(), "CLOCK SKEW"), 1)
+	assert.Equal(t, strings.Contains(out.String(), "10m0s behind GitHub"), true)
+
+	// warned once
+	c.checkClock(req, resp, time.Date(2021, 3, 1, 11, 50, 0, 0, time.UTC))
+	assert.Equal(t, strings.Count(out.String(), "CLOCK SKEW"), 1)
+}
+
+func TestClockHint(t *testing.T) {
+	err := fmt.Errorf("get: %w", x509.CertificateInvalidError{Reason: x509.Expired})
+	hinted := clockHint(err)
+	assert.Equal(t, errors.Is(hinted, err), true)
+	assert.Equal(t, hinted == err, false)
+
+	err = errors.New("connection refused")
+	assert.Equal(t, clockHint(err), err)
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// sourceRepository is cloned to build launchers CI published no build for.
+const sourceRepository = "https://github.com/opendexnetwork/opendex-docker.git"
+
+// buildImage is the golang image launchers are built in when Go is not
+// installed. It is pinned, so builds do not change with the latest tag.
+const buildImage = "golang:1.15.15"
+
+// buildLauncher builds the launcher module in src into out, with the Go
+// toolchain when it is installed, otherwise in buildImage with Docker, as
+// the current user.
+func buildLauncher(src string, out string) error {
+	var cmd *exec.Cmd
+	if _, err := exec.LookPath("go"); err == nil {
+		cmd = exec.Command("go", "build", "-o", out, ".")
+		cmd.Dir = src
+	} else if _, err := exec.LookPath("docker"); err == nil {
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return err
+		}
+		cmd = exec.Command("docker", dockerBuildArgs(src, out, os.Getuid(), os.Getgid())...)
+	} else {
+		return errors.New("building the launcher needs Go or Docker")
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// dockerBuildArgs returns the arguments of docker building src into out in
+// buildImage as uid and gid, with the caches of Go in the container. A uid
+// below 0, as on Windows, builds as the user of the image.
+func dockerBuildArgs(src string, out string, uid int, gid int) []string {
+	args := []string{"run", "--rm"}
+	if uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	return append(args,
+		"-v", src+":/src", "-v", filepath.Dir(out)+":/out", "-w", "/src",
+		"-e", "HOME=/tmp", "-e", "GOCACHE=/tmp/go-cache", "-e", "GOPATH=/tmp/go",
+		"-e", "GOOS="+runtime.GOOS, "-e", "GOARCH="+runtime.GOARCH, "-e", "CGO_ENABLED=0",
+		buildImage, "go", "build", "-o", "/out/"+filepath.Base(out), ".")
+}
+
+// fetchSource checks out commit of opendex-docker into dir.
+func fetchSource(dir string, commit string) error {
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", sourceRepository, commit},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("git %s: %w", args[0], err)
+		}
+	}
+	return nil
+}
+
+// buildFromSource installs the launcher of commit by building it from the
+// sources of opendex-docker at commit.
+func (t *Launcher) buildFromSource(commit string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("building the launcher needs git: %w", err)
+	}
+	tmp, err := ioutil.TempDir(t.launcherDir, "source-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	t.logger.Infof("No launcher build for %s, building it from source", shortCommit(commit))
+	t.reportPhase(PhaseBuild, 0, "Fetching the sources of %s", shortCommit(commit))
+	if err := fetchSource(tmp, commit); err != nil {
+		return fmt.Errorf("fetch sources: %w", err)
+	}
+	src, err := devSourceDir(tmp)
+	if err != nil {
+		return err
+	}
+
+	launcher := t.launcherPath(commit)
+	dir := filepath.Dir(launcher)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	t.reportPhase(PhaseBuild, 50, "Building launcher %s", shortCommit(commit))
+	if err := buildLauncher(src, launcher); err != nil {
+		_ = os.RemoveAll(dir)
+		return fmt.Errorf("build launcher: %w", err)
+	}
+	t.reportPhase(PhaseBuild, 100, "Built launcher %s", shortCommit(commit))
+
+	m := &VersionMetadata{Branch: t.branch, Commit: commit, Url: sourceRepository, Built: true, InstalledAt: time.Now()}
+	return writeMetadata(dir, m)
+}
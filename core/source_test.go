@@ -0,0 +1,94 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadReleaseWithoutArtifact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewGithubClient()
+	c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader("Not Found")), Header: make(http.Header)}, nil
+	})}
+
+	err = c.DownloadLatestBinary("21.01.01", "abc", dir)
+
+	assert.Equal(t, errors.Is(err, ErrNoArtifact), true)
+}
+
+func TestBuildLauncher(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "launcher")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "go.mod"), []byte("module launcher\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out", launcherBinary())
+	if err := buildLauncher(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = os.Stat(out)
+	assert.Equal(t, err, nil)
+}
+
+func TestDockerBuildArgs(t *testing.T) {
+	args := strings.Join(dockerBuildArgs("/tmp/src", "/tmp/out/launcher", 1000, 1000), " ")
+	assert.Equal(t, strings.HasPrefix(args, "run --rm --user 1000:1000 "), true, args)
+	assert.Equal(t, strings.Contains(args, " "+buildImage+" go build -o /out/launcher ."), true, args)
+
+	args = strings.Join(dockerBuildArgs("/tmp/src", "/tmp/out/launcher", -1, -1), " ")
+	assert.Equal(t, strings.Contains(args, "--user"), false, args)
+}
+
+func TestDownloadRemovesCreatedDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewGithubClient()
+	c.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader("Not Found")), Header: make(http.Header)}, nil
+	})}
+
+	commitDir, created, err := c.ensureCommitDir("abc", filepath.Join(dir, "relocated", "versions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, commitDir, filepath.Join(dir, "relocated", "versions", "abc"))
+	assert.Equal(t, created, filepath.Join(dir, "relocated"))
+	if err := os.RemoveAll(created); err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.DownloadLatestBinary("21.01.01", "abc", filepath.Join(dir, "relocated", "versions"))
+	assert.Equal(t, errors.Is(err, ErrNoArtifact), true)
+	_, err = os.Stat(filepath.Join(dir, "relocated"))
+	assert.Equal(t, os.IsNotExist(err), true)
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pelletier/go-toml"
+	"sort"
+	"strings"
+)
+
+var ErrUnknownConfigKey = errors.New("unknown config key")
+
+// unknownConfigKey is a key of the config file opendex-launcher does not
+// support, with the supported key it most likely was meant to be.
+type unknownConfigKey struct {
+	Key        string
+	Suggestion string
+}
+
+func (k unknownConfigKey) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUnknownConfigKey, k.String())
+}
+
+func (k unknownConfigKey) Unwrap() error {
+	return ErrUnknownConfigKey
+}
+
+func (k unknownConfigKey) String() string {
+	if k.Suggestion == "" {
+		return k.Key
+	}
+	return fmt.Sprintf("%s (did you mean %s?)", k.Key, k.Suggestion)
+}
+
+// levenshtein returns the edit distance of a and b.
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// nearestKey returns the key of keys closest to key, or "" when none is close
+// enough to be a typo.
+func nearestKey(key string, keys []string) string {
+	best, bestDistance := "", len(key)/3+2
+	for _, candidate := range keys {
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// unknownConfigKeys lists the keys and tables of tree which are not part of
+// Config.
+func unknownConfigKeys(tree *toml.Tree) []unknownConfigKey {
+	known := make(map[string]bool)
+	free := make(map[string]bool)
+	var names []string
+	for _, key := range ConfigKeys() {
+		name := strings.ToLower(key.Key)
+		known[name] = true
+		names = append(names, key.Key)
+		if strings.HasPrefix(key.Type, "table") {
+			free[name] = true
+		}
+		parts := strings.Split(key.Key, ".")
+		for i := 1; i < len(parts); i++ {
+			section := strings.Join(parts[:i], ".")
+			if !known[strings.ToLower(section)] {
+				known[strings.ToLower(section)] = true
+				names = append(names, section)
+			}
+		}
+	}
+
+	var unknown []unknownConfigKey
+	var walk func(tree *toml.Tree, prefix string)
+	walk = func(tree *toml.Tree, prefix string) {
+		keys := tree.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := prefix + key
+			if !known[strings.ToLower(name)] {
+				unknown = append(unknown, unknownConfigKey{Key: name, Suggestion: nearestKey(name, names)})
+				continue
+			}
+			if free[strings.ToLower(name)] {
+				continue
+			}
+			if sub, ok := tree.Get(key).(*toml.Tree); ok {
+				walk(sub, name+".")
+			}
+		}
+	}
+	walk(tree, "")
+	return unknown
+}
+
+// checkConfigKeys reports unknown keys of the config file data. Depending on
+// the strict-mode setting they are ignored, logged or rejected.
+func (t *Launcher) checkConfigKeys(data []byte, mode string) error {
+	if mode == "off" {
+		return nil
+	}
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return err
+	}
+	unknown := unknownConfigKeys(tree)
+	if len(unknown) == 0 {
+		return nil
+	}
+	var keys []string
+	for _, key := range unknown {
+		keys = append(keys, key.String())
+	}
+	switch mode {
+	case "error":
+		return fmt.Errorf("%w in %s: %s", ErrUnknownConfigKey, t.configFile, strings.Join(keys, ", "))
+	case "warn", "":
+		t.logger.Warnf("Ignoring unknown keys in %s: %s. Unknown keys will be rejected in a future release, set strict-mode = \"off\" to allow them", t.configFile, strings.Join(keys, ", "))
+		return nil
+	default:
+		return fmt.Errorf("unsupported strict-mode: %s (off, warn or error)", mode)
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"github.com/pelletier/go-toml"
+	"github.com/sirupsen/logrus"
+	"testing"
+)
+
+func TestUnknownConfigKeys(t *testing.T) {
+	tree, err := toml.Load(`
+config-version = 1
+simnet-dir = "/data"
+
+[GitHub]
+acess-token = "x"
+graphql = false
+
+[webhok]
+url = "https://example.com"
+
+[cache]
+max-size = 100
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, unknownConfigKeys(tree), []unknownConfigKey{
+		{Key: "GitHub.acess-token", Suggestion: "GitHub.access-token"},
+		{Key: "webhok", Suggestion: "webhook"},
+	})
+}
+
+func TestNearestKey(t *testing.T) {
+	keys := []string{"simnet-dir", "testnet-dir", "mainnet-dir"}
+
+	assert.Equal(t, nearestKey("testnt-dir", keys), "testnet-dir")
+	assert.Equal(t, nearestKey("something-else", keys), "")
+}
+
+func TestCheckConfigKeys(t *testing.T) {
+	l := &Launcher{configFile: "opendex-docker.conf", logger: logrus.NewEntry(logrus.New())}
+	data := []byte("sinmet-dir = \"/data\"\n")
+
+	assert.Equal(t, l.checkConfigKeys(data, "warn"), nil)
+	assert.Equal(t, l.checkConfigKeys(data, "off"), nil)
+	err := l.checkConfigKeys(data, "error")
+	assert.Equal(t, errors.Is(err, ErrUnknownConfigKey), true)
+	assert.Equal(t, err.Error(), "unknown config key in opendex-docker.conf: sinmet-dir (did you mean simnet-dir?)")
+}
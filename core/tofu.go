@@ -0,0 +1,136 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// KnownHashesFilename records the launcher hash of every commit ever
+// installed, per platform. It lives outside of the versions dir so that it
+// survives removing a version.
+const KnownHashesFilename = "known-hashes.json"
+
+var ErrArtifactChanged = errors.New("launcher artifact changed")
+
+func sha256File(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func readKnownHashes(file string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return hashes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", filepath.Base(file), err)
+	}
+	return hashes, nil
+}
+
+func writeKnownHashes(file string, hashes map[string]string) error {
+	data, err := json.MarshalIndent(hashes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// pinKey keys the hash of the launcher build of commit for arch. The builds of
+// one commit differ between platforms, and entries of older releases, keyed by
+// the commit alone, are never matched.
+func pinKey(commit string, arch string) string {
+	return fmt.Sprintf("%s %s/%s", commit, runtime.GOOS, arch)
+}
+
+// versionArch returns the architecture of the launcher build of the version
+// with metadata m.
+func (t *Launcher) versionArch(m *VersionMetadata) string {
+	if m.Arch != "" {
+		return m.Arch
+	}
+	if t.github != nil {
+		return t.github.archs()[0]
+	}
+	return runtime.GOARCH
+}
+
+// pinHash trusts the hash of launcher the first time commit is installed for
+// arch and fails with ErrArtifactChanged when a later install differs.
+func pinHash(file string, commit string, arch string, launcher string) (string, error) {
+	hash, err := sha256File(launcher)
+	if err != nil {
+		return "", fmt.Errorf("hash: %w", err)
+	}
+	hashes, err := readKnownHashes(file)
+	if err != nil {
+		return "", err
+	}
+	key := pinKey(commit, arch)
+	if known, ok := hashes[key]; ok {
+		if known != hash {
+			return hash, fmt.Errorf("%w: launcher of commit %s for %s/%s has sha256 %s, it had %s when first installed", ErrArtifactChanged, commit, runtime.GOOS, arch, hash, known)
+		}
+		return hash, nil
+	}
+	hashes[key] = hash
+	return hash, writeKnownHashes(file, hashes)
+}
+
+// verifyInstalled pins the hash of a freshly installed launcher and returns
+// it. A changed artifact is removed again so that it never runs. Launchers
+// built from source are not reproducible and are not pinned.
+func (t *Launcher) verifyInstalled(commit string, launcher string) (string, error) {
+	m, err := readMetadata(filepath.Dir(launcher))
+	if err != nil {
+		return "", err
+	}
+	if m.Built {
+		hash, err := sha256File(launcher)
+		if err != nil {
+			return "", fmt.Errorf("hash: %w", err)
+		}
+		m.Sha256 = hash
+		return hash, writeMetadata(filepath.Dir(launcher), m)
+	}
+	if m.Arch == "" {
+		m.Arch = t.github.selectedArch()
+	}
+
+	hash, err := pinHash(filepath.Join(t.launcherDir, KnownHashesFilename), commit, m.Arch, launcher)
+	if errors.Is(err, ErrArtifactChanged) {
+		t.logger.Errorf("%s", err)
+		if err := os.RemoveAll(filepath.Dir(launcher)); err != nil {
+			t.logger.Warnf("Failed to remove %s: %s", filepath.Dir(launcher), err)
+		} else {
+			t.audit(AuditRecord{Action: AuditPurged, Commit: commit, Detail: "artifact changed"})
+		}
+		t.emit(Event{Type: EventArtifactChanged, Commit: commit, Message: err.Error()})
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("pin hash: %w", err)
+	}
+	m.Sha256 = hash
+	return hash, writeMetadata(filepath.Dir(launcher), m)
+}
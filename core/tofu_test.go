@@ -0,0 +1,79 @@
+package core
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPinHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	known := filepath.Join(dir, KnownHashesFilename)
+	launcher := filepath.Join(dir, "launcher")
+	if err := ioutil.WriteFile(launcher, []byte("v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := pinHash(known, "abc", "amd64", launcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := pinHash(known, "abc", "amd64", launcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, first, second)
+
+	if err := ioutil.WriteFile(launcher, []byte("v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = pinHash(known, "abc", "amd64", launcher)
+	assert.Equal(t, errors.Is(err, ErrArtifactChanged), true)
+
+	_, err = pinHash(known, "abc", "arm64", launcher)
+	assert.Equal(t, err, nil, "the build of another arch is pinned separately")
+
+	_, err = pinHash(known, "def", "amd64", launcher)
+	assert.Equal(t, err, nil)
+}
+
+func TestBuiltLauncherNotPinned(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	versionDir := filepath.Join(dir, "versions", "abc")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	launcher := filepath.Join(versionDir, "launcher")
+	if err := ioutil.WriteFile(launcher, []byte("built"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMetadata(versionDir, &VersionMetadata{Commit: "abc", Built: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Launcher{launcherDir: dir, github: &GithubClient{}}
+	hash, err := l.verifyInstalled("abc", launcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := readMetadata(versionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, m.Sha256, hash)
+	_, err = os.Stat(filepath.Join(dir, KnownHashesFilename))
+	assert.Equal(t, os.IsNotExist(err), true, "a launcher built from source is not pinned")
+}
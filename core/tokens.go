@@ -0,0 +1,96 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TokenPool rotates through GitHub access tokens. A token which hit its
+// rate limit is skipped until the limit resets.
+type TokenPool struct {
+	mu      sync.Mutex
+	tokens  []string
+	resets  map[string]time.Time
+	current int
+	now     func() time.Time
+}
+
+func NewTokenPool(tokens ...string) *TokenPool {
+	p := &TokenPool{
+		resets: make(map[string]time.Time),
+		now:    time.Now,
+	}
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+		p.tokens = append(p.tokens, token)
+	}
+	return p
+}
+
+func (p *TokenPool) Len() int {
+	return len(p.tokens)
+}
+
+// Token returns the current token, or an empty string when the pool is
+// empty. When every token is rate limited the one resetting first is used.
+func (p *TokenPool) Token() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	now := p.now()
+	best := p.current
+	for i := 0; i < len(p.tokens); i++ {
+		j := (p.current + i) % len(p.tokens)
+		reset := p.resets[p.tokens[j]]
+		if !reset.After(now) {
+			p.current = j
+			return p.tokens[j]
+		}
+		if reset.Before(p.resets[p.tokens[best]]) {
+			best = j
+		}
+	}
+	p.current = best
+	return p.tokens[best]
+}
+
+// RateLimited marks token as limited until reset and reports whether
+// another token is available right now.
+func (p *TokenPool) RateLimited(token string, reset time.Time) bool {
+	p.mu.Lock()
+	p.resets[token] = reset
+	now := p.now()
+	available := false
+	for _, other := range p.tokens {
+		if other != token && !p.resets[other].After(now) {
+			available = true
+			break
+		}
+	}
+	p.mu.Unlock()
+	return available
+}
+
+// rateLimitReset returns when the rate limit of a rate limited response
+// resets.
+func rateLimitReset(resp *http.Response) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Now().Add(time.Hour), true
+	}
+	return time.Unix(seconds, 0), true
+}
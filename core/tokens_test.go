@@ -0,0 +1,61 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc answers requests without the network.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTokenPoolRotation(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	p := NewTokenPool("a", "", "b", "a", "c")
+	p.now = func() time.Time { return now }
+
+	assert.Equal(t, p.Len(), 3)
+	assert.Equal(t, p.Token(), "a")
+
+	assert.Equal(t, p.RateLimited("a", now.Add(time.Hour)), true)
+	assert.Equal(t, p.Token(), "b")
+
+	assert.Equal(t, p.RateLimited("b", now.Add(30*time.Minute)), true)
+	assert.Equal(t, p.Token(), "c")
+
+	assert.Equal(t, p.RateLimited("c", now.Add(2*time.Hour)), false)
+	assert.Equal(t, p.Token(), "b", "should use the token resetting first")
+
+	now = now.Add(61 * time.Minute)
+	assert.Equal(t, p.Token(), "b")
+}
+
+func TestEmptyTokenPool(t *testing.T) {
+	p := NewTokenPool()
+	assert.Equal(t, p.Token(), "")
+}
+
+func TestDoGetSendsToken(t *testing.T) {
+	auth := make(map[string]string)
+	client := NewGithubClient("secret")
+	client.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		auth[req.URL.Host] = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}")), Header: make(http.Header)}, nil
+	})}
+
+	if _, err := client.doGet("https://api.github.com/repos/opendexnetwork/opendex-docker/commits/master"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.doGet("https://mirror.example.com/repos/opendexnetwork/opendex-docker/commits/master"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, auth, map[string]string{"api.github.com": "token secret", "mirror.example.com": ""})
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Tracing struct {
+	OTLPEndpoint string `toml:"otlp-endpoint" comment:"OTLP/HTTP endpoint the spans of the startup are exported to, e.g. http://localhost:4318/v1/traces, defaults to $OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName  string `toml:"service-name" default:"opendex-launcher" comment:"service.name of the exported spans"`
+}
+
+// endpoint returns the configured traces endpoint, falling back to the
+// standard OpenTelemetry environment variables.
+func (t Tracing) endpoint() string {
+	if t.OTLPEndpoint != "" {
+		return t.OTLPEndpoint
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		return strings.TrimRight(endpoint, "/") + "/v1/traces"
+	}
+	return ""
+}
+
+// span is a timed step of the startup.
+type span struct {
+	id     [8]byte
+	parent [8]byte
+	name   string
+	start  time.Time
+	end    time.Time
+	attrs  map[string]string
+	err    error
+}
+
+// tracer records the startup of the launcher as one trace, with a span for
+// every phase below the root span, and exports it once the launcher was
+// started. A nil tracer records nothing.
+type tracer struct {
+	endpoint string
+	service  string
+	client   *http.Client
+	traceID  [16]byte
+	root     *span
+
+	mu    sync.Mutex
+	spans []*span
+	once  sync.Once
+}
+
+func newSpanID() [8]byte {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newTracer(endpoint string, service string) *tracer {
+	t := &tracer{
+		endpoint: endpoint,
+		service:  service,
+		client:   &http.Client{Timeout: 5 * time.Second, Transport: newUserAgentTransport(nil)},
+		root:     &span{id: newSpanID(), name: "startup", start: time.Now(), attrs: make(map[string]string)},
+	}
+	_, _ = rand.Read(t.traceID[:])
+	return t
+}
+
+// span starts the span name and returns the function ending it.
+func (t *tracer) span(name string) func(err error) {
+	if t == nil {
+		return func(error) {}
+	}
+	s := &span{id: newSpanID(), parent: t.root.id, name: name, start: time.Now()}
+	return func(err error) {
+		s.end = time.Now()
+		s.err = err
+		t.mu.Lock()
+		t.spans = append(t.spans, s)
+		t.mu.Unlock()
+	}
+}
+
+// finish ends the root span with attrs and exports the trace. Only the first
+// call has an effect.
+func (t *tracer) finish(err error, attrs map[string]string) {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		t.root.end = time.Now()
+		t.root.err = err
+		t.root.attrs = attrs
+		if err := t.export(); err != nil && Debug {
+			fmt.Printf("Failed to export trace: %s\n", err)
+		}
+	})
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceId           string          `json:"traceId"`
+	SpanId            string          `json:"spanId"`
+	ParentSpanId      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+func otlpAttributes(attrs map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var result []otlpAttribute
+	for _, key := range keys {
+		result = append(result, otlpAttribute{Key: key, Value: otlpValue{StringValue: attrs[key]}})
+	}
+	return result
+}
+
+// otlpPayload encodes spans as an OTLP/JSON ExportTraceServiceRequest.
+func otlpPayload(service string, traceID [16]byte, spans []*span) map[string]interface{} {
+	var encoded []otlpSpan
+	for _, s := range spans {
+		e := otlpSpan{
+			TraceId:           hex.EncodeToString(traceID[:]),
+			SpanId:            hex.EncodeToString(s.id[:]),
+			Name:              s.name,
+			Kind:              1, // internal
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attrs),
+		}
+		if s.parent != [8]byte{} {
+			e.ParentSpanId = hex.EncodeToString(s.parent[:])
+		}
+		if s.err != nil {
+			e.Status = otlpStatus{Code: 2, Message: s.err.Error()}
+		}
+		encoded = append(encoded, e)
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]string{"service.name": service}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "opendex-launcher"},
+						"spans": encoded,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (t *tracer) export() error {
+	t.mu.Lock()
+	spans := append([]*span{t.root}, t.spans...)
+	t.mu.Unlock()
+
+	data, err := json.Marshal(otlpPayload(t.service, t.traceID, spans))
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp: %s", resp.Status)
+	}
+	return nil
+}
+
+// setupTracing starts tracing the startup when an OTLP endpoint is
+// configured.
+func (t *Launcher) setupTracing() {
+	endpoint := t.config.Tracing.endpoint()
+	if endpoint == "" {
+		return
+	}
+	t.trace = newTracer(endpoint, t.config.Tracing.ServiceName)
+}
+
+// finishTrace exports the startup trace.
+func (t *Launcher) finishTrace(err error, commit string) {
+	t.trace.finish(err, map[string]string{
+		"opendex.network": t.network,
+		"opendex.branch":  t.branch,
+		"opendex.commit":  commit,
+	})
+}
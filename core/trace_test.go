@@ -0,0 +1,48 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestOTLPPayload(t *testing.T) {
+	start := time.Unix(1614600000, 0)
+	root := &span{id: [8]byte{1}, name: "startup", start: start, end: start.Add(time.Second), attrs: map[string]string{"opendex.network": "mainnet"}}
+	child := &span{id: [8]byte{2}, parent: root.id, name: "download", start: start, end: start.Add(time.Millisecond), err: errors.New("timeout")}
+
+	data, err := json.Marshal(otlpPayload("opendex-launcher", [16]byte{0xab}, []*span{root, child}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []otlpAttribute `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []otlpSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, payload.ResourceSpans[0].Resource.Attributes, []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: "opendex-launcher"}}})
+	spans := payload.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Equal(t, len(spans), 2)
+	assert.Equal(t, spans[0].TraceId, "ab000000000000000000000000000000")
+	assert.Equal(t, spans[0].ParentSpanId, "")
+	assert.Equal(t, spans[0].StartTimeUnixNano, "1614600000000000000")
+	assert.Equal(t, spans[1].ParentSpanId, "0100000000000000")
+	assert.Equal(t, spans[1].Status, otlpStatus{Code: 2, Message: "timeout"})
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *tracer
+	tracer.span("resolve")(nil)
+	tracer.finish(nil, nil)
+}
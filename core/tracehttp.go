@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// traceBodyLimit is the number of bytes of a response body --trace-http logs.
+const traceBodyLimit = 1024
+
+const redacted = "REDACTED"
+
+// secretQueryParams carry credentials in the URLs of signed redirects to
+// artifact storage.
+var secretQueryParams = []string{"sig", "token", "access_token", "x-amz-signature", "x-amz-credential", "x-amz-security-token"}
+
+// traceTransport logs every request with --trace-http: method, URL, status,
+// timing, the rate limit headers of GitHub and the start of textual bodies.
+// Credentials in the Authorization header and signed URLs are never logged.
+type traceTransport struct {
+	base   http.RoundTripper
+	logger *logrus.Entry
+	now    func() time.Time
+}
+
+func newTraceTransport(base http.RoundTripper, logger *logrus.Entry) *traceTransport {
+	return &traceTransport{base: base, logger: logger, now: time.Now}
+}
+
+// redactURL replaces the userinfo and the values of secret query parameters
+// of u.
+func redactURL(u *url.URL) string {
+	c := *u
+	if c.User != nil {
+		c.User = url.User(redacted)
+	}
+	query := c.Query()
+	changed := false
+	for key := range query {
+		for _, secret := range secretQueryParams {
+			if strings.EqualFold(key, secret) {
+				query.Set(key, redacted)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		c.RawQuery = query.Encode()
+	}
+	return c.String()
+}
+
+// rateLimit summarizes the rate limit headers of resp.
+func rateLimit(resp *http.Response) string {
+	var parts []string
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "" {
+		parts = append(parts, fmt.Sprintf("rate limit %s/%s", remaining, resp.Header.Get("X-RateLimit-Limit")))
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		parts = append(parts, "resets at "+time.Unix(reset, 0).UTC().Format(time.RFC3339))
+	}
+	if retry := resp.Header.Get("Retry-After"); retry != "" {
+		parts = append(parts, "retry after "+retry)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func isTextual(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml")
+}
+
+// peekBody returns the start of the body of resp and leaves the body intact
+// for the caller.
+func peekBody(resp *http.Response) string {
+	buf := make([]byte, traceBodyLimit+1)
+	n, err := io.ReadFull(resp.Body, buf)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf[:n]), resp.Body), resp.Body}
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	if n > traceBodyLimit {
+		return string(buf[:traceBodyLimit]) + "... (truncated)"
+	}
+	return string(buf[:n])
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := redactURL(req.URL)
+	auth := ""
+	if req.Header.Get("Authorization") != "" {
+		auth = " (Authorization: " + redacted + ")"
+	}
+	t.logger.Infof("> %s %s%s", req.Method, target, auth)
+
+	start := t.now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := t.now().Sub(start).Round(time.Millisecond)
+	if err != nil {
+		t.logger.Infof("< %s %s failed after %s: %s", req.Method, target, elapsed, err)
+		return nil, err
+	}
+
+	line := fmt.Sprintf("< %s %s %s in %s", resp.Status, req.Method, target, elapsed)
+	if limit := rateLimit(resp); limit != "" {
+		line += ", " + limit
+	}
+	t.logger.Info(line)
+	if isTextual(resp.Header.Get("Content-Type")) {
+		if body := peekBody(resp); body != "" {
+			t.logger.Infof("< %s", body)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TransfersFilename keeps the summaries of the latest launcher downloads,
+// next to the known hashes in the launcher dir.
+const TransfersFilename = "downloads.json"
+
+// maxTransfers is how many download summaries are kept.
+const maxTransfers = 5
+
+// Transfer summarizes a completed download of a launcher archive.
+type Transfer struct {
+	Commit string `json:"commit"`
+	Url    string `json:"url"`
+	// Mirror is the host the archive was finally downloaded from, after
+	// following redirects.
+	Mirror         string    `json:"mirror"`
+	Bytes          int64     `json:"bytes"`
+	Seconds        float64   `json:"seconds"`
+	BytesPerSecond float64   `json:"bytes_per_second"`
+	Retries        int       `json:"retries"`
+	Sha256         string    `json:"sha256"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
+
+func newTransfer(mirror string, n int64, hash string, elapsed time.Duration, finished time.Time) Transfer {
+	s := Transfer{Mirror: mirror, Bytes: n, Sha256: hash, Seconds: elapsed.Seconds(), FinishedAt: finished}
+	if s.Seconds > 0 {
+		s.BytesPerSecond = float64(n) / s.Seconds
+	}
+	return s
+}
+
+func (s Transfer) String() string {
+	retries := "no retries"
+	switch {
+	case s.Retries == 1:
+		retries = "1 retry"
+	case s.Retries > 1:
+		retries = fmt.Sprintf("%d retries", s.Retries)
+	}
+	return fmt.Sprintf("%.1f MB in %.1fs (%.1f MB/s) from %s, %s", float64(s.Bytes)/1024/1024, s.Seconds, s.BytesPerSecond/1024/1024, s.Mirror, retries)
+}
+
+func readTransfers(file string) ([]Transfer, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var transfers []Transfer
+	if err := json.Unmarshal(data, &transfers); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", filepath.Base(file), err)
+	}
+	return transfers, nil
+}
+
+// appendTransfer adds s to the summaries in file, dropping the oldest ones
+// beyond maxTransfers.
+func appendTransfer(file string, s Transfer) error {
+	transfers, err := readTransfers(file)
+	if err != nil {
+		return err
+	}
+	transfers = append(transfers, s)
+	if len(transfers) > maxTransfers {
+		transfers = transfers[len(transfers)-maxTransfers:]
+	}
+	data, err := json.MarshalIndent(transfers, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// transferred logs the summary of a completed launcher download and keeps it
+// for the status.
+func (t *Launcher) transferred(s Transfer) {
+	t.logger.Infof("Downloaded launcher of %s: %s", shortCommit(s.Commit), s)
+	t.audit(AuditRecord{Action: AuditDownloaded, Commit: s.Commit, Url: s.Url, Sha256: s.Sha256, Detail: fmt.Sprintf("%d bytes from %s", s.Bytes, s.Mirror)})
+	if err := appendTransfer(filepath.Join(t.launcherDir, TransfersFilename), s); err != nil {
+		t.logger.Warnf("Failed to record the download: %s", err)
+	}
+}
+
+// recentTransfers returns the latest download summaries, oldest first.
+func (t *Launcher) recentTransfers() []Transfer {
+	if t.launcherDir == "" {
+		return nil
+	}
+	transfers, err := readTransfers(filepath.Join(t.launcherDir, TransfersFilename))
+	if err != nil {
+		t.logger.Debugf("Read download summaries: %s", err)
+	}
+	return transfers
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTransferString(t *testing.T) {
+	s := newTransfer("objects.githubusercontent.com", 3*1024*1024, "", 2*time.Second, time.Now())
+	assert.Equal(t, s.BytesPerSecond, float64(1.5*1024*1024))
+	assert.Equal(t, s.String(), "3.0 MB in 2.0s (1.5 MB/s) from objects.githubusercontent.com, no retries")
+
+	s.Retries = 2
+	assert.Equal(t, s.String(), "3.0 MB in 2.0s (1.5 MB/s) from objects.githubusercontent.com, 2 retries")
+}
+
+func TestAppendTransfer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transfers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, TransfersFilename)
+
+	for i := 0; i < maxTransfers+2; i++ {
+		if err := appendTransfer(file, Transfer{Bytes: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transfers, err := readTransfers(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(transfers), maxTransfers)
+	assert.Equal(t, transfers[0].Bytes, int64(2))
+	assert.Equal(t, transfers[maxTransfers-1].Bytes, int64(maxTransfers+1))
+}
+
+func TestDownloadTransferred(t *testing.T) {
+	zip := launcherZip(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Write([]byte("corrupt"))
+			return
+		}
+		w.Write(zip)
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "transferred")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var transfers []Transfer
+	c := NewGithubClient()
+	c.Attempts = 2
+	c.Transferred = func(s Transfer) {
+		transfers = append(transfers, s)
+	}
+	if err := c.downloadRetrying(server.URL+"/launcher.zip", "master", "abc", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(server.URL)
+	assert.Equal(t, len(transfers), 1)
+	assert.Equal(t, transfers[0].Commit, "abc")
+	assert.Equal(t, transfers[0].Mirror, u.Host)
+	assert.Equal(t, transfers[0].Bytes, int64(len(zip)))
+	assert.Equal(t, transfers[0].Retries, 1)
+}
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/utils"
+)
+
+// ExitUpdateAvailable is the exit code of "update --check" when a new
+// launcher build is available.
+const ExitUpdateAvailable = 10
+
+// runUpdateCheck exits with 0 when the head of the branch is installed and
+// with ExitUpdateAvailable when it is not, without downloading anything.
+func (t *Launcher) runUpdateCheck(args []string) error {
+	commit, err := t.resolve()
+	if err != nil {
+		return err
+	}
+	installed, err := utils.FileExists(t.launcherPath(commit))
+	if err != nil {
+		return err
+	}
+	if installed {
+		fmt.Printf("Up to date: %s\n", describeRef(t.branch, commit))
+		return nil
+	}
+	fmt.Printf("Update available: %s\n", describeRef(t.branch, commit))
+	return &ExitCodeError{Code: ExitUpdateAvailable}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// User makes the launcher run as a dedicated system user when
+// opendex-launcher itself is started by root, e.g. from a service manager.
+type User struct {
+	Name string `toml:"name" comment:"System user the launcher runs as when opendex-launcher runs as root, see \"user setup\""`
+}
+
+// lookupUser returns the uid, gid and supplementary groups of name.
+func lookupUser(name string) (uint32, uint32, []uint32, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("uid %s: %w", u.Uid, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("gid %s: %w", u.Gid, err)
+	}
+	ids, err := u.GroupIds()
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("groups: %w", err)
+	}
+	var groups []uint32
+	for _, id := range ids {
+		g, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			continue
+		}
+		groups = append(groups, uint32(g))
+	}
+	return uint32(uid), uint32(gid), groups, nil
+}
+
+// userEnv returns env with the variables naming the user, which root set,
+// pointing to the user name and its home directory home instead.
+func userEnv(env []string, name string, home string) []string {
+	var result []string
+	for _, kv := range env {
+		switch strings.SplitN(kv, "=", 2)[0] {
+		case "HOME", "USER", "LOGNAME":
+			continue
+		}
+		result = append(result, kv)
+	}
+	return append(result, "HOME="+home, "USER="+name, "LOGNAME="+name)
+}
+
+// userHomeDir returns the home directory of the user name. A user without
+// an existing home directory, e.g. a system user created without one, gets
+// the opendex-docker home directory it owns after user setup.
+func (t *Launcher) userHomeDir(name string) string {
+	u, err := user.Lookup(name)
+	if err == nil && u.HomeDir != "" {
+		if _, err := os.Stat(u.HomeDir); err == nil {
+			return u.HomeDir
+		}
+	}
+	return t.homeDir
+}
+
+// chownTree changes the owner of dir and everything below it.
+func chownTree(dir string, uid int, gid int) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+// runUserSetup creates the system user (by default "opendex") with a home
+// directory in /var/lib, adds it to the docker group and hands the
+// opendex-docker home directory over to it.
+func (t *Launcher) runUserSetup(args []string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("user setup is only supported on Linux")
+	}
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("user setup has to run as root")
+	}
+	name := "opendex"
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	if _, err := user.Lookup(name); err != nil {
+		fmt.Printf("Creating system user %s\n", name)
+		cmd := exec.Command("useradd", "--system", "--user-group", "--create-home", "--home-dir", filepath.Join("/var/lib", name), "--shell", "/usr/sbin/nologin", name)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("useradd: %w: %s", err, output)
+		}
+	}
+	if _, err := user.LookupGroup("docker"); err == nil {
+		if output, err := exec.Command("usermod", "--append", "--groups", "docker", name).CombinedOutput(); err != nil {
+			return fmt.Errorf("usermod: %w: %s", err, output)
+		}
+	} else {
+		t.logger.Warnf("There is no docker group, %s may not be able to use docker", name)
+	}
+
+	uid, gid, _, err := lookupUser(name)
+	if err != nil {
+		return err
+	}
+	if err := chownTree(t.homeDir, int(uid), int(gid)); err != nil {
+		return fmt.Errorf("chown %s: %w", t.homeDir, err)
+	}
+	fmt.Printf("%s now owns %s\n", name, t.homeDir)
+	fmt.Printf("Set name = %q in the [user] section of %s to run the launcher as %s\n", name, t.configFile, name)
+	return nil
+}
@@ -0,0 +1,11 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestUserEnv(t *testing.T) {
+	env := userEnv([]string{"HOME=/root", "PATH=/usr/bin", "USER=root", "LOGNAME=root", "NETWORK_DIR=/root/.opendex-docker/mainnet"}, "opendex", "/var/lib/opendex")
+	assert.Equal(t, env, []string{"PATH=/usr/bin", "NETWORK_DIR=/root/.opendex-docker/mainnet", "HOME=/var/lib/opendex", "USER=opendex", "LOGNAME=opendex"})
+}
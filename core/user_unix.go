@@ -0,0 +1,43 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// runAsUser drops the privileges of cmd to the configured user, with the
+// HOME of the user, and hands the network directory over to it. Without
+// root privileges the launcher keeps running as the current user.
+func (t *Launcher) runAsUser(cmd *exec.Cmd) error {
+	name := t.config.User.Name
+	if os.Geteuid() != 0 {
+		t.logger.Debugf("Not running as root, the launcher runs as the current user instead of %s", name)
+		return nil
+	}
+	uid, gid, groups, err := lookupUser(name)
+	if err != nil {
+		return fmt.Errorf("look up user %s: %w", name, err)
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uid, Gid: gid, Groups: groups}
+	cmd.Env = userEnv(cmd.Env, name, t.userHomeDir(name))
+	if t.networkDir == "" {
+		return nil
+	}
+	// network directories created by root after user setup
+	if info, err := os.Stat(t.networkDir); err == nil {
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Uid != uid {
+			if err := chownTree(t.networkDir, int(uid), int(gid)); err != nil {
+				return fmt.Errorf("chown %s: %w", t.networkDir, err)
+			}
+		}
+	}
+	return nil
+}
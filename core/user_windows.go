@@ -0,0 +1,10 @@
+package core
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func (t *Launcher) runAsUser(cmd *exec.Cmd) error {
+	return errors.New("running the launcher as another user is not supported on Windows")
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/build"
+	"net/http"
+	"runtime"
+)
+
+// userAgent identifies opendex-launcher to GitHub, as its API requires, and
+// to mirror operators.
+func userAgent() string {
+	version := build.Version
+	if version == "" {
+		version = "dev"
+	}
+	return fmt.Sprintf("opendex-launcher/%s (%s/%s)", version, runtime.GOOS, runtime.GOARCH)
+}
+
+// userAgentTransport sets the User-Agent of requests which have none.
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func newUserAgentTransport(base http.RoundTripper) *userAgentTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &userAgentTransport{base: base}
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.base.RoundTrip(req)
+	}
+	// a RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent())
+	return t.base.RoundTrip(req)
+}
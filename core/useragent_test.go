@@ -0,0 +1,32 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestUserAgentTransport(t *testing.T) {
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.UserAgent())
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: newUserAgentTransport(nil)}
+
+	if _, err := client.Get(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "custom")
+	if _, err := client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, agents, []string{"opendex-launcher/dev (" + runtime.GOOS + "/" + runtime.GOARCH + ")", "custom"})
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+const (
+	VerifyOK           = "ok"
+	VerifyCorrupted    = "corrupted"
+	VerifyMissing      = "missing"
+	VerifyUnverifiable = "unverifiable"
+)
+
+type versionCheck struct {
+	Commit string
+	Branch string
+	Status string
+	// Expected is the recorded hash of the launcher, empty when none was
+	// recorded.
+	Expected string
+	Detail   string
+}
+
+// checkLauncher hashes launcher and compares it with the expected hash. The
+// hash pinned in the known hashes wins over the one in the metadata, which
+// lives next to the binary and might be damaged along with it.
+func checkLauncher(launcher string, pinned string, recorded string) versionCheck {
+	expected := pinned
+	if expected == "" {
+		expected = recorded
+	}
+	check := versionCheck{Expected: expected}
+
+	if _, err := os.Stat(launcher); os.IsNotExist(err) {
+		check.Status = VerifyMissing
+		check.Detail = "launcher binary not found"
+		return check
+	}
+	hash, err := sha256File(launcher)
+	if err != nil {
+		check.Status = VerifyCorrupted
+		check.Detail = fmt.Sprintf("hash: %s", err)
+		return check
+	}
+	switch {
+	case expected == "":
+		check.Status = VerifyUnverifiable
+		check.Detail = "no hash recorded"
+	case hash != expected:
+		check.Status = VerifyCorrupted
+		check.Detail = fmt.Sprintf("sha256 %s, expected %s", hash, expected)
+	default:
+		check.Status = VerifyOK
+	}
+	return check
+}
+
+// verifyVersions re-hashes the launcher of every installed version.
+func (t *Launcher) verifyVersions() ([]versionCheck, error) {
+	versions, err := t.installedVersions()
+	if err != nil {
+		return nil, err
+	}
+	known, err := readKnownHashes(filepath.Join(t.launcherDir, KnownHashesFilename))
+	if err != nil {
+		return nil, err
+	}
+	var checks []versionCheck
+	for _, v := range versions {
+		m, err := readMetadata(filepath.Dir(v.Path))
+		if err != nil {
+			checks = append(checks, versionCheck{Commit: v.Commit, Status: VerifyCorrupted, Expected: known[pinKey(v.Commit, t.versionArch(&VersionMetadata{}))], Detail: err.Error()})
+			continue
+		}
+		check := checkLauncher(v.Path, known[pinKey(v.Commit, t.versionArch(m))], m.Sha256)
+		check.Commit = v.Commit
+		check.Branch = m.Branch
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// repairVersion installs the launcher of a damaged version again. The stored
+// copy of its binary is dropped first, as it is a hardlink to the damaged
+// file and dedupe would link the new download back to it.
+func (t *Launcher) repairVersion(check versionCheck) error {
+	if err := os.RemoveAll(filepath.Dir(t.launcherPath(check.Commit))); err != nil {
+		return err
+	}
+	t.audit(AuditRecord{Action: AuditPurged, Commit: check.Commit, Sha256: check.Expected, Detail: "damaged, repairing"})
+	if check.Expected != "" {
+		if err := t.github.Cache.dropBinary(check.Expected); err != nil {
+			return err
+		}
+	}
+	if check.Branch != "" {
+		branch := t.branch
+		t.branch = check.Branch
+		defer func() { t.branch = branch }()
+	}
+	_, err := t.ensureLauncher(check.Commit)
+	return err
+}
+
+func (t *Launcher) runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	repair := fs.Bool("repair", false, "download corrupted and missing versions again")
+	remove := fs.Bool("remove", false, "remove corrupted and missing versions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *repair && *remove {
+		return errors.New("usage: verify [--repair | --remove]")
+	}
+
+	checks, err := t.verifyVersions()
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMIT\tSTATUS\tDETAIL")
+	for _, check := range checks {
+		if check.Status == VerifyCorrupted || check.Status == VerifyMissing {
+			switch {
+			case *repair:
+				if err := t.repairVersion(check); err != nil {
+					check.Detail = fmt.Sprintf("%s, repair failed: %s", check.Detail, err)
+				} else {
+					check.Status = "repaired"
+				}
+			case *remove:
+				if err := t.removeVersion(check.Commit, "damaged, "+check.Status); err != nil {
+					check.Detail = fmt.Sprintf("%s, remove failed: %s", check.Detail, err)
+				} else {
+					check.Status = "removed"
+				}
+			}
+		}
+		if check.Status == VerifyCorrupted || check.Status == VerifyMissing {
+			failed = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", shortCommit(check.Commit), check.Status, check.Detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed {
+		if !*repair && !*remove {
+			fmt.Println("Run verify --repair to download damaged versions again, or verify --remove to remove them.")
+		}
+		return &ExitCodeError{Code: 1}
+	}
+	return nil
+}
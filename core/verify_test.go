@@ -0,0 +1,36 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLauncher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	launcher := filepath.Join(dir, "launcher")
+	if err := ioutil.WriteFile(launcher, []byte("launcher"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := sha256File(launcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, checkLauncher(launcher, hash, "").Status, VerifyOK)
+	assert.Equal(t, checkLauncher(launcher, "", hash).Status, VerifyOK)
+	assert.Equal(t, checkLauncher(launcher, "", "").Status, VerifyUnverifiable)
+	assert.Equal(t, checkLauncher(filepath.Join(dir, "missing"), hash, "").Status, VerifyMissing)
+
+	// The pinned hash wins over the metadata next to the binary.
+	check := checkLauncher(launcher, "0000", hash)
+	assert.Equal(t, check.Status, VerifyCorrupted)
+	assert.Equal(t, check.Expected, "0000")
+}
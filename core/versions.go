@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InstalledVersion is a launcher version downloaded into the versions dir.
+type InstalledVersion struct {
+	Commit      string    `json:"commit"`
+	Path        string    `json:"path"`
+	InstalledAt time.Time `json:"installed_at"`
+	// Version is the version of the launcher from its manifest.
+	Version string `json:"version,omitempty"`
+	// BuildTime is the build time of the launcher from its manifest.
+	BuildTime string `json:"build_time,omitempty"`
+}
+
+// launcherPath returns the path of the launcher binary of commit.
+func (t *Launcher) launcherPath(commit string) string {
+	return filepath.Join(t.launcherVersionsDir, commit, launcherBinary())
+}
+
+// installedVersions lists the installed versions, newest first.
+func (t *Launcher) installedVersions() ([]InstalledVersion, error) {
+	entries, err := ioutil.ReadDir(t.launcherVersionsDir)
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+	var versions []InstalledVersion
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		v := InstalledVersion{
+			Commit:      entry.Name(),
+			Path:        t.launcherPath(entry.Name()),
+			InstalledAt: entry.ModTime(),
+		}
+		if m, err := readMetadata(filepath.Join(t.launcherVersionsDir, entry.Name())); err == nil && m.Manifest != nil {
+			v.Version = m.Manifest.Version
+			v.BuildTime = m.Manifest.BuildTime
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].InstalledAt.After(versions[j].InstalledAt)
+	})
+	return versions, nil
+}
+
+// findVersion returns the installed version whose commit starts with prefix.
+func (t *Launcher) findVersion(prefix string) (InstalledVersion, error) {
+	versions, err := t.installedVersions()
+	if err != nil {
+		return InstalledVersion{}, err
+	}
+	var found []InstalledVersion
+	for _, v := range versions {
+		if strings.HasPrefix(v.Commit, prefix) {
+			found = append(found, v)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return InstalledVersion{}, fmt.Errorf("version %s is not installed", prefix)
+	case 1:
+		return found[0], nil
+	default:
+		return InstalledVersion{}, fmt.Errorf("version %s is ambiguous, it matches %d installed versions", prefix, len(found))
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+// defaultWatchInterval is how often --watch polls the branch.
+const defaultWatchInterval = 2 * time.Minute
+
+// watchOnce updates the running launcher to the head of the branch and logs
+// the transition. Heads without a launcher build yet are tried again at the
+// next poll, and so is the whole poll while another update runs.
+func (t *Launcher) watchOnce() {
+	previous := t.status().Commit
+	commit, updated, err := t.update()
+	switch {
+	case errors.Is(err, ErrUpdateRunning):
+		t.logger.Debugf("Watch: skipping the poll of %s, an update is running", t.branch)
+	case errors.Is(err, ErrNoArtifact):
+		t.logger.Debugf("Watch: the head of %s has no launcher build yet", t.branch)
+	case err != nil:
+		t.logger.Warnf("Watch: failed to update %s: %s", t.branch, err)
+	case updated:
+		t.logger.Infof("Watch: restarted the launcher on the new build of %s, %s -> %s", t.branch, shortCommit(previous), shortCommit(commit))
+	}
+}
+
+// watch polls the branch with --watch until opendex-launcher stops, and
+// restarts the launcher gracefully on every new build.
+func (t *Launcher) watch() {
+	interval := t.args.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	t.logger.Infof("Watching %s for new builds every %s", t.branch, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.watchOnce()
+		case <-t.baseContext().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"bytes"
+	"github.com/magiconair/properties/assert"
+	"github.com/sirupsen/logrus"
+	"testing"
+)
+
+func TestWatchOnceSkipsRunningUpdate(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetLevel(logrus.DebugLevel)
+	l := &Launcher{branch: "master", logger: logrus.NewEntry(logger), updating: 1}
+
+	l.watchOnce()
+
+	assert.Equal(t, bytes.Contains(out.Bytes(), []byte("skipping the poll of master")), true)
+	assert.Equal(t, l.updating, int32(1))
+}
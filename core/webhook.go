@@ -0,0 +1,102 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+type Webhook struct {
+	Url    string   `toml:"url" secret:"true" comment:"HTTP webhook notified about launcher events"`
+	Format string   `toml:"format" default:"slack" comment:"Webhook payload format: slack, mattermost or discord"`
+	Events []string `toml:"events" comment:"Events sent to the webhook (installed, download-failed, child-crashed), all but progress and lifecycle events when empty"`
+}
+
+// webhookQueueSize is the number of events waiting to be sent before new ones
+// are dropped
+const webhookQueueSize = 32
+
+type webhookNotifier struct {
+	config Webhook
+	client *http.Client
+	logger *logrus.Entry
+	queue  chan Event
+}
+
+func newWebhookNotifier(config Webhook) *webhookNotifier {
+	t := &webhookNotifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second, Transport: newUserAgentTransport(nil)},
+		logger: logrus.NewEntry(logrus.StandardLogger()).WithField("name", "webhook"),
+		queue:  make(chan Event, webhookQueueSize),
+	}
+	go t.loop()
+	return t
+}
+
+func (t *webhookNotifier) wants(e Event) bool {
+	if len(t.config.Events) == 0 {
+		return e.Type.notable()
+	}
+	for _, name := range t.config.Events {
+		if EventType(name) == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *webhookNotifier) payload(e Event) (interface{}, error) {
+	switch t.config.Format {
+	case "slack", "mattermost", "":
+		return map[string]string{"text": e.String()}, nil
+	case "discord":
+		return map[string]string{"content": e.String()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported webhook format: %s", t.config.Format)
+	}
+}
+
+func (t *webhookNotifier) send(e Event) error {
+	payload, err := t.payload(e)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	resp, err := t.client.Post(t.config.Url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (t *webhookNotifier) loop() {
+	for e := range t.queue {
+		if err := t.send(e); err != nil {
+			t.logger.Warnf("Failed to notify %s: %s", e.Type, err)
+		}
+	}
+}
+
+// handle runs under emitMu, so it only queues the event and a slow webhook
+// never holds up the launcher
+func (t *webhookNotifier) handle(e Event) {
+	if !t.wants(e) {
+		return
+	}
+	select {
+	case t.queue <- e:
+	default:
+		t.logger.Warnf("Dropped %s notification: the webhook is not keeping up", e.Type)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookDoesNotBlock(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var sent int32
+	n := newWebhookNotifier(Webhook{Url: "https://hooks.example.com/"})
+	n.client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		atomic.AddInt32(&sent, 1)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+	})}
+
+	n.handle(Event{Type: EventChildCrashed})
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < webhookQueueSize+10; i++ {
+			n.handle(Event{Type: EventChildCrashed})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handle blocked on a stuck webhook")
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&sent) < webhookQueueSize+1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, atomic.LoadInt32(&sent), int32(webhookQueueSize+1), "the queued events are sent and the rest dropped")
+}
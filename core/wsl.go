@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// windowsDrive matches paths on a Windows drive mounted into WSL.
+var windowsDrive = regexp.MustCompile(`^/mnt/[a-zA-Z](/|$)`)
+
+// wslEnvironment describes the WSL distribution opendex-launcher runs in.
+type wslEnvironment struct {
+	Version int
+	Distro  string
+}
+
+// detectWSL tells whether the Linux kernel release, as in
+// /proc/sys/kernel/osrelease, is the one of WSL and which WSL version it
+// belongs to.
+func detectWSL(release string, distro string) (wslEnvironment, bool) {
+	release = strings.ToLower(release)
+	if !strings.Contains(release, "microsoft") {
+		return wslEnvironment{}, false
+	}
+	env := wslEnvironment{Version: 1, Distro: distro}
+	if strings.Contains(release, "wsl2") || strings.Contains(release, "microsoft-standard") {
+		env.Version = 2
+	}
+	return env, true
+}
+
+func onWindowsDrive(path string) bool {
+	return windowsDrive.MatchString(path)
+}
+
+// wslWarnings returns the known pitfalls of running in env: WSL 1, which
+// Docker Desktop does not support, data on the Windows drives, which is slow
+// and loses Linux permissions, and Docker Desktop without the integration
+// of the distribution.
+func (t *Launcher) wslWarnings(env wslEnvironment, dockerInstalled bool, dockerExeInstalled bool) []string {
+	var warnings []string
+	if env.Version == 1 {
+		warnings = append(warnings, fmt.Sprintf("WSL 1 is not supported by Docker Desktop, convert the distribution with: wsl --set-version %s 2", env.Distro))
+	}
+	if onWindowsDrive(t.homeDir) {
+		warnings = append(warnings, fmt.Sprintf("The home directory %s is on a Windows drive, which is slow and breaks file permissions in WSL; move it to .opendex-docker in the Linux home directory of the user, which is used once it no longer exists", t.homeDir))
+	} else if onWindowsDrive(t.networkDir) {
+		warnings = append(warnings, fmt.Sprintf("The data of %s in %s is on a Windows drive, which is slow and breaks file permissions in WSL; move it into the Linux file system with %s-dir", t.network, t.networkDir, t.network))
+	}
+	if !dockerInstalled && dockerExeInstalled {
+		warnings = append(warnings, fmt.Sprintf("Docker Desktop is installed on Windows but not integrated with %s; enable it in Settings > Resources > WSL integration", env.Distro))
+	}
+	return warnings
+}
+
+// currentWSL returns the WSL environment opendex-launcher runs in, if any.
+func currentWSL() (wslEnvironment, bool) {
+	release, err := ioutil.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return wslEnvironment{}, false
+	}
+	return detectWSL(string(release), os.Getenv("WSL_DISTRO_NAME"))
+}
+
+// linuxHomeDir returns the home directory of the user in the Linux file
+// system when home, usually $HOME, points to a Windows drive in WSL.
+func linuxHomeDir(home string) string {
+	if !onWindowsDrive(home) {
+		return home
+	}
+	if _, ok := currentWSL(); !ok {
+		return home
+	}
+	u, err := user.Current()
+	if err != nil {
+		return home
+	}
+	return wslHomeDir(home, u.HomeDir)
+}
+
+// wslHomeDir returns linuxHome instead of home when home is on a Windows
+// drive. An existing opendex-docker home directory in home is kept, it is
+// only moved by the user, checkWSL warns about it.
+func wslHomeDir(home string, linuxHome string) string {
+	if !onWindowsDrive(home) || linuxHome == "" || onWindowsDrive(linuxHome) {
+		return home
+	}
+	if _, err := os.Stat(filepath.Join(home, ".opendex-docker")); err == nil {
+		return home
+	}
+	return linuxHome
+}
+
+// checkWSL warns about the pitfalls of WSL when running in it.
+func (t *Launcher) checkWSL() {
+	env, ok := currentWSL()
+	if !ok {
+		return
+	}
+	t.logger.Debugf("Running in WSL %d (%s)", env.Version, env.Distro)
+	_, err := exec.LookPath("docker")
+	dockerInstalled := err == nil
+	_, err = exec.LookPath("docker.exe")
+	dockerExeInstalled := err == nil
+	for _, warning := range t.wslWarnings(env, dockerInstalled, dockerExeInstalled) {
+		t.logger.Warnf("%s", warning)
+	}
+}
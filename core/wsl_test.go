@@ -0,0 +1,37 @@
+package core
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestDetectWSL(t *testing.T) {
+	env, ok := detectWSL("5.15.90.1-microsoft-standard-WSL2\n", "Ubuntu")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, env, wslEnvironment{Version: 2, Distro: "Ubuntu"})
+	env, ok = detectWSL("4.4.0-19041-Microsoft\n", "Ubuntu")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, env.Version, 1)
+	_, ok = detectWSL("6.1.0-18-amd64\n", "")
+	assert.Equal(t, ok, false)
+}
+
+func TestWSLWarnings(t *testing.T) {
+	assert.Equal(t, onWindowsDrive("/mnt/c/Users/alice"), true)
+	assert.Equal(t, onWindowsDrive("/mnt/data/opendex"), false)
+
+	l := &Launcher{homeDir: "/home/alice/.opendex-docker", network: "mainnet", networkDir: "/home/alice/.opendex-docker/mainnet"}
+	env := wslEnvironment{Version: 2, Distro: "Ubuntu"}
+	assert.Equal(t, len(l.wslWarnings(env, true, true)), 0)
+
+	l.networkDir = "/mnt/d/mainnet"
+	assert.Equal(t, len(l.wslWarnings(env, true, false)), 1)
+	assert.Equal(t, len(l.wslWarnings(wslEnvironment{Version: 1, Distro: "Ubuntu"}, false, true)), 3)
+}
+
+func TestWSLHomeDir(t *testing.T) {
+	assert.Equal(t, wslHomeDir("/home/alice", "/home/alice"), "/home/alice")
+	assert.Equal(t, wslHomeDir("/mnt/c/Users/alice", "/home/alice"), "/home/alice")
+	assert.Equal(t, wslHomeDir("/mnt/c/Users/alice", "/mnt/c/Users/alice"), "/mnt/c/Users/alice")
+	assert.Equal(t, wslHomeDir("/mnt/c/Users/alice", ""), "/mnt/c/Users/alice")
+}
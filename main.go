@@ -1,18 +1,10 @@
 package main
 
 import (
-	"fmt"
-	"github.com/opendexnetwork/opendex-launcher/core"
+	"github.com/opendexnetwork/opendex-launcher/pkg/launcher"
 	"os"
 )
 
 func main() {
-	launcher := core.NewLauncher()
-	err := launcher.Start()
-	if err != nil {
-		if core.Debug {
-			fmt.Println(err)
-		}
-		os.Exit(1)
-	}
+	os.Exit(launcher.Main(os.Args[1:]))
 }
@@ -0,0 +1,123 @@
+// Package launcher is the Go API of opendex-launcher for applications that
+// embed it, e.g. opendex-desktop, instead of running the binary. Its
+// interface is kept stable across releases.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/opendexnetwork/opendex-launcher/core"
+)
+
+type (
+	Event     = core.Event
+	EventType = core.EventType
+	Config    = core.Config
+)
+
+const (
+	EventInstalled       = core.EventInstalled
+	EventDownloadFailed  = core.EventDownloadFailed
+	EventChildCrashed    = core.EventChildCrashed
+	EventProgress        = core.EventProgress
+	EventArtifactChanged = core.EventArtifactChanged
+	EventResourceAlert   = core.EventResourceAlert
+	EventResolveStarted  = core.EventResolveStarted
+	EventChildStarted    = core.EventChildStarted
+	EventChildExited     = core.EventChildExited
+)
+
+// eventBuffer is the number of events kept for a slow reader of Events.
+// Further events are dropped until it catches up.
+const eventBuffer = 100
+
+// Launcher resolves, installs and runs the launcher of an opendex-docker
+// branch, release tag or release channel.
+type Launcher struct {
+	core        *core.Launcher
+	events      <-chan Event
+	closeEvents func()
+}
+
+// New returns a Launcher set up by args, the bootstrap flags and launcher
+// arguments of the opendex-launcher command line, e.g.
+//
+//	launcher.New("--network", "testnet", "--branch", "master", "status")
+//
+// The network, branch and home directory default to $NETWORK, $BRANCH and
+// the platform default like on the command line.
+func New(args ...string) (*Launcher, error) {
+	return NewWithContext(context.Background(), args...)
+}
+
+// NewWithContext returns a Launcher like New. Cancelling ctx aborts Resolve
+// and EnsureInstalled, removing partial downloads, and makes Launch stop the
+// launcher gracefully and return.
+func NewWithContext(ctx context.Context, args ...string) (*Launcher, error) {
+	l := &Launcher{core: core.NewLauncher()}
+	l.events, l.closeEvents = l.core.Events(eventBuffer)
+	if err := l.core.Prepare(ctx, args); err != nil {
+		l.closeEvents()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Close stops delivering events and closes the channel of Events. The
+// Launcher is not used after Close.
+func (l *Launcher) Close() {
+	l.closeEvents()
+}
+
+// Resolve returns the commit to run.
+func (l *Launcher) Resolve() (string, error) {
+	return l.core.Resolve()
+}
+
+// EnsureInstalled downloads and verifies the launcher of commit unless it is
+// installed already and returns its path.
+func (l *Launcher) EnsureInstalled(commit string) (string, error) {
+	return l.core.EnsureInstalled(commit)
+}
+
+// Launch runs the launcher at path, installed for commit, until it exits.
+func (l *Launcher) Launch(path string, commit string) error {
+	return l.core.Launch(path, commit)
+}
+
+// Events returns the events of the launcher: the start of resolving,
+// download progress, installs, failed downloads and the start, exit and
+// crashes of the launcher. The channel is closed by Close.
+func (l *Launcher) Events() <-chan Event {
+	return l.events
+}
+
+// Subscribe calls handler with every event of the launcher until the
+// returned function is called. Handlers are called one at a time and must
+// not block.
+func (l *Launcher) Subscribe(handler func(Event)) func() {
+	return l.core.Subscribe(handler)
+}
+
+// Config returns a copy of the effective configuration.
+func (l *Launcher) Config() *Config {
+	return l.core.Config()
+}
+
+// Main runs the opendex-launcher command line args, without the program
+// name, and returns the exit code of the process.
+func Main(args []string) int {
+	err := core.NewLauncher().StartArgs(args)
+	if err == nil {
+		return 0
+	}
+	var exitErr *core.ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if core.Debug {
+		fmt.Println(err)
+	}
+	return 1
+}
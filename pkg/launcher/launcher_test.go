@@ -0,0 +1,37 @@
+package launcher
+
+import (
+	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	home, err := ioutil.TempDir("", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	for _, key := range []string{"HOME", "USERPROFILE"} {
+		if value, ok := os.LookupEnv(key); ok {
+			defer os.Setenv(key, value)
+		} else {
+			defer os.Unsetenv(key)
+		}
+		os.Setenv(key, home)
+	}
+
+	l, err := New("--network", "simnet", "-o", "cache.max-size=5", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, l.Config().Cache.MaxSize, 5)
+	assert.Equal(t, len(l.Events()), 0)
+
+	// ranging over the events ends with Close
+	l.Close()
+	for range l.Events() {
+	}
+	l.Close()
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// MkdirAll creates path together with any missing parents, like
+// os.MkdirAll, and syncs the directory each new directory was created in so
+// that the tree survives a crash. It returns the topmost directory it
+// created, or an empty string when path already existed. When it fails, the
+// directories it created are removed again.
+func MkdirAll(path string, perm os.FileMode) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return "", &os.PathError{Op: "mkdir", Path: dir, Err: os.ErrExist}
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if len(missing) == 0 {
+		return "", nil
+	}
+
+	top := ""
+	for i := len(missing) - 1; i >= 0; i-- {
+		dir := missing[i]
+		if err := os.Mkdir(dir, perm); err != nil {
+			if os.IsExist(err) {
+				// created concurrently
+				continue
+			}
+			if top != "" {
+				_ = os.RemoveAll(top)
+			}
+			return "", err
+		}
+		if top == "" {
+			top = dir
+		}
+		if err := SyncDir(filepath.Dir(dir)); err != nil {
+			_ = os.RemoveAll(top)
+			return "", err
+		}
+	}
+	return top, nil
+}
+
+// SyncDir flushes the entries of dir to disk. Windows cannot sync
+// directories, there it does nothing.
+func SyncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}